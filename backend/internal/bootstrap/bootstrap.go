@@ -1,9 +1,16 @@
 package bootstrap
 
 import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
 	_ "valibibe/docs"
 	"valibibe/internal/controller"
 	"valibibe/internal/db"
+	"valibibe/internal/dedup"
 	"valibibe/internal/middleware"
 	"valibibe/internal/repository"
 	"valibibe/internal/router"
@@ -22,16 +29,39 @@ func InitializeApp() (*gin.Engine, error) {
 	noteRepo := repository.NewNoteRepository(database)
 	folderRepo := repository.NewFolderRepo(database)
 	tagRepo := repository.NewTagRepository(database)
+	examRepo := repository.NewExamRepository(database)
+	prerequisiteRepo := repository.NewPrerequisiteRepository(database)
+	reviewLogRepo := repository.NewReviewLogRepository(database)
+	trashRepo := repository.NewTrashRepository(database)
+	noteRevisionRepo := repository.NewNoteRevisionRepository(database)
+	savedSearchRepo := repository.NewSavedSearchRepository(database)
+	noteLinkRepo := repository.NewNoteLinkRepository(database)
+	noteTypeRepo := repository.NewNoteTypeRepository(database)
+	noteDuplicateRepo := repository.NewNoteDuplicateRepository(database)
+	noteBulkRepo := repository.NewNoteBulkRepository(database)
+	copyRepo := repository.NewCopyRepository(database)
 
 	// Сервисы
 	tokenService := service.NewTokenService()
 	authService := service.NewAuthService(userRepo, tokenService)
-	noteService := service.NewNoteService(noteRepo)
+	noteRevisionService := service.NewNoteRevisionService(noteRevisionRepo, noteRepo, noteRevisionLimit())
+	noteLinkService := service.NewNoteLinkService(noteLinkRepo, noteRepo)
+	noteTypeService := service.NewNoteTypeService(noteTypeRepo)
+	duplicateService := service.NewDuplicateService(noteDuplicateRepo, duplicateThreshold())
+	noteService := service.NewNoteService(noteRepo, reviewLogRepo, noteRevisionService, noteLinkService, noteTypeService, duplicateService, folderRepo, tagRepo)
 	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
 	folderService := service.NewFolderService(folderRepo)
 	tagService := service.NewTagService(tagRepo)
 	noteTagService := service.NewNoteTagService(noteRepo, tagRepo)
-	reviewSessionService := service.NewReviewSessionService(noteRepo)
+	reviewSessionService := service.NewReviewSessionService(noteRepo, savedSearchRepo, noteTypeRepo, folderRepo, reviewLogRepo)
+	examService := service.NewExamService(examRepo, noteRepo, reviewLogRepo, folderRepo)
+	prerequisiteService := service.NewPrerequisiteService(prerequisiteRepo, noteRepo, folderRepo)
+	statsService := service.NewStatsService(noteRepo, reviewLogRepo, folderRepo)
+	trashService := service.NewTrashService(trashRepo, trashRetention())
+	savedSearchService := service.NewSavedSearchService(savedSearchRepo, noteService)
+	graphService := service.NewGraphService(noteRepo, folderRepo, tagRepo, prerequisiteRepo, noteLinkRepo)
+	noteBulkService := service.NewNoteBulkService(noteBulkRepo, noteService, folderRepo, tagRepo)
+	copyService := service.NewCopyService(copyRepo, noteRepo, folderRepo, noteLinkService)
 
 	// Контроллеры
 	authController := controller.NewAuthController(authService)
@@ -40,12 +70,91 @@ func InitializeApp() (*gin.Engine, error) {
 	tagController := controller.NewTagController(tagService)
 	noteTagController := controller.NewNoteTagController(noteTagService)
 	reviewSessionController := controller.NewReviewSessionController(reviewSessionService)
+	examController := controller.NewExamController(examService)
+	prerequisiteController := controller.NewPrerequisiteController(prerequisiteService)
+	statsController := controller.NewStatsController(statsService)
+	trashController := controller.NewTrashController(trashService)
+	noteRevisionController := controller.NewNoteRevisionController(noteRevisionService)
+	savedSearchController := controller.NewSavedSearchController(savedSearchService)
+	noteLinkController := controller.NewNoteLinkController(noteLinkService)
+	graphController := controller.NewGraphController(graphService)
+	noteTypeController := controller.NewNoteTypeController(noteTypeService)
+	duplicateController := controller.NewDuplicateController(duplicateService)
+	noteBulkController := controller.NewNoteBulkController(noteBulkService)
+	copyController := controller.NewCopyController(copyService)
+
+	// Фоновая очистка корзины
+	trashService.StartPurger(context.Background(), trashPurgeInterval)
 
 	// Инициализация Gin
 	engine := gin.Default()
 	engine.Use(middleware.CORSMiddleware())
 	// Роутинг
-	router.SetupRoutes(engine, tokenService, authController, noteController, folderController, tagController, noteTagController, reviewSessionController)
+	router.SetupRoutes(engine, tokenService, router.Controllers{
+		Auth:          authController,
+		Note:          noteController,
+		Folder:        folderController,
+		Tag:           tagController,
+		NoteTag:       noteTagController,
+		ReviewSession: reviewSessionController,
+		Exam:          examController,
+		Prerequisite:  prerequisiteController,
+		Stats:         statsController,
+		Trash:         trashController,
+		NoteRevision:  noteRevisionController,
+		SavedSearch:   savedSearchController,
+		NoteLink:      noteLinkController,
+		Graph:         graphController,
+		NoteType:      noteTypeController,
+		Duplicate:     duplicateController,
+		NoteBulk:      noteBulkController,
+		Copy:          copyController,
+	})
 
 	return engine, nil
 }
+
+// trashPurgeInterval — как часто запускается очистка корзины
+const trashPurgeInterval = time.Hour
+
+// trashRetention читает срок хранения корзины из TRASH_RETENTION_DAYS
+func trashRetention() time.Duration {
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if value == "" {
+		return service.DefaultTrashRetention
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		log.Printf("⚠️ Некорректный TRASH_RETENTION_DAYS=%q, используется значение по умолчанию", value)
+		return service.DefaultTrashRetention
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// noteRevisionLimit читает из NOTE_REVISION_LIMIT, сколько ревизий хранить на заметку (0 — без ограничения)
+func noteRevisionLimit() int {
+	value := os.Getenv("NOTE_REVISION_LIMIT")
+	if value == "" {
+		return service.DefaultRevisionLimit
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Printf("⚠️ Некорректный NOTE_REVISION_LIMIT=%q, используется значение по умолчанию", value)
+		return service.DefaultRevisionLimit
+	}
+	return limit
+}
+
+// duplicateThreshold читает из DUPLICATE_SIMILARITY_THRESHOLD, с какой похожести текста заметки считаются дубликатами
+func duplicateThreshold() float64 {
+	value := os.Getenv("DUPLICATE_SIMILARITY_THRESHOLD")
+	if value == "" {
+		return dedup.DefaultThreshold
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		log.Printf("⚠️ Некорректный DUPLICATE_SIMILARITY_THRESHOLD=%q, используется значение по умолчанию", value)
+		return dedup.DefaultThreshold
+	}
+	return threshold
+}
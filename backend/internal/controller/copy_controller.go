@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type CopyController struct {
+	copyService *service.CopyService
+}
+
+func NewCopyController(copyService *service.CopyService) *CopyController {
+	return &CopyController{copyService: copyService}
+}
+
+// DuplicateNote godoc
+// @Summary Копия заметки
+// @Description Создаёт копию заметки в той же папке, с теми же тегами, типом и полями; к заголовку добавляется " (copy)".
+// @Description Журнал повторений и история правок не копируются. С reset_scheduling=true копия начинает изучаться заново.
+// @Tags notes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param reset_scheduling query bool false "Сбросить уровень памяти и дату повторения"
+// @Success 201 {object} models.Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/duplicate [post]
+func (c *CopyController) DuplicateNote(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	reset, ok := parseResetScheduling(ctx)
+	if !ok {
+		return
+	}
+
+	note, err := c.copyService.DuplicateNote(ctx, userID, ctx.Param("id"), reset)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, note)
+}
+
+// CopyFolder godoc
+// @Summary Копия папки со всем поддеревом
+// @Description Копирует папку, вложенные папки, их заметки и теги заметок одной транзакцией.
+// @Description Копия кладётся в папку target (без target — в корень); при совпадении имени добавляется " (copy)", " (copy 2)" и т.д.
+// @Description Папку нельзя скопировать в саму себя или в её потомка.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param target query string false "Папка, в которую кладётся копия"
+// @Param reset_scheduling query bool false "Сбросить уровень памяти и дату повторения у копий заметок"
+// @Success 201 {object} dto.FolderCopyResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/copy [post]
+func (c *CopyController) CopyFolder(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	reset, ok := parseResetScheduling(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.copyService.CopyFolder(ctx, userID, ctx.Param("id"), ctx.Query("target"), reset)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+func parseResetScheduling(ctx *gin.Context) (bool, bool) {
+	value := ctx.Query("reset_scheduling")
+	if value == "" {
+		return false, true
+	}
+	reset, err := strconv.ParseBool(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reset_scheduling value"})
+		return false, false
+	}
+	return reset, true
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "valibibe/internal/models"
+
+// FolderCopyResult — копия папки и число скопированных папок (вместе с корнем) и заметок
+type FolderCopyResult struct {
+	Folder  models.Folder `json:"folder"`
+	Folders int           `json:"folders"`
+	Notes   int           `json:"notes"`
+}
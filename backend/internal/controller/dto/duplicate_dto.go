@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// Что делать при создании заметки, похожей на существующие (параметр on_duplicate)
+const (
+	// OnDuplicateWarn — создать заметку и вернуть ID похожих в duplicate_ids
+	OnDuplicateWarn = "warn"
+	// OnDuplicateReject — не создавать заметку, ответить 409 с ID похожих
+	OnDuplicateReject = "reject"
+	// OnDuplicateAllow — не проверять (например, при массовом импорте)
+	OnDuplicateAllow = "allow"
+)
+
+// DuplicateReport — группы вероятных дубликатов по всей коллекции
+type DuplicateReport struct {
+	Groups []DuplicateGroup `json:"groups"`
+	Total  int              `json:"total"`
+}
+
+// DuplicateGroup — заметки, похожие друг на друга (от старых к новым).
+// TitleMatch — в группе есть заметки с одинаковым заголовком,
+// Similarity — наибольшая оценка похожести текста среди пар группы.
+type DuplicateGroup struct {
+	Notes      []DuplicateNote `json:"notes"`
+	TitleMatch bool            `json:"title_match"`
+	Similarity float64         `json:"similarity"`
+}
+
+type DuplicateNote struct {
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	MemoryLevel  int        `json:"memory_level"`
+	NextReviewAt *time.Time `json:"next_review_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// MergeNotesInput — заметки, которые вливаются в заметку из пути запроса
+type MergeNotesInput struct {
+	SourceIDs []string `json:"source_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440001"`
+}
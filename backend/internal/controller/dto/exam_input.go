@@ -0,0 +1,16 @@
+package dto
+
+// ExamInput представляет параметры запуска экзамена
+type ExamInput struct {
+	FolderID        *string  `json:"folder_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TagIDs          []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Limit           int      `json:"limit" example:"20" minimum:"1" maximum:"100"`
+	DurationSeconds int      `json:"duration_seconds" example:"600" minimum:"30" maximum:"14400"`
+	ApplyToSchedule bool     `json:"apply_to_schedule" example:"false"`
+}
+
+// ExamAnswerInput — ответ на вопрос экзамена (каждый вопрос отвечается один раз)
+type ExamAnswerInput struct {
+	QuestionID string `json:"question_id" binding:"required"`
+	Correct    bool   `json:"correct"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/google/uuid"
+
+// Режимы DELETE /folders/:id — что происходит с содержимым папки
+const (
+	// FolderDeleteCascade — подпапки и заметки уходят в корзину вместе с папкой
+	FolderDeleteCascade = "cascade"
+	// FolderDeleteMoveToParent — прямые подпапки и заметки переходят к родителю папки (или в корень)
+	FolderDeleteMoveToParent = "move_to_parent"
+	// FolderDeleteUnfile — папка с подпапками уходит в корзину, заметки поддерева остаются без папки
+	FolderDeleteUnfile = "unfile"
+)
+
+// FolderDeleteResult — что затрагивает удаление папки (ответ удаления и его предпросмотра)
+type FolderDeleteResult struct {
+	Mode   string `json:"mode" enums:"cascade,move_to_parent,unfile" example:"move_to_parent"`
+	DryRun bool   `json:"dry_run" example:"false"`
+	// Subfolders и Notes — сколько всего подпапок и заметок в поддереве папки
+	Subfolders int `json:"subfolders" example:"2"`
+	Notes      int `json:"notes" example:"14"`
+	// TrashedFolders и TrashedNotes уходят в корзину; сама папка всегда в TrashedFolders
+	TrashedFolders []uuid.UUID `json:"trashed_folders"`
+	TrashedNotes   []uuid.UUID `json:"trashed_notes"`
+	// MovedFolders и MovedNotes переносятся в TargetFolderID (null — в корень или без папки)
+	MovedFolders   []uuid.UUID `json:"moved_folders"`
+	MovedNotes     []uuid.UUID `json:"moved_notes"`
+	TargetFolderID *uuid.UUID  `json:"target_folder_id"`
+}
@@ -1,5 +1,6 @@
 package dto
 
+// FolderCreateInput — имя без "/": он разделяет имена в пути папки
 type FolderCreateInput struct {
     Name     string  `json:"name" binding:"required,min=1,max=200"`
     ParentID *string `json:"parent_id,omitempty"`
@@ -8,4 +9,6 @@ type FolderCreateInput struct {
 type FolderUpdateInput struct {
     Name     *string `json:"name,omitempty" binding:"omitempty,min=1,max=200"`
     ParentID *string `json:"parent_id,omitempty"`
+    // Version — версия, с которой работал клиент (или заголовок If-Match)
+    Version *int `json:"version,omitempty" example:"3"`
 }
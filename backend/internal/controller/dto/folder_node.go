@@ -1,8 +1,24 @@
 package dto
 
 type FolderNode struct {
-	ID       string        `json:"id"`
-	Name     string        `json:"name"`
-	ParentID *string       `json:"parent_id,omitempty"`
-	Children []*FolderNode `json:"children"`
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id,omitempty"`
+	Version  int     `json:"version"`
+	Position string  `json:"position"`
+	// Counts — заметки самой папки, SubtreeCounts — вместе со всеми подпапками
+	Counts        FolderCounts  `json:"counts"`
+	SubtreeCounts FolderCounts  `json:"subtree_counts"`
+	Children      []*FolderNode `json:"children"`
+}
+
+// FolderCounts — статистика заметок папки; архивные заметки не учитываются
+type FolderCounts struct {
+	Total int `json:"total"`
+	// Due — пора повторять (next_review_at наступил)
+	Due int `json:"due"`
+	// New — ещё ни разу не повторялись (next_review_at не задан)
+	New int `json:"new"`
+	// AverageMemoryLevel — средний уровень памяти, 0 без заметок
+	AverageMemoryLevel float64 `json:"average_memory_level"`
 }
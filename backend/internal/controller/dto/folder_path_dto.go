@@ -0,0 +1,14 @@
+package dto
+
+import "valibibe/internal/models"
+
+// FolderEnsurePathInput — путь из имён папок через "/", от корня
+type FolderEnsurePathInput struct {
+	Path string `json:"path" binding:"required" example:"Languages/Spanish/Verbs"`
+}
+
+// FolderEnsurePathResult — последняя папка пути и папки, созданные по дороге (от верхней к нижней)
+type FolderEnsurePathResult struct {
+	Folder  models.Folder   `json:"folder"`
+	Created []models.Folder `json:"created"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"valibibe/internal/scheduler"
+)
+
+// FolderEffectiveSettings — действующие настройки обучения папки с учётом наследования
+type FolderEffectiveSettings struct {
+	FolderID uuid.UUID          `json:"folder_id"`
+	Settings scheduler.Settings `json:"settings"`
+	// Sources — для заданных в папках полей: ID папки (самой или предка), откуда взято значение.
+	// Поля, которых нет в Sources, взяты из настроек по умолчанию
+	Sources map[string]uuid.UUID `json:"sources"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+// Типы узлов графа знаний
+const (
+	GraphNodeNote   = "note"
+	GraphNodeTag    = "tag"
+	GraphNodeFolder = "folder"
+)
+
+// Типы рёбер графа знаний
+const (
+	// GraphEdgeTagged — заметка → тег
+	GraphEdgeTagged = "tagged"
+	// GraphEdgeInFolder — заметка → папка
+	GraphEdgeInFolder = "in_folder"
+	// GraphEdgeChildOf — папка → родительская папка
+	GraphEdgeChildOf = "child_of"
+	// GraphEdgeLinksTo — заметка → заметка по вики-ссылке [[...]]
+	GraphEdgeLinksTo = "links_to"
+	// GraphEdgePrerequisite — пререквизит → зависимая заметка
+	GraphEdgePrerequisite = "prerequisite"
+)
+
+// Graph — граф знаний пользователя: заметки, теги, папки и связи между ними
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID    string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type  string `json:"type" enums:"note,tag,folder" example:"note"`
+	Label string `json:"label" example:"Испанские глаголы"`
+	// MemoryLevel и Archived заполняются только для заметок
+	MemoryLevel *int `json:"memory_level,omitempty" example:"40"`
+	Archived    bool `json:"archived,omitempty" example:"false"`
+}
+
+type GraphEdge struct {
+	Source string `json:"source" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Target string `json:"target" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Type   string `json:"type" enums:"tagged,in_folder,child_of,links_to,prerequisite" example:"tagged"`
+}
+
+// GraphFilter ограничивает граф поддеревом папки и/или тегами (достаточно любого из тегов)
+type GraphFilter struct {
+	FolderID *string
+	TagIDs   []string
+}
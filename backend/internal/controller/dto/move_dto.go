@@ -0,0 +1,17 @@
+package dto
+
+// MoveInput — новое место элемента среди соседей: перед before или после after
+// (ID соседа с тем же родителем); без них элемент переносится в конец
+type MoveInput struct {
+	Before *string `json:"before,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	After  *string `json:"after,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+}
+
+// FolderMoveInput — перенос папки: новый родитель и место среди его папок
+type FolderMoveInput struct {
+	// ParentID — новый родитель; "" — корень, без поля — родитель прежний
+	ParentID *string `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	MoveInput
+	// Version — версия, с которой работал клиент (или заголовок If-Match); необязательна
+	Version *int `json:"version,omitempty" example:"3"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+// Действия POST /notes/bulk
+const (
+	BulkArchive         = "archive"
+	BulkUnarchive       = "unarchive"
+	BulkDelete          = "delete"
+	BulkMove            = "move"
+	BulkAddTags         = "add_tags"
+	BulkRemoveTags      = "remove_tags"
+	BulkReplaceTags     = "replace_tags"
+	BulkResetScheduling = "reset_scheduling"
+)
+
+// Результат по заметке
+const (
+	// BulkItemUpdated — заметка изменена (при dry_run — была бы изменена)
+	BulkItemUpdated = "updated"
+	// BulkItemUnchanged — заметка уже в нужном состоянии
+	BulkItemUnchanged = "unchanged"
+	// BulkItemNotFound — заметки нет или она чужая; пропускается
+	BulkItemNotFound = "not_found"
+)
+
+// BulkNoteFilter — выбор заметок по тем же фильтрам, что у GET /notes
+type BulkNoteFilter struct {
+	Query    string   `json:"q" example:"tag:biology is:due"`
+	Search   string   `json:"search" example:"клетка"`
+	FolderID *string  `json:"folder_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TagIDs   []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Archived *bool    `json:"archived,omitempty" example:"false"`
+	// IncludeSubfolders — вместе с folder_id: заметки всех подпапок
+	IncludeSubfolders bool `json:"include_subfolders" example:"false"`
+}
+
+// NoteBulkInput — массовая операция над заметками: задаётся ровно одно из ids и filter
+type NoteBulkInput struct {
+	IDs    []string        `json:"ids" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Filter *BulkNoteFilter `json:"filter,omitempty"`
+	Action string          `json:"action" binding:"required" enums:"archive,unarchive,delete,move,add_tags,remove_tags,replace_tags,reset_scheduling" example:"add_tags"`
+	// FolderID — папка для move; null или пустое значение убирают заметки из папок
+	FolderID *string `json:"folder_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// TagIDs — теги для add_tags, remove_tags и replace_tags
+	TagIDs []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// DryRun — только посчитать результат, ничего не меняя
+	DryRun bool `json:"dry_run" example:"false"`
+}
+
+// NoteBulkItem — результат по одной заметке
+type NoteBulkItem struct {
+	ID     string `json:"id"`
+	Status string `json:"status" enums:"updated,unchanged,not_found"`
+}
+
+// NoteBulkResult — итог массовой операции
+type NoteBulkResult struct {
+	Action    string         `json:"action"`
+	DryRun    bool           `json:"dry_run"`
+	Matched   int            `json:"matched"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	NotFound  int            `json:"not_found"`
+	Items     []NoteBulkItem `json:"items"`
+}
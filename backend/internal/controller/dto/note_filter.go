@@ -1,5 +1,7 @@
 package dto
 
+import "valibibe/internal/notequery"
+
 type NoteFilter struct {
 	UserID   string
 	Search   string
@@ -10,4 +12,9 @@ type NoteFilter struct {
 	Archived *bool
 	FolderID *string
 	TagIDs   []string
+	// IncludeSubfolders — вместе с FolderID: заметки всех подпапок
+	IncludeSubfolders bool
+	// Query — запрос на языке notequery (параметр q); Expr — его разобранное AST
+	Query string
+	Expr  notequery.Expr
 }
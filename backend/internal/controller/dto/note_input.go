@@ -2,5 +2,16 @@ package dto
 
 type NoteInput struct {
     Title   string `json:"title" binding:"required,min=1,max=255"`
-    Content string `json:"content" binding:"required"`
+    Content string `json:"content"`
+
+    // NoteTypeID — тип заметки; "" — встроенный Basic, при обновлении отсутствие поля оставляет тип прежним
+    NoteTypeID *string `json:"note_type_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+    // Fields — значения полей типа; у Basic не используются.
+    // Если content пуст, текст заметки собирается из значений полей.
+    Fields map[string]string `json:"fields,omitempty"`
+
+    // Version — версия, с которой работал клиент (или заголовок If-Match); обязательна при обновлении
+    Version *int `json:"version,omitempty" example:"3"`
+    // OnDuplicate — реакция на похожие заметки при создании (warn, reject, allow), из query-параметра
+    OnDuplicate string `json:"-"`
 }
\ No newline at end of file
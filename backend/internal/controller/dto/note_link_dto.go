@@ -0,0 +1,25 @@
+package dto
+
+// NoteLinkInfo — исходящая вики-ссылка заметки
+type NoteLinkInfo struct {
+	// Text — цель ссылки, как она написана внутри [[ ]]
+	Text string `json:"text" example:"Испанские глаголы"`
+	// NoteID и Title — заметка, на которую ссылка указывает сейчас
+	NoteID   string `json:"note_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title    string `json:"title,omitempty" example:"Испанские глаголы"`
+	Dangling bool   `json:"dangling"`
+}
+
+// Backlink — заметка, ссылающаяся на текущую
+type Backlink struct {
+	NoteID string `json:"note_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title  string `json:"title" example:"Грамматика"`
+	Text   string `json:"text" example:"Испанские глаголы"`
+}
+
+// DanglingLink — ссылка, которая не указывает ни на одну существующую заметку
+type DanglingLink struct {
+	SourceID    string `json:"source_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SourceTitle string `json:"source_title" example:"Грамматика"`
+	Text        string `json:"text" example:"Несуществующая заметка"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// Форматы тела PATCH /notes/:id
+const (
+	// PatchFormatMerge — JSON Merge Patch (RFC 7396), Content-Type application/merge-patch+json
+	PatchFormatMerge = "merge"
+	// PatchFormatJSON — JSON Patch (RFC 6902), Content-Type application/json-patch+json
+	PatchFormatJSON = "json-patch"
+)
+
+// NotePatchInput — частичное обновление заметки
+type NotePatchInput struct {
+	Format string
+	Patch  []byte
+	// Version — версия из заголовка If-Match; без него берётся из патча
+	Version *int
+}
+
+// NotePatchDocument — изменяемое представление заметки, к которому применяется патч.
+// folder_id и next_review_at можно удалить или обнулить, остальные поля обязательны;
+// version только для чтения и проверки версии.
+type NotePatchDocument struct {
+	Title        string     `json:"title" example:"Present Simple"`
+	Content      string     `json:"content" example:"I work, he works"`
+	FolderID     *string    `json:"folder_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Archived     bool       `json:"archived"`
+	TagIDs       []string   `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001"`
+	MemoryLevel  int        `json:"memory_level" example:"40"`
+	NextReviewAt *time.Time `json:"next_review_at" example:"2025-01-01T10:00:00Z"`
+	Version      int        `json:"version" example:"3"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"valibibe/internal/diff"
+)
+
+// NoteRevisionInfo — элемент истории ревизий (без текста)
+type NoteRevisionInfo struct {
+	Revision     int       `json:"revision"`
+	Title        string    `json:"title"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	Current      bool      `json:"current"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NoteRevisionDiff — построчная разница между двумя ревизиями заметки
+type NoteRevisionDiff struct {
+	NoteID   string      `json:"note_id"`
+	From     int         `json:"from"`
+	To       int         `json:"to"`
+	OldTitle string      `json:"old_title"`
+	NewTitle string      `json:"new_title"`
+	Added    int         `json:"added"`
+	Removed  int         `json:"removed"`
+	Lines    []diff.Line `json:"lines"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "valibibe/internal/models"
+
+// NoteTypeInput — описание типа заметки. Пустые шаблоны заполняются по умолчанию:
+// вопрос — первое поле, ответ — остальные поля по строкам.
+type NoteTypeInput struct {
+	Name           string                 `json:"name" binding:"required" example:"Слово"`
+	Fields         []models.NoteTypeField `json:"fields" binding:"required"`
+	PromptTemplate string                 `json:"prompt_template" example:"{{Word}}"`
+	AnswerTemplate string                 `json:"answer_template" example:"{{Translation}}\n{{Example}}"`
+}
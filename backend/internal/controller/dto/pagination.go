@@ -7,4 +7,14 @@ import (
 type PaginatedNotes struct {
     Notes []models.Note `json:"notes"`
     Total int64 `json:"total"`
+    // Highlights — подсветка совпадений по ID заметки; заполняется только при поиске
+    Highlights map[string]NoteHighlight `json:"highlights,omitempty"`
+}
+
+// NoteHighlight — фрагменты заметки с совпадениями, обёрнутыми в <mark></mark>;
+// остальной текст экранирован как HTML
+type NoteHighlight struct {
+    Title   string  `json:"title"`
+    Snippet string  `json:"snippet"`
+    Rank    float64 `json:"rank"`
 }
\ No newline at end of file
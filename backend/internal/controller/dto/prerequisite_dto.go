@@ -0,0 +1,38 @@
+package dto
+
+// PrerequisiteInput — объявить заметку PrerequisiteID пререквизитом текущей заметки
+type PrerequisiteInput struct {
+	PrerequisiteID string `json:"prerequisite_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RequiredLevel  *int   `json:"required_level,omitempty" binding:"omitempty,min=0,max=100" example:"60"`
+}
+
+// Prerequisite — пререквизит заметки с текущим прогрессом
+type Prerequisite struct {
+	NoteID         string `json:"note_id"`
+	PrerequisiteID string `json:"prerequisite_id"`
+	Title          string `json:"title"`
+	MemoryLevel    int    `json:"memory_level"`
+	RequiredLevel  int    `json:"required_level"`
+	Satisfied      bool   `json:"satisfied"`
+}
+
+// PrerequisiteGraph — граф зависимостей папки для визуализации
+type PrerequisiteGraph struct {
+	Nodes []PrerequisiteGraphNode `json:"nodes"`
+	Edges []PrerequisiteGraphEdge `json:"edges"`
+}
+
+type PrerequisiteGraphNode struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	MemoryLevel int    `json:"memory_level"`
+	InFolder    bool   `json:"in_folder"`
+}
+
+// PrerequisiteGraphEdge направлено от пререквизита (From) к зависимой заметке (To)
+type PrerequisiteGraphEdge struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	RequiredLevel int    `json:"required_level"`
+	Satisfied     bool   `json:"satisfied"`
+}
@@ -1,10 +1,27 @@
 package dto
 
+import "github.com/google/uuid"
+
 // ReviewSessionInput представляет входные данные для создания сессии повторения
 type ReviewSessionInput struct {
 	FolderID *string  `json:"folder_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// TagIDs — родительский тег (lang) включает и заметки потомков (lang::spanish)
 	TagIDs   []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001,550e8400-e29b-41d4-a716-446655440002"`
 	Limit    int      `json:"limit" example:"10" minimum:"1" maximum:"100"`
+
+	// IncludeSubfolders — вместе с folder_id: заметки всех подпапок
+	IncludeSubfolders bool `json:"include_subfolders" example:"true"`
+
+	// SavedSearchID — сохранённый поиск, из заметок которого собирается сессия
+	SavedSearchID *string `json:"saved_search_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440003"`
+	// Filter — фильтр сохранённого поиска, заполняется сервисом
+	Filter *NoteFilter `json:"-"`
+
+	// Дневные лимиты, заполняются сервисом: папки, где сегодня исчерпан лимит новых карточек
+	// или повторений (uuid.Nil — заметки без папки), и уже отобранные в сессию заметки
+	NewBlocked    []uuid.UUID `json:"-"`
+	ReviewBlocked []uuid.UUID `json:"-"`
+	ExcludeIDs    []uuid.UUID `json:"-"`
 }
 
 // ReviewSessionResponse представляет ответ с заметками для повторения
@@ -25,6 +42,14 @@ type ReviewSessionNote struct {
 	Tags          []Tag  `json:"tags"`
 	CreatedAt     string `json:"created_at"`
 	UpdatedAt     string `json:"updated_at"`
+
+	// NoteType — имя типа заметки, Basic для заметок без типа
+	NoteTypeID string            `json:"note_type_id,omitempty"`
+	NoteType   string            `json:"note_type"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	// Prompt и Answer — стороны карточки, отрисованные по шаблонам типа
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
 }
 
 // Tag представляет тег в контексте сессии повторения
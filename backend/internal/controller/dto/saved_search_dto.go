@@ -0,0 +1,13 @@
+package dto
+
+// SavedSearchInput — фильтры сохранённого поиска; те же, что у GET /notes
+type SavedSearchInput struct {
+	Name     string   `json:"name" binding:"required" example:"Биология к повторению"`
+	Query    string   `json:"q" example:"tag:biology -tag:draft is:due"`
+	Search   string   `json:"search" example:"клетка"`
+	FolderID *string  `json:"folder_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TagIDs   []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Archived *bool    `json:"archived,omitempty" example:"false"`
+	SortBy   string   `json:"sort_by" enums:"created_at,next_review_at,relevance,position" example:"next_review_at"`
+	Order    string   `json:"order" enums:"asc,desc" example:"asc"`
+}
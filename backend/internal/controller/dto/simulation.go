@@ -0,0 +1,54 @@
+package dto
+
+import "valibibe/internal/scheduler"
+
+// SimulationInput — кандидатные настройки планировщика для симуляции нагрузки.
+// Заданные поля переопределяют действующие настройки папки каждой заметки,
+// незаданные берутся из них (у заметок без папки — из scheduler.Default).
+type SimulationInput struct {
+	Days              int                 `json:"days" example:"30" minimum:"1" maximum:"365"`
+	Settings          *SimulationSettings `json:"settings,omitempty"`
+	SecondsPerReview  float64             `json:"seconds_per_review" example:"8"`
+	SecondsPerNewCard float64             `json:"seconds_per_new_card" example:"20"`
+	Seed              *int64              `json:"seed,omitempty" example:"42"`
+}
+
+// SimulationSettings — частичное переопределение scheduler.Settings
+type SimulationSettings struct {
+	LevelStep       *int  `json:"level_step,omitempty" example:"20"`
+	IntervalDays    []int `json:"interval_days,omitempty" example:"1,3,5,10,30"`
+	MaxIntervalDays *int  `json:"max_interval_days,omitempty" example:"0"`
+	NewPerDay       *int  `json:"new_per_day,omitempty" example:"20"`
+	ReviewsPerDay   *int  `json:"reviews_per_day,omitempty" example:"200"`
+	// Algorithm — levels или exponential
+	Algorithm       *string  `json:"algorithm,omitempty" example:"levels"`
+	TargetRetention *float64 `json:"target_retention,omitempty" example:"0.9"`
+	// LearningSteps — шаги обучения в минутах; пустой список отключает шаги
+	LearningSteps []int `json:"learning_steps,omitempty" example:"1,10"`
+}
+
+// SimulationDay — прогноз на один день
+type SimulationDay struct {
+	Day               int     `json:"day"`
+	Date              string  `json:"date"`
+	Reviews           int     `json:"reviews"`
+	NewCards          int     `json:"new_cards"`
+	Forgotten         int     `json:"forgotten"`
+	Postponed         int     `json:"postponed"`
+	ExpectedRetention float64 `json:"expected_retention"`
+	TimeSpentSeconds  float64 `json:"time_spent_seconds"`
+}
+
+// SimulationResult — результат симуляции
+type SimulationResult struct {
+	// Settings — итоговые настройки для заметок без папки
+	Settings             scheduler.Settings `json:"settings"`
+	Notes                int                `json:"notes"`
+	RecallRates          []float64          `json:"recall_rates"`
+	Days                 []SimulationDay    `json:"days"`
+	TotalReviews         int                `json:"total_reviews"`
+	TotalNewCards        int                `json:"total_new_cards"`
+	AverageReviewsPerDay float64            `json:"average_reviews_per_day"`
+	ExpectedRetention    float64            `json:"expected_retention"`
+	TotalTimeSeconds     float64            `json:"total_time_seconds"`
+}
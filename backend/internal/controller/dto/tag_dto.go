@@ -1,14 +1,83 @@
 package dto
 
+import (
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
 type TagCreateInput struct {
     Name string `json:"name" binding:"required"`
 }
 
 type TagUpdateInput struct {
     Name string `json:"name" binding:"required"`
+    // Version — версия, с которой работал клиент (или заголовок If-Match)
+    Version *int `json:"version,omitempty" example:"3"`
 }
 
 type NoteTagInput struct {
 	NoteID string `json:"note_id" binding:"required"`
 	TagID  string `json:"tag_id" binding:"required"`
 }
+
+// TagNode — тег в дереве; иерархия задаётся именем вида "lang::spanish::verbs"
+type TagNode struct {
+	ID string `json:"id"`
+	// Name — полный путь тега, Label — последний сегмент
+	Name     string     `json:"name"`
+	Label    string     `json:"label"`
+	Version  int        `json:"version"`
+	Children []*TagNode `json:"children"`
+}
+
+// TagMergeInput — теги source_ids сливаются в target_id
+type TagMergeInput struct {
+	SourceIDs []string `json:"source_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440001"`
+	TargetID  string   `json:"target_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440002"`
+}
+
+// TagMergeResult — итог слияния тегов
+type TagMergeResult struct {
+	Target models.Tag `json:"target"`
+	// MergedTagIDs — исходные теги, удалённые после слияния
+	MergedTagIDs []uuid.UUID `json:"merged_tag_ids"`
+	// MovedLinks — связи, перенесённые на target; DuplicateLinks — связи заметок,
+	// у которых target уже был (или было несколько исходных тегов), они просто удалены
+	MovedLinks     int `json:"moved_links"`
+	DuplicateLinks int `json:"duplicate_links"`
+}
+
+// Режимы массового переименования тегов
+const (
+	// TagRenamePrefix — имена, начинающиеся с pattern (без учёта регистра), получают replacement вместо него
+	TagRenamePrefix = "prefix"
+	// TagRenameRegex — pattern как регулярное выражение Go, в replacement доступны $1, ${name}
+	TagRenameRegex = "regex"
+)
+
+// TagBulkRenameInput — массовое переименование тегов
+type TagBulkRenameInput struct {
+	Mode        string `json:"mode" binding:"required" example:"prefix" enums:"prefix,regex"`
+	Pattern     string `json:"pattern" binding:"required" example:"bio"`
+	Replacement string `json:"replacement" example:"biology"`
+	// DryRun — только показать, что изменится
+	DryRun bool `json:"dry_run" example:"true"`
+}
+
+// TagRename — новое имя тега
+type TagRename struct {
+	ID   uuid.UUID `json:"id"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// TagBulkRenameResult — итог (или предпросмотр) массового переименования
+type TagBulkRenameResult struct {
+	DryRun  bool        `json:"dry_run"`
+	Renamed []TagRename `json:"renamed"`
+	// Conflicts — переименования, после которых имя совпало бы с другим тегом; при них ничего не меняется
+	Conflicts []TagRename `json:"conflicts"`
+	// CreatedParents — недостающие родительские теги новых путей
+	CreatedParents []string `json:"created_parents"`
+}
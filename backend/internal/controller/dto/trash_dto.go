@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// Типы объектов в корзине
+const (
+	TrashItemNote   = "note"
+	TrashItemFolder = "folder"
+	TrashItemTag    = "tag"
+)
+
+// TrashItem — объект в корзине. Папка показывается вместе с количеством
+// вложенных папок и заметок, удалённых вместе с ней.
+type TrashItem struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type" example:"folder"`
+	Title        string    `json:"title"`
+	ParentID     *string   `json:"parent_id,omitempty"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	PurgeAt      time.Time `json:"purge_at"`
+	FoldersCount int       `json:"folders_count,omitempty"`
+	NotesCount   int       `json:"notes_count,omitempty"`
+}
+
+// TrashList — содержимое корзины пользователя
+type TrashList struct {
+	Items         []TrashItem `json:"items"`
+	RetentionDays int         `json:"retention_days"`
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type DuplicateController struct {
+	duplicateService *service.DuplicateService
+}
+
+func NewDuplicateController(duplicateService *service.DuplicateService) *DuplicateController {
+	return &DuplicateController{duplicateService: duplicateService}
+}
+
+// ListDuplicates godoc
+// @Summary Вероятные дубликаты заметок
+// @Description Группирует заметки пользователя с одинаковым (после нормализации) заголовком или похожим текстом.
+// @Description Похожесть текста — оценка MinHash по шинглам из 5 символов; группы транзитивны и упорядочены по похожести.
+// @Tags notes
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.DuplicateReport
+// @Failure 500 {object} map[string]string
+// @Router /notes/duplicates [get]
+func (c *DuplicateController) ListDuplicates(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	report, err := c.duplicateService.Report(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// MergeNotes godoc
+// @Summary Слить дубликаты в заметку
+// @Description Заметки source_ids вливаются в заметку id: теги объединяются, остаётся лучшее расписание
+// @Description (больший уровень памяти, при равенстве — более позднее повторение), журнал повторений и входящие
+// @Description вики-ссылки переходят к ней, а дубликаты отправляются в корзину. Заголовок и текст не меняются.
+// @Tags notes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param input body dto.MergeNotesInput true "Дубликаты"
+// @Success 200 {object} models.Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/merge [post]
+func (c *DuplicateController) MergeNotes(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.MergeNotesInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := c.duplicateService.MergeNotes(ctx, userID, ctx.Param("id"), &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, note)
+}
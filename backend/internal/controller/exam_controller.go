@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type ExamController struct {
+	examService *service.ExamService
+}
+
+func NewExamController(examService *service.ExamService) *ExamController {
+	return &ExamController{examService: examService}
+}
+
+// StartExam godoc
+// @Summary Начать экзамен на время
+// @Description Выбирает заметки из папки и/или по тегам, фиксирует порядок вопросов и дедлайн на сервере.
+// @Tags exams
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.ExamInput true "Параметры экзамена"
+// @Success 201 {object} models.Exam
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exams [post]
+func (c *ExamController) StartExam(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.ExamInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exam, err := c.examService.StartExam(ctx, userID, &input)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "No notes found for exam"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, exam)
+}
+
+// ListExams godoc
+// @Summary Результаты экзаменов
+// @Description Возвращает экзамены пользователя с баллами, правильностью ответов и затраченным временем.
+// @Tags exams
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Exam
+// @Failure 500 {object} map[string]string
+// @Router /exams [get]
+func (c *ExamController) ListExams(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	exams, err := c.examService.ListExams(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, exams)
+}
+
+// GetExam godoc
+// @Summary Получить экзамен
+// @Tags exams
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Exam ID"
+// @Success 200 {object} models.Exam
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exams/{id} [get]
+func (c *ExamController) GetExam(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	id := ctx.Param("id")
+
+	exam, err := c.examService.GetExam(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Exam not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, exam)
+}
+
+// AnswerQuestion godoc
+// @Summary Ответить на вопрос экзамена
+// @Description Каждый вопрос принимает ровно один ответ и только до дедлайна.
+// @Tags exams
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Exam ID"
+// @Param input body dto.ExamAnswerInput true "Ответ"
+// @Success 200 {object} models.ExamQuestion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exams/{id}/answers [post]
+func (c *ExamController) AnswerQuestion(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	id := ctx.Param("id")
+
+	var input dto.ExamAnswerInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := c.examService.AnswerQuestion(ctx, userID, id, &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Exam or question not found"})
+		case errors.Is(err, apperrors.ErrExamSubmitted),
+			errors.Is(err, apperrors.ErrExamDeadlinePassed),
+			errors.Is(err, apperrors.ErrQuestionAnswered):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, question)
+}
+
+// SubmitExam godoc
+// @Summary Завершить экзамен
+// @Description Подсчитывает итоговый балл. Ответы влияют на расписание повторений только при apply_to_schedule.
+// @Tags exams
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Exam ID"
+// @Success 200 {object} models.Exam
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exams/{id}/submit [post]
+func (c *ExamController) SubmitExam(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	id := ctx.Param("id")
+
+	exam, err := c.examService.SubmitExam(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Exam not found"})
+			return
+		}
+		if errors.Is(err, apperrors.ErrExamSubmitted) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, exam)
+}
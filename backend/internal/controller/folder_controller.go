@@ -6,6 +6,7 @@ import (
 
 	"valibibe/internal/controller/dto"
 	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
 	"valibibe/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,7 @@ func NewFolderController(folderService *service.FolderService) *FolderController
 
 // CreateFolder godoc
 // @Summary Создать папку
+// @Description Имя не может содержать "/": он разделяет имена в пути (GET /folders/by-path, POST /folders/ensure-path).
 // @Tags folders
 // @Security BearerAuth
 // @Accept json
@@ -42,6 +44,10 @@ func (c *FolderController) CreateFolder(ctx *gin.Context) {
 
 	folder, err := c.folderService.CreateFolder(ctx, userID, input)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, apperrors.ErrNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Parent folder not found"})
 			return
@@ -50,11 +56,14 @@ func (c *FolderController) CreateFolder(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, folder.Version)
 	ctx.JSON(http.StatusCreated, folder)
 }
 
 // GetFolderTree godoc
 // @Summary Получить дерево папок пользователя
+// @Description У каждой папки counts — заметки самой папки и subtree_counts — вместе с подпапками:
+// @Description всего, пора повторять, новые (ещё не повторялись) и средний уровень памяти. Архивные заметки не учитываются.
 // @Tags folders
 // @Security BearerAuth
 // @Produce json
@@ -73,17 +82,121 @@ func (c *FolderController) GetFolderTree(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, tree)
 }
 
+// GetFolderPath godoc
+// @Summary Получить путь к папке
+// @Description Цепочка папок от корня до самой папки включительно — для хлебных крошек.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {array} models.Folder
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/path [get]
+func (c *FolderController) GetFolderPath(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	chain, err := c.folderService.GetFolderPath(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, chain)
+}
+
+// GetFolderByPath godoc
+// @Summary Найти папку по пути
+// @Description Путь — имена папок от корня через "/", например Languages/Spanish/Verbs. Имена сравниваются точно, пробелы вокруг них и пустые сегменты отбрасываются.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param path query string true "Путь из имён папок" example(Languages/Spanish/Verbs)
+// @Success 200 {object} models.Folder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/by-path [get]
+func (c *FolderController) GetFolderByPath(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	folder, err := c.folderService.ResolveFolderPath(ctx, userID, ctx.Query("path"))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	setETag(ctx, folder.Version)
+	ctx.JSON(http.StatusOK, folder)
+}
+
+// EnsureFolderPath godoc
+// @Summary Создать недостающие папки пути
+// @Description Находит папку по пути из имён (как GET /folders/by-path) и создаёт по дороге недостающие папки.
+// @Description 201 — если что-то создано, 200 — если весь путь уже существовал.
+// @Tags folders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.FolderEnsurePathInput true "Путь из имён папок"
+// @Success 200 {object} dto.FolderEnsurePathResult
+// @Success 201 {object} dto.FolderEnsurePathResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/ensure-path [post]
+func (c *FolderController) EnsureFolderPath(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.FolderEnsurePathInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := c.folderService.EnsureFolderPath(ctx, userID, input.Path)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Created) > 0 {
+		status = http.StatusCreated
+	}
+	ctx.JSON(status, result)
+}
+
 // UpdateFolder godoc
 // @Summary Обновить папку
+// @Description При смене parent_id действуют те же проверки, что у POST /folders/{id}/move: без циклов и совпадающих имён (409).
 // @Tags folders
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Folder ID"
+// @Param If-Match header string false "ETag папки; заменяет поле version"
 // @Param folder body dto.FolderUpdateInput true "Обновлённые данные папки"
 // @Success 200 {object} models.Folder
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /folders/{id} [put]
 func (c *FolderController) UpdateFolder(ctx *gin.Context) {
@@ -95,34 +208,249 @@ func (c *FolderController) UpdateFolder(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	fromHeader, ok := applyIfMatch(ctx, &input.Version)
+	if !ok {
+		return
+	}
 
 	folder, err := c.folderService.UpdateFolder(ctx, userID, id, input)
 	if err != nil {
-		if errors.Is(err, apperrors.ErrNotFound) {
+		if handleVersionError(ctx, err, fromHeader) {
+			return
+		}
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder or parent folder not found"})
+		case errors.Is(err, apperrors.ErrFolderCycle), errors.Is(err, apperrors.ErrDuplicateName):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	setETag(ctx, folder.Version)
+	ctx.JSON(http.StatusOK, folder)
+}
+
+// MoveFolder godoc
+// @Summary Перенести папку
+// @Description Переносит папку к родителю parent_id ("" — в корень, без поля — родитель прежний) и ставит её
+// @Description перед before или после after — папкой того же родителя; без них папка становится последней.
+// @Description Папку нельзя перенести в неё саму или в её подпапку (409), у нового родителя не должно быть папки с тем же именем (409).
+// @Description Порядок хранится ключами дробной индексации, поэтому перестановка меняет только ключ самой папки.
+// @Tags folders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param If-Match header string false "ETag папки; заменяет поле version"
+// @Param input body dto.FolderMoveInput true "Новый родитель и место"
+// @Success 200 {object} models.Folder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/move [post]
+func (c *FolderController) MoveFolder(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.FolderMoveInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fromHeader, ok := applyIfMatch(ctx, &input.Version)
+	if !ok {
+		return
+	}
+
+	folder, err := c.folderService.MoveFolder(ctx, userID, ctx.Param("id"), input)
+	if err != nil {
+		if handleVersionError(ctx, err, fromHeader) {
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder or parent folder not found"})
+		case errors.Is(err, apperrors.ErrFolderCycle), errors.Is(err, apperrors.ErrDuplicateName):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
 		return
 	}
 
+	setETag(ctx, folder.Version)
 	ctx.JSON(http.StatusOK, folder)
 }
 
 // DeleteFolder godoc
-// @Summary Удалить папку (с каскадом заметок и подпапок)
+// @Summary Переместить папку в корзину
+// @Description Режим mode задаёт судьбу содержимого: cascade (по умолчанию) — подпапки и заметки уходят в корзину вместе с папкой;
+// @Description move_to_parent — прямые подпапки и заметки переходят к родителю папки (или в корень); unfile — подпапки уходят в корзину, а заметки остаются без папки.
+// @Description С явным mode ответ перечисляет затронутые папки и заметки; без mode, как и раньше, — 204 без тела.
 // @Tags folders
 // @Security BearerAuth
+// @Produce json
 // @Param id path string true "Folder ID"
-// @Success 204 {string} string "No Content"
+// @Param mode query string false "Режим удаления" Enums(cascade, move_to_parent, unfile) default(cascade)
+// @Success 200 {object} dto.FolderDeleteResult
+// @Success 204
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "У родителя уже есть папка с именем одной из подпапок"
 // @Failure 500 {object} map[string]string
 // @Router /folders/{id} [delete]
 func (c *FolderController) DeleteFolder(ctx *gin.Context) {
+	c.deleteFolder(ctx, false)
+}
+
+// PreviewDeleteFolder godoc
+// @Summary Предпросмотр удаления папки
+// @Description Считает подпапки и заметки папки и показывает, что сделает DELETE /folders/{id} с тем же mode, ничего не меняя.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param mode query string false "Режим удаления" Enums(cascade, move_to_parent, unfile) default(cascade)
+// @Success 200 {object} dto.FolderDeleteResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "У родителя уже есть папка с именем одной из подпапок"
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/delete-preview [get]
+func (c *FolderController) PreviewDeleteFolder(ctx *gin.Context) {
+	c.deleteFolder(ctx, true)
+}
+
+func (c *FolderController) deleteFolder(ctx *gin.Context, dryRun bool) {
 	userID := ctx.MustGet("user_id").(string)
-	id := ctx.Param("id")
 
-	if err := c.folderService.DeleteFolder(ctx, userID, id); err != nil {
+	result, err := c.folderService.DeleteFolder(ctx, userID, ctx.Param("id"), ctx.Query("mode"), dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		case errors.Is(err, apperrors.ErrDuplicateName):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	// Клиенты, которые не знают о режимах, получают прежний ответ без тела
+	if !dryRun && ctx.Query("mode") == "" {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetFolderSettings godoc
+// @Summary Получить настройки обучения папки
+// @Description Собственные настройки папки (deck options): null-поля наследуются от родительской папки, у корня — от настроек по умолчанию.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} models.FolderSettings
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/settings [get]
+func (c *FolderController) GetFolderSettings(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	folder, err := c.folderService.GetFolderSettings(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	setETag(ctx, folder.Version)
+	ctx.JSON(http.StatusOK, folder.Settings)
+}
+
+// UpdateFolderSettings godoc
+// @Summary Заменить настройки обучения папки
+// @Description Заменяет собственные настройки папки целиком: null или отсутствующее поле снова наследуется.
+// @Description learning_steps: [] отключает шаги обучения, не наследуя их. Проверяются и сами значения, и действующие настройки вместе с унаследованными.
+// @Tags folders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param If-Match header string false "ETag папки; без него настройки перезаписываются"
+// @Param settings body models.FolderSettings true "Настройки папки"
+// @Success 200 {object} models.FolderSettings
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/settings [put]
+func (c *FolderController) UpdateFolderSettings(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var settings models.FolderSettings
+	if err := ctx.ShouldBindJSON(&settings); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var version *int
+	fromHeader, ok := applyIfMatch(ctx, &version)
+	if !ok {
+		return
+	}
+
+	folder, err := c.folderService.UpdateFolderSettings(ctx, userID, ctx.Param("id"), settings, version)
+	if err != nil {
+		if handleVersionError(ctx, err, fromHeader) {
+			return
+		}
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	setETag(ctx, folder.Version)
+	ctx.JSON(http.StatusOK, folder.Settings)
+}
+
+// GetEffectiveFolderSettings godoc
+// @Summary Получить действующие настройки обучения папки
+// @Description Настройки, по которым планируются заметки папки: собственные значения папки, затем ближайших предков, затем по умолчанию.
+// @Description sources показывает, какая папка задала каждое из переопределённых полей.
+// @Tags folders
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} dto.FolderEffectiveSettings
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/settings/effective [get]
+func (c *FolderController) GetEffectiveFolderSettings(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	effective, err := c.folderService.GetEffectiveSettings(ctx, userID, ctx.Param("id"))
+	if err != nil {
 		if errors.Is(err, apperrors.ErrNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
 			return
@@ -131,5 +459,5 @@ func (c *FolderController) DeleteFolder(ctx *gin.Context) {
 		return
 	}
 
-	ctx.Status(http.StatusNoContent)
+	ctx.JSON(http.StatusOK, effective)
 }
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/graphexport"
+	"valibibe/internal/service"
+)
+
+type GraphController struct {
+	graphService *service.GraphService
+}
+
+func NewGraphController(graphService *service.GraphService) *GraphController {
+	return &GraphController{graphService: graphService}
+}
+
+// GetGraph godoc
+// @Summary Граф знаний
+// @Description Узлы — заметки, теги и папки; рёбра — заметка→тег, заметка→папка, папка→родитель, вики-ссылки и пререквизиты между заметками. Выгружается в JSON, GraphML или DOT.
+// @Tags graph
+// @Security BearerAuth
+// @Produce json
+// @Produce xml
+// @Produce plain
+// @Param format query string false "Формат выгрузки" Enums(json, graphml, dot) default(json)
+// @Param folder_id query string false "Только поддерево папки"
+// @Param tag_ids query []string false "Только заметки хотя бы с одним из тегов (через tag_ids[]=id1&tag_ids[]=id2)"
+// @Success 200 {object} dto.Graph
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /graph [get]
+func (c *GraphController) GetGraph(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	format := strings.ToLower(ctx.DefaultQuery("format", graphexport.FormatJSON))
+	contentType, ok := graphexport.ContentTypes[format]
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, graphml or dot"})
+		return
+	}
+
+	filter := dto.GraphFilter{TagIDs: ctx.QueryArray("tag_ids[]")}
+	if folderID := ctx.Query("folder_id"); folderID != "" {
+		filter.FolderID = &folderID
+	}
+
+	graph, err := c.graphService.BuildGraph(ctx, userID, &filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	switch format {
+	case graphexport.FormatGraphML:
+		body, err := graphexport.GraphML(graph)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		ctx.Data(http.StatusOK, contentType, body)
+	case graphexport.FormatDOT:
+		ctx.Data(http.StatusOK, contentType, graphexport.DOT(graph))
+	default:
+		ctx.JSON(http.StatusOK, graph)
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/notequery"
+	"valibibe/internal/service"
+)
+
+type NoteBulkController struct {
+	noteBulkService *service.NoteBulkService
+}
+
+func NewNoteBulkController(noteBulkService *service.NoteBulkService) *NoteBulkController {
+	return &NoteBulkController{noteBulkService: noteBulkService}
+}
+
+// BulkNotes godoc
+// @Summary Массовая операция над заметками
+// @Description Заметки задаются списком ids или фильтром filter (как у GET /notes), не больше 1000 за запрос.
+// @Description Действия: archive, unarchive, delete (в корзину), move (folder_id, null — без папки),
+// @Description add_tags, remove_tags, replace_tags (tag_ids), reset_scheduling (уровень памяти 0, без даты повторения).
+// @Description Все изменения выполняются одной транзакцией; по каждой заметке возвращается updated, unchanged или not_found
+// @Description (чужие и несуществующие ids пропускаются). С dry_run=true результат считается без изменений.
+// @Tags notes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.NoteBulkInput true "Заметки и действие"
+// @Success 200 {object} dto.NoteBulkResult
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string "Заметки изменились во время операции"
+// @Failure 500 {object} map[string]string
+// @Router /notes/bulk [post]
+func (c *NoteBulkController) BulkNotes(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.NoteBulkInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := c.noteBulkService.Run(ctx, userID, &input)
+	if err != nil {
+		var syntaxErr *notequery.SyntaxError
+		switch {
+		case errors.As(err, &syntaxErr):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Error(), "position": syntaxErr.Position})
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Notes changed during the operation, nothing was applied"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
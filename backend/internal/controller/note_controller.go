@@ -7,6 +7,8 @@ import (
 
 	"valibibe/internal/controller/dto"
 	apperrors "valibibe/internal/errors"
+	"valibibe/internal/jsonpatch"
+	"valibibe/internal/notequery"
 	"valibibe/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -29,13 +31,18 @@ func NewNoteController(
 
 // CreateNote godoc
 // @Summary Создать новую заметку
+// @Description Заметка без note_type_id относится к встроенному типу Basic (заголовок и текст).
+// @Description Для пользовательского типа значения полей передаются в fields и проверяются по его описанию.
+// @Description Похожие заметки (тот же заголовок или похожий текст) возвращаются в duplicate_ids либо, при on_duplicate=reject, приводят к 409.
 // @Tags notes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param note body dto.NoteInput true "Данные заметки"
+// @Param on_duplicate query string false "Реакция на похожие заметки: warn — создать и вернуть duplicate_ids, reject — 409 с duplicate_ids, allow — не проверять" Enums(warn, reject, allow) default(warn)
 // @Success 201 {object} models.Note
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{} "Похожие заметки: error и duplicate_ids"
 // @Failure 500 {object} map[string]string
 // @Router /notes [post]
 func (c *NoteController) CreateNote(ctx *gin.Context) {
@@ -46,13 +53,24 @@ func (c *NoteController) CreateNote(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	input.OnDuplicate = ctx.Query("on_duplicate")
 
 	note, err := c.noteService.CreateNote(ctx, userID, &input)
 	if err != nil {
+		var duplicateErr *service.DuplicateNoteError
+		if errors.As(err, &duplicateErr) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": apperrors.ErrDuplicateNote.Error(), "duplicate_ids": duplicateErr.NoteIDs})
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setETag(ctx, note.Version)
 	ctx.JSON(http.StatusCreated, note)
 }
 
@@ -80,6 +98,7 @@ func (c *NoteController) GetNoteByID(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, note.Version)
 	ctx.JSON(http.StatusOK, note)
 }
 
@@ -89,14 +108,17 @@ func (c *NoteController) GetNoteByID(ctx *gin.Context) {
 // @Tags notes
 // @Security BearerAuth
 // @Produce json
-// @Param search query string false "Поиск по заголовку или содержимому"
-// @Param sort_by query string false "Поле сортировки: created_at (дата создания), next_review_at (дата следующего повторения)" Enums(created_at, next_review_at) default(created_at)
+// @Param q query string false "Запрос: tag:biology -tag:draft folder:\"Chem/Organic\" is:due is:archived level:<40 created:>2025-01-01 \"точная фраза\". Условия объединяются через AND, минус — отрицание"
+// @Param search query string false "Полнотекстовый поиск по заголовку и содержимому (со стеммингом). Подсветка совпадений возвращается в highlights"
+// @Param sort_by query string false "Поле сортировки: created_at (дата создания), next_review_at (дата следующего повторения), relevance (релевантность, по умолчанию при поиске), position (ручной порядок, вместе с order=asc)" Enums(created_at, next_review_at, relevance, position) default(created_at)
 // @Param order query string false "Порядок сортировки: asc (по возрастанию), desc (по убыванию)" Enums(asc, desc) default(desc)
 // @Param limit query int false "Максимальное количество записей" minimum(1) default(10)
 // @Param offset query int false "Смещение для пагинации" minimum(0) default(0)
 // @Param folder_id query string false "ID папки для фильтрации заметок по папке"
-// @Param tag_ids query []string false "Массив ID тегов для фильтрации заметок по тегам (через tag_ids[]=id1&tag_ids[]=id2)"
+// @Param include_subfolders query bool false "Вместе с folder_id: заметки всех подпапок" default(false)
+// @Param tag_ids query []string false "Массив ID тегов для фильтрации заметок по тегам (через tag_ids[]=id1&tag_ids[]=id2); родительский тег включает потомков"
 // @Success 200 {object} dto.PaginatedNotes
+// @Failure 400 {object} map[string]interface{} "Синтаксическая ошибка в q: error и position (позиция символа, с 1)"
 // @Failure 500 {object} map[string]string
 // @Router /notes [get]
 func (c *NoteController) GetAllNotes(ctx *gin.Context) {
@@ -118,21 +140,30 @@ func (c *NoteController) GetAllNotes(ctx *gin.Context) {
 		folderIDPtr = &folderID
 	}
 	tagIDs := ctx.QueryArray("tag_ids[]")
+	includeSubfolders, _ := strconv.ParseBool(ctx.Query("include_subfolders"))
 
 	filter := dto.NoteFilter{
 		UserID:   userID,
 		Search:   ctx.Query("search"),
-		SortBy:   ctx.DefaultQuery("sort_by", "created_at"),
+		SortBy:   ctx.Query("sort_by"),
 		Order:    ctx.DefaultQuery("order", "desc"),
 		Limit:    limit,
 		Offset:   offset,
 		Archived: archived,
 		FolderID: folderIDPtr,
 		TagIDs:   tagIDs,
+		Query:    ctx.Query("q"),
+
+		IncludeSubfolders: includeSubfolders,
 	}
 
 	result, err := c.noteService.GetAllNotesByUserID(ctx, &filter)
 	if err != nil {
+		var syntaxErr *notequery.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Error(), "position": syntaxErr.Position})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -142,15 +173,21 @@ func (c *NoteController) GetAllNotes(ctx *gin.Context) {
 
 // UpdateNote godoc
 // @Summary Обновить заметку
+// @Description Оптимистичная блокировка: нужна версия, с которой работал клиент (ETag или поле version).
 // @Tags notes
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Note ID"
 // @Param note body dto.NoteInput true "Обновлённые данные заметки"
+// @Param If-Match header string false "ETag заметки (версия); вместо него можно передать version в теле"
 // @Success 200 {object} models.Note
+// @Header 200 {string} ETag "Новая версия заметки"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{} "Устаревший version в теле: error и current — актуальная копия"
+// @Failure 412 {object} map[string]interface{} "Устаревший If-Match: error и current — актуальная копия"
+// @Failure 428 {object} map[string]string "Не передана версия"
 // @Failure 500 {object} map[string]string
 // @Router /notes/{id} [put]
 func (c *NoteController) UpdateNote(ctx *gin.Context) {
@@ -162,9 +199,20 @@ func (c *NoteController) UpdateNote(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	fromHeader, ok := applyIfMatch(ctx, &input.Version)
+	if !ok {
+		return
+	}
 
 	note, err := c.noteService.UpdateNote(ctx, userID, id, &input)
 	if err != nil {
+		if handleVersionError(ctx, err, fromHeader) {
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, apperrors.ErrNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
 			return
@@ -173,6 +221,121 @@ func (c *NoteController) UpdateNote(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, note.Version)
+	ctx.JSON(http.StatusOK, note)
+}
+
+// PatchNote godoc
+// @Summary Частично обновить заметку
+// @Description Принимает JSON Merge Patch (application/merge-patch+json или application/json) или JSON Patch (application/json-patch+json).
+// @Description Патч применяется к документу dto.NotePatchDocument: title, content, folder_id, archived, tag_ids, memory_level, next_review_at.
+// @Description Все изменения, включая теги, сохраняются атомарно. Версия — If-Match, член version (Merge Patch) или операция test по /version (JSON Patch).
+// @Tags notes
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param patch body dto.NotePatchDocument true "Патч документа заметки"
+// @Param If-Match header string false "ETag заметки (версия)"
+// @Success 200 {object} models.Note
+// @Header 200 {string} ETag "Новая версия заметки"
+// @Failure 400 {object} map[string]interface{} "Неверный патч или поля: error и fields — ошибки по полям"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{} "Устаревшая версия в патче или не прошла операция test"
+// @Failure 412 {object} map[string]interface{} "Устаревший If-Match: error и current — актуальная копия"
+// @Failure 415 {object} map[string]string
+// @Failure 428 {object} map[string]string "Не передана версия"
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id} [patch]
+func (c *NoteController) PatchNote(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	id := ctx.Param("id")
+
+	input := dto.NotePatchInput{}
+	switch ctx.ContentType() {
+	case "application/merge-patch+json", "application/json":
+		input.Format = dto.PatchFormatMerge
+	case "application/json-patch+json":
+		input.Format = dto.PatchFormatJSON
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json or application/json-patch+json"})
+		return
+	}
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Patch = body
+	fromHeader, ok := applyIfMatch(ctx, &input.Version)
+	if !ok {
+		return
+	}
+
+	note, err := c.noteService.PatchNote(ctx, userID, id, &input)
+	if err != nil {
+		if handleVersionError(ctx, err, fromHeader) {
+			return
+		}
+		var validation *service.PatchValidationError
+		switch {
+		case errors.As(err, &validation):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": validation.Fields})
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	setETag(ctx, note.Version)
+	ctx.JSON(http.StatusOK, note)
+}
+
+// MoveNote godoc
+// @Summary Переставить заметку внутри папки
+// @Description Ставит заметку перед before или после after — заметкой из той же папки (или тоже без папки);
+// @Description без них заметка становится последней. Ручной порядок отдаётся в GET /notes с sort_by=position&order=asc.
+// @Tags notes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param input body dto.MoveInput true "Новое место"
+// @Success 200 {object} models.Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/move [post]
+func (c *NoteController) MoveNote(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.MoveInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := c.noteService.MoveNote(ctx, userID, ctx.Param("id"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	setETag(ctx, note.Version)
 	ctx.JSON(http.StatusOK, note)
 }
 
@@ -231,7 +394,7 @@ func (c *NoteController) UnArchiveNote(ctx *gin.Context) {
 }
 
 // DeleteNote godoc
-// @Summary Удалить заметку
+// @Summary Переместить заметку в корзину
 // @Tags notes
 // @Security BearerAuth
 // @Produce json
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type NoteLinkController struct {
+	linkService *service.NoteLinkService
+}
+
+func NewNoteLinkController(linkService *service.NoteLinkService) *NoteLinkController {
+	return &NoteLinkController{linkService: linkService}
+}
+
+// ListLinks godoc
+// @Summary Исходящие ссылки заметки
+// @Description Возвращает ссылки [[Заголовок]] и [[id]] из текста заметки. Ссылка без существующей цели помечается dangling.
+// @Tags note-links
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Success 200 {array} dto.NoteLinkInfo
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/links [get]
+func (c *NoteLinkController) ListLinks(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	links, err := c.linkService.ListLinks(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, links)
+}
+
+// ListBacklinks godoc
+// @Summary Обратные ссылки
+// @Description Возвращает заметки, в тексте которых есть ссылка на эту заметку.
+// @Tags note-links
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Success 200 {array} dto.Backlink
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/backlinks [get]
+func (c *NoteLinkController) ListBacklinks(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	backlinks, err := c.linkService.ListBacklinks(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backlinks)
+}
+
+// ListDangling godoc
+// @Summary Висячие ссылки
+// @Description Отчёт о ссылках, которые не указывают ни на одну заметку (нет заметки с таким заголовком или она удалена).
+// @Tags note-links
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.DanglingLink
+// @Failure 500 {object} map[string]string
+// @Router /notes/links/dangling [get]
+func (c *NoteLinkController) ListDangling(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	links, err := c.linkService.ListDangling(ctx, userID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, links)
+}
+
+func (c *NoteLinkController) handleError(ctx *gin.Context, err error) {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
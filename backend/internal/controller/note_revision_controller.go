@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type NoteRevisionController struct {
+	revisionService *service.NoteRevisionService
+}
+
+func NewNoteRevisionController(revisionService *service.NoteRevisionService) *NoteRevisionController {
+	return &NoteRevisionController{revisionService: revisionService}
+}
+
+// ListRevisions godoc
+// @Summary История ревизий заметки
+// @Description Ревизии от последней к первой. Количество хранимых ревизий ограничено настройкой NOTE_REVISION_LIMIT.
+// @Tags revisions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Success 200 {array} dto.NoteRevisionInfo
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/revisions [get]
+func (c *NoteRevisionController) ListRevisions(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	revisions, err := c.revisionService.ListRevisions(ctx, userID, noteID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision godoc
+// @Summary Получить ревизию заметки
+// @Tags revisions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.NoteRevision
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/revisions/{rev} [get]
+func (c *NoteRevisionController) GetRevision(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	rev, ok := parseRevision(ctx, ctx.Param("rev"))
+	if !ok {
+		return
+	}
+
+	revision, err := c.revisionService.GetRevision(ctx, userID, noteID, rev)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary Построчный дифф между ревизиями
+// @Description Сравнивает текст ревизии from с ревизией to (по умолчанию — с последней).
+// @Tags revisions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param from query int true "Исходная ревизия"
+// @Param to query int false "Целевая ревизия (по умолчанию последняя)"
+// @Success 200 {object} dto.NoteRevisionDiff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/revisions/diff [get]
+func (c *NoteRevisionController) DiffRevisions(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	from, ok := parseRevision(ctx, ctx.Query("from"))
+	if !ok {
+		return
+	}
+	to := 0
+	if value := ctx.Query("to"); value != "" {
+		if to, ok = parseRevision(ctx, value); !ok {
+			return
+		}
+	}
+
+	result, err := c.revisionService.DiffRevisions(ctx, userID, noteID, from, to)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// RestoreRevision godoc
+// @Summary Восстановить заметку из ревизии
+// @Description Заголовок и текст заметки заменяются содержимым ревизии; восстановление сохраняется новой ревизией.
+// @Tags revisions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/revisions/{rev}/restore [post]
+func (c *NoteRevisionController) RestoreRevision(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	rev, ok := parseRevision(ctx, ctx.Param("rev"))
+	if !ok {
+		return
+	}
+
+	note, err := c.revisionService.RestoreRevision(ctx, userID, noteID, rev)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, note)
+}
+
+func (c *NoteRevisionController) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func parseRevision(ctx *gin.Context, value string) (int, bool) {
+	rev, err := strconv.Atoi(value)
+	if err != nil || rev <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "revision must be a positive integer"})
+		return 0, false
+	}
+	return rev, true
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type NoteTypeController struct {
+	noteTypeService *service.NoteTypeService
+}
+
+func NewNoteTypeController(noteTypeService *service.NoteTypeService) *NoteTypeController {
+	return &NoteTypeController{noteTypeService: noteTypeService}
+}
+
+// CreateNoteType godoc
+// @Summary Создать тип заметки
+// @Description Тип задаёт поля заметки (text, long_text, url, audio) и шаблоны сторон карточки в сессиях повторения.
+// @Description Шаблоны ссылаются на поля через {{Имя поля}}; если оба пусты, вопрос — первое поле, ответ — остальные.
+// @Tags note-types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.NoteTypeInput true "Описание типа"
+// @Success 201 {object} models.NoteType
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /note-types [post]
+func (c *NoteTypeController) CreateNoteType(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.NoteTypeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	noteType, err := c.noteTypeService.CreateNoteType(ctx, userID, &input)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, noteType)
+}
+
+// ListNoteTypes godoc
+// @Summary Типы заметок
+// @Description Возвращает встроенный тип Basic (builtin=true, нулевой id) и типы пользователя по алфавиту.
+// @Tags note-types
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.NoteType
+// @Failure 500 {object} map[string]string
+// @Router /note-types [get]
+func (c *NoteTypeController) ListNoteTypes(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	noteTypes, err := c.noteTypeService.ListNoteTypes(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, noteTypes)
+}
+
+// GetNoteType godoc
+// @Summary Получить тип заметки
+// @Tags note-types
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note type ID"
+// @Success 200 {object} models.NoteType
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /note-types/{id} [get]
+func (c *NoteTypeController) GetNoteType(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	noteType, err := c.noteTypeService.GetNoteType(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, noteType)
+}
+
+// UpdateNoteType godoc
+// @Summary Изменить тип заметки
+// @Description Заменяет имя, поля и шаблоны. Заметки не перепроверяются: значения удалённых полей сохраняются, но не показываются.
+// @Tags note-types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Note type ID"
+// @Param input body dto.NoteTypeInput true "Описание типа"
+// @Success 200 {object} models.NoteType
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /note-types/{id} [put]
+func (c *NoteTypeController) UpdateNoteType(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.NoteTypeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	noteType, err := c.noteTypeService.UpdateNoteType(ctx, userID, ctx.Param("id"), &input)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, noteType)
+}
+
+// DeleteNoteType godoc
+// @Summary Удалить тип заметки
+// @Description Тип, которым пользуются заметки (в том числе в корзине), удалить нельзя — 409.
+// @Tags note-types
+// @Security BearerAuth
+// @Param id path string true "Note type ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /note-types/{id} [delete]
+func (c *NoteTypeController) DeleteNoteType(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	if err := c.noteTypeService.DeleteNoteType(ctx, userID, ctx.Param("id")); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *NoteTypeController) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Note type not found"})
+	case errors.Is(err, apperrors.ErrDuplicateName), errors.Is(err, apperrors.ErrNoteTypeInUse):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
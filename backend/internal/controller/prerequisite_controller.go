@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type PrerequisiteController struct {
+	prerequisiteService *service.PrerequisiteService
+}
+
+func NewPrerequisiteController(prerequisiteService *service.PrerequisiteService) *PrerequisiteController {
+	return &PrerequisiteController{prerequisiteService: prerequisiteService}
+}
+
+// AddPrerequisite godoc
+// @Summary Добавить пререквизит заметке
+// @Description Заметка из тела запроса становится пререквизитом заметки {id}. Пока пререквизит не достигнет required_level, заметка {id} не попадает в очередь новых карточек.
+// @Tags prerequisites
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param input body dto.PrerequisiteInput true "Пререквизит"
+// @Success 201 {object} dto.Prerequisite
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/prerequisites [post]
+func (c *PrerequisiteController) AddPrerequisite(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	var input dto.PrerequisiteInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prerequisite, err := c.prerequisiteService.AddPrerequisite(ctx, userID, noteID, &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		case errors.Is(err, apperrors.ErrPrerequisiteCycle):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, prerequisite)
+}
+
+// GetPrerequisites godoc
+// @Summary Пререквизиты заметки
+// @Tags prerequisites
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Note ID"
+// @Success 200 {array} dto.Prerequisite
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/prerequisites [get]
+func (c *PrerequisiteController) GetPrerequisites(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+
+	prerequisites, err := c.prerequisiteService.GetPrerequisites(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, prerequisites)
+}
+
+// RemovePrerequisite godoc
+// @Summary Удалить пререквизит заметки
+// @Tags prerequisites
+// @Security BearerAuth
+// @Param id path string true "Note ID"
+// @Param prerequisiteId path string true "Prerequisite note ID"
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/prerequisites/{prerequisiteId} [delete]
+func (c *PrerequisiteController) RemovePrerequisite(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	noteID := ctx.Param("id")
+	prerequisiteID := ctx.Param("prerequisiteId")
+
+	if err := c.prerequisiteService.RemovePrerequisite(ctx, userID, noteID, prerequisiteID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Prerequisite not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetFolderGraph godoc
+// @Summary Граф зависимостей папки
+// @Description Узлы — заметки папки и связанные с ними заметки, рёбра направлены от пререквизита к зависимой заметке.
+// @Tags prerequisites
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} dto.PrerequisiteGraph
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /folders/{id}/prerequisite-graph [get]
+func (c *PrerequisiteController) GetFolderGraph(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	folderID := ctx.Param("id")
+
+	graph, err := c.prerequisiteService.GetFolderGraph(ctx, userID, folderID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, graph)
+}
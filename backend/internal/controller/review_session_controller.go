@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
 	"valibibe/internal/service"
 )
 
@@ -21,7 +23,7 @@ func NewReviewSessionController(reviewSessionService *service.ReviewSessionServi
 
 // CreateReviewSession godoc
 // @Summary Создать сессию повторения
-// @Description Создает сессию повторения с фильтрацией по папке и тегам. Возвращает заметки готовые к повторению, при нехватке добавляет случайные заметки.
+// @Description Создает сессию повторения с фильтрацией по папке (include_subfolders — вместе с подпапками) и тегам или по сохранённому поиску (saved_search_id). Возвращает заметки готовые к повторению, при нехватке добавляет случайные заметки. Действуют дневные лимиты новых карточек и повторений: у каждой папки на пути заметки свой лимит на всё её поддерево.
 // @Tags review-sessions
 // @Security BearerAuth
 // @Accept json
@@ -29,6 +31,7 @@ func NewReviewSessionController(reviewSessionService *service.ReviewSessionServi
 // @Param input body dto.ReviewSessionInput true "Параметры сессии повторения"
 // @Success 200 {object} dto.ReviewSessionResponse
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /review/sessions [post]
 func (c *ReviewSessionController) CreateReviewSession(ctx *gin.Context) {
@@ -50,6 +53,10 @@ func (c *ReviewSessionController) CreateReviewSession(ctx *gin.Context) {
 
 	result, err := c.reviewSessionService.CreateReviewSession(ctx, userID, &input)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/notequery"
+	"valibibe/internal/service"
+)
+
+type SavedSearchController struct {
+	savedSearchService *service.SavedSearchService
+}
+
+func NewSavedSearchController(savedSearchService *service.SavedSearchService) *SavedSearchController {
+	return &SavedSearchController{savedSearchService: savedSearchService}
+}
+
+// CreateSavedSearch godoc
+// @Summary Сохранить поиск
+// @Description Сохраняет набор фильтров заметок (q, search, папка, теги, архив, сортировка) под именем.
+// @Tags saved-searches
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.SavedSearchInput true "Фильтры сохранённого поиска"
+// @Success 201 {object} models.SavedSearch
+// @Failure 400 {object} map[string]interface{} "Неверные фильтры; для ошибки в q — error и position"
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches [post]
+func (c *SavedSearchController) CreateSavedSearch(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.SavedSearchInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := c.savedSearchService.CreateSavedSearch(ctx, userID, &input)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, search)
+}
+
+// ListSavedSearches godoc
+// @Summary Сохранённые поиски
+// @Description Возвращает сохранённые поиски пользователя по алфавиту; показываются рядом с деревом папок.
+// @Tags saved-searches
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SavedSearch
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches [get]
+func (c *SavedSearchController) ListSavedSearches(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	searches, err := c.savedSearchService.ListSavedSearches(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, searches)
+}
+
+// GetSavedSearch godoc
+// @Summary Получить сохранённый поиск
+// @Tags saved-searches
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Success 200 {object} models.SavedSearch
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches/{id} [get]
+func (c *SavedSearchController) GetSavedSearch(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	search, err := c.savedSearchService.GetSavedSearch(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, search)
+}
+
+// UpdateSavedSearch godoc
+// @Summary Изменить сохранённый поиск
+// @Description Полностью заменяет имя и фильтры сохранённого поиска.
+// @Tags saved-searches
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Param input body dto.SavedSearchInput true "Фильтры сохранённого поиска"
+// @Success 200 {object} models.SavedSearch
+// @Failure 400 {object} map[string]interface{} "Неверные фильтры; для ошибки в q — error и position"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches/{id} [put]
+func (c *SavedSearchController) UpdateSavedSearch(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.SavedSearchInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := c.savedSearchService.UpdateSavedSearch(ctx, userID, ctx.Param("id"), &input)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch godoc
+// @Summary Удалить сохранённый поиск
+// @Description Заметки не затрагиваются.
+// @Tags saved-searches
+// @Security BearerAuth
+// @Param id path string true "Saved search ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches/{id} [delete]
+func (c *SavedSearchController) DeleteSavedSearch(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	if err := c.savedSearchService.DeleteSavedSearch(ctx, userID, ctx.Param("id")); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RunSavedSearch godoc
+// @Summary Заметки сохранённого поиска
+// @Description Выполняет сохранённый поиск и возвращает страницу заметок в его сортировке.
+// @Tags saved-searches
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Param limit query int false "Максимальное количество записей" minimum(1) default(10)
+// @Param offset query int false "Смещение для пагинации" minimum(0) default(0)
+// @Success 200 {object} dto.PaginatedNotes
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /saved-searches/{id}/notes [get]
+func (c *SavedSearchController) RunSavedSearch(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	result, err := c.savedSearchService.RunSavedSearch(ctx, userID, ctx.Param("id"), limit, offset)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *SavedSearchController) handleError(ctx *gin.Context, err error) {
+	var syntaxErr *notequery.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Error(), "position": syntaxErr.Position})
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+	case errors.Is(err, apperrors.ErrDuplicateName):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type StatsController struct {
+	statsService *service.StatsService
+}
+
+func NewStatsController(statsService *service.StatsService) *StatsController {
+	return &StatsController{statsService: statsService}
+}
+
+// Simulate godoc
+// @Summary Симуляция нагрузки
+// @Description Прогоняет текущие заметки пользователя через планировщик и прогнозирует ежедневное число повторений, ожидаемое удержание и затраченное время. Вероятность вспомнить карточку берётся из истории повторений пользователя. Каждая заметка получает действующие настройки своей папки, поверх которых применяются кандидатные настройки; дневные лимиты считаются по поддеревьям папок, как в сессии повторения. Данные не изменяются.
+// @Tags stats
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body dto.SimulationInput true "Параметры симуляции"
+// @Success 200 {object} dto.SimulationResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/simulate [post]
+func (c *StatsController) Simulate(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.SimulationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := c.statsService.Simulate(ctx, userID, &input)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
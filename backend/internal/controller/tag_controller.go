@@ -21,7 +21,8 @@ func NewTagController(tagService *service.TagService) *TagController {
 
 // CreateTag godoc
 // @Summary      Создать тег
-// @Description  Создаёт новый тег для пользователя
+// @Description  Создаёт новый тег для пользователя. Имя вида "lang::spanish::verbs" задаёт иерархию;
+// @Description  недостающие родительские теги ("lang", "lang::spanish") создаются автоматически
 // @Tags         tags
 // @Accept       json
 // @Produce      json
@@ -42,6 +43,10 @@ func (tc *TagController) CreateTag(ctx *gin.Context) {
 
 	tag, err := tc.tagService.CreateTag(ctx, userID, input)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == "tag with this name already exists" {
 			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
@@ -50,6 +55,7 @@ func (tc *TagController) CreateTag(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, tag.Version)
 	ctx.JSON(http.StatusCreated, tag)
 }
 
@@ -78,6 +84,7 @@ func (tc *TagController) GetTag(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, tag.Version)
 	ctx.JSON(http.StatusOK, tag)
 }
 
@@ -101,18 +108,43 @@ func (tc *TagController) ListTags(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, tags)
 }
 
+// GetTagTree godoc
+// @Summary      Дерево тегов
+// @Description  Возвращает теги пользователя иерархией по путям вида "lang::spanish::verbs"
+// @Tags         tags
+// @Security BearerAuth
+// @Produce      json
+// @Success      200  {array}   dto.TagNode
+// @Failure      500  {object}  map[string]string
+// @Router       /tags/tree [get]
+func (tc *TagController) GetTagTree(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	tree, err := tc.tagService.GetTagTree(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tree)
+}
+
 // UpdateTag godoc
 // @Summary      Обновить тег
-// @Description  Обновляет имя тега по ID
+// @Description  Обновляет имя (полный путь) тега по ID. Потомки переименовываются вместе с ним:
+// @Description  "lang" → "language" превращает "lang::spanish" в "language::spanish"
 // @Tags         tags
 // @Accept       json
 // @Produce      json
 // @Param        id    path      string             true  "ID тега"
+// @Param        If-Match  header  string  false  "ETag тега; заменяет поле version"
 // @Param        body  body      dto.TagUpdateInput true  "Новые данные"
 // @Success      200   {object}  models.Tag
 // @Failure      400   {object}  map[string]string
 // @Failure      404   {object}  map[string]string
-// @Failure      409   {object}  map[string]string
+// @Failure      409   {object}  map[string]interface{}
+// @Failure      412   {object}  map[string]interface{}
+// @Failure      428   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /tags/{id} [put]
 func (tc *TagController) UpdateTag(ctx *gin.Context) {
@@ -124,13 +156,24 @@ func (tc *TagController) UpdateTag(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
 		return
 	}
+	fromHeader, ok := applyIfMatch(ctx, &input.Version)
+	if !ok {
+		return
+	}
 
 	tag, err := tc.tagService.UpdateTag(ctx, userID, tagID, input)
 	if err != nil {
+		if handleVersionError(ctx, err, fromHeader) {
+			return
+		}
 		if errors.Is(err, apperrors.ErrNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
 			return
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == "tag with this name already exists" {
 			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
@@ -139,12 +182,13 @@ func (tc *TagController) UpdateTag(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, tag.Version)
 	ctx.JSON(http.StatusOK, tag)
 }
 
 // DeleteTag godoc
 // @Summary      Удалить тег
-// @Description  Удаляет тег по ID
+// @Description  Перемещает тег в корзину; связи с заметками восстанавливаются вместе с тегом
 // @Tags         tags
 // @Produce      json
 // @Param        id   path      string  true  "ID тега"
@@ -168,3 +212,84 @@ func (tc *TagController) DeleteTag(ctx *gin.Context) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// MergeTags godoc
+// @Summary      Слить теги
+// @Description  Переносит связи заметок с тегов source_ids на target_id и удаляет исходные теги.
+// @Description  Заметка, у которой уже был target или несколько исходных тегов, получает одну связь.
+// @Description  Дочерние теги исходных (bio::cells) переезжают под target (biology::cells).
+// @Tags         tags
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.TagMergeInput  true  "Исходные теги и тег, в который они сливаются"
+// @Success      200   {object}  dto.TagMergeResult
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /tags/merge [post]
+func (tc *TagController) MergeTags(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.TagMergeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	result, err := tc.tagService.MergeTags(ctx, userID, input)
+	if err != nil {
+		tc.handleBatchError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// BulkRenameTags godoc
+// @Summary      Массово переименовать теги
+// @Description  mode=prefix заменяет начало имени pattern (без учёта регистра) на replacement,
+// @Description  mode=regex применяет регулярное выражение Go ко всему имени ($1 в replacement — группа).
+// @Description  Если новые имена совпадут с другими тегами, ничего не меняется (409); dry_run возвращает план с конфликтами.
+// @Tags         tags
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.TagBulkRenameInput  true  "Шаблон и замена"
+// @Success      200   {object}  dto.TagBulkRenameResult
+// @Failure      400   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /tags/bulk-rename [post]
+func (tc *TagController) BulkRenameTags(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var input dto.TagBulkRenameInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	result, err := tc.tagService.BulkRenameTags(ctx, userID, input)
+	if err != nil {
+		tc.handleBatchError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// handleBatchError переводит ошибки слияния и массового переименования в HTTP-статус
+func (tc *TagController) handleBatchError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+	case errors.Is(err, apperrors.ErrDuplicateName):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "tag with this name already exists"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+type TrashController struct {
+	trashService *service.TrashService
+}
+
+func NewTrashController(trashService *service.TrashService) *TrashController {
+	return &TrashController{trashService: trashService}
+}
+
+// ListTrash godoc
+// @Summary Содержимое корзины
+// @Description Удалённые заметки, папки и теги. Папка показывается одной записью вместе с удалёнными вместе с ней вложенными папками и заметками. После retention_days объекты удаляются окончательно.
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.TrashList
+// @Failure 500 {object} map[string]string
+// @Router /trash [get]
+func (c *TrashController) ListTrash(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	trash, err := c.trashService.ListTrash(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trash)
+}
+
+// RestoreItem godoc
+// @Summary Восстановить объект из корзины
+// @Description Восстанавливает заметку, папку или тег. Удалённые родительские папки восстанавливаются вместе с объектом, связи заметок с тегами сохраняются.
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID заметки, папки или тега"
+// @Success 200 {object} dto.TrashItem
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /trash/{id}/restore [post]
+func (c *TrashController) RestoreItem(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+	itemID := ctx.Param("id")
+
+	item, err := c.trashService.Restore(ctx, userID, itemID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Item not found in trash"})
+		case errors.Is(err, apperrors.ErrRestoreConflict):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
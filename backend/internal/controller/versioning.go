@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/service"
+)
+
+// setETag отдаёт версию объекта в заголовке ETag
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// applyIfMatch переносит версию из заголовка If-Match ("3" или W/"3") в version;
+// заголовок важнее поля version в теле. Второе значение — заголовок был.
+// При неверном заголовке отвечает 400 и возвращает ok=false.
+func applyIfMatch(ctx *gin.Context, version **int) (fromHeader bool, ok bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return false, true
+	}
+
+	value, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err == nil {
+		var v int
+		if v, err = strconv.Atoi(value); err == nil {
+			*version = &v
+			return true, true
+		}
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must contain the ETag of the resource"})
+	return true, false
+}
+
+// handleVersionError отвечает на ошибки оптимистичной блокировки:
+// 428 без версии, 412 (If-Match) или 409 (version в теле) с актуальной копией при конфликте.
+// Возвращает false, если err к версиям не относится.
+func handleVersionError(ctx *gin.Context, err error, fromHeader bool) bool {
+	var conflict *service.VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		status := http.StatusConflict
+		if fromHeader {
+			status = http.StatusPreconditionFailed
+		}
+		setETag(ctx, conflict.Version)
+		ctx.JSON(status, gin.H{"error": conflict.Error(), "current": conflict.Current})
+		return true
+	case errors.Is(err, apperrors.ErrVersionRequired):
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error()})
+		return true
+	}
+	return false
+}
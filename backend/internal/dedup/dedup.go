@@ -0,0 +1,339 @@
+// Package dedup ищет дубликаты заметок: совпадение нормализованных заголовков
+// или похожий текст. Похожесть текста оценивается по MinHash-сигнатурам символьных шинглов,
+// а кандидаты для сравнения отбираются через LSH (сигнатура режется на полосы),
+// поэтому отчёт по всей коллекции не сравнивает каждую пару заметок.
+package dedup
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Параметры MinHash: сигнатура из bands*rows хешей.
+// При 16 полосах по 4 хеша пара с похожестью 0.8 становится кандидатом с вероятностью ~0.999,
+// а пара с похожестью 0.3 — меньше чем в 13% случаев.
+const (
+	shingleSize = 5
+	bands       = 16
+	rows        = 4
+	numHashes   = bands * rows
+)
+
+// DefaultThreshold — минимальная оценка похожести текста для дубликата
+const DefaultThreshold = 0.8
+
+// Document — заметка для сравнения
+type Document struct {
+	ID      string
+	Title   string
+	Content string
+}
+
+// Match — найденный дубликат. TitleMatch — совпали нормализованные заголовки,
+// Similarity — оценка похожести текста (доля совпавших хешей сигнатуры, от 0 до 1).
+type Match struct {
+	ID         string
+	TitleMatch bool
+	Similarity float64
+}
+
+// signature — MinHash-сигнатура текста; empty — в тексте нет букв и цифр
+type signature struct {
+	hashes [numHashes]uint32
+	empty  bool
+}
+
+// seeds — параметры хеш-функций h_i(x) = a_i*x + b_i; фиксированы, чтобы сигнатуры были воспроизводимы
+var seeds = func() [numHashes][2]uint32 {
+	var s [numHashes][2]uint32
+	state := uint32(2166136261)
+	next := func() uint32 {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		return state
+	}
+	for i := range s {
+		s[i] = [2]uint32{next() | 1, next()}
+	}
+	return s
+}()
+
+// Normalize приводит текст к нижнему регистру, убирает пунктуацию и лишние пробелы
+func Normalize(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// sign строит MinHash-сигнатуру по шинглам из shingleSize символов нормализованного текста.
+// Текст короче шингла даёт один шингл из всего текста.
+func sign(text string) signature {
+	runes := []rune(Normalize(text))
+	var sig signature
+	if len(runes) == 0 {
+		sig.empty = true
+		return sig
+	}
+
+	for i := range sig.hashes {
+		sig.hashes[i] = ^uint32(0)
+	}
+	last := len(runes) - shingleSize
+	if last < 0 {
+		last = 0
+	}
+	for i := 0; i <= last; i++ {
+		end := i + shingleSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(string(runes[i:end])))
+		x := h.Sum32()
+		for j, seed := range seeds {
+			if v := seed[0]*x + seed[1]; v < sig.hashes[j] {
+				sig.hashes[j] = v
+			}
+		}
+	}
+	return sig
+}
+
+func similarity(a, b *signature) float64 {
+	if a.empty || b.empty {
+		return 0
+	}
+	equal := 0
+	for i := range a.hashes {
+		if a.hashes[i] == b.hashes[i] {
+			equal++
+		}
+	}
+	return float64(equal) / numHashes
+}
+
+type bandKey struct {
+	band int
+	hash uint64
+}
+
+func bandKeys(sig *signature) []bandKey {
+	if sig.empty {
+		return nil
+	}
+	keys := make([]bandKey, bands)
+	for band := 0; band < bands; band++ {
+		var hash uint64 = 14695981039346656037
+		for _, v := range sig.hashes[band*rows : (band+1)*rows] {
+			hash ^= uint64(v)
+			hash *= 1099511628211
+		}
+		keys[band] = bandKey{band: band, hash: hash}
+	}
+	return keys
+}
+
+// Keys возвращает ключи документа для поиска кандидатов в базе: хеш нормализованного
+// заголовка и по ключу на каждую полосу сигнатуры. Документы-кандидаты — те, у кого
+// совпал хотя бы один ключ; похожесть затем проверяется через Index
+func Keys(doc Document) []int64 {
+	var keys []int64
+	if title := Normalize(doc.Title); title != "" {
+		h := fnv.New64a()
+		h.Write([]byte("title:" + title))
+		keys = append(keys, int64(h.Sum64()))
+	}
+	sig := sign(doc.Content)
+	for _, key := range bandKeys(&sig) {
+		// номер полосы подмешивается в хеш: одинаковые значения разных полос — разные ключи
+		keys = append(keys, int64((key.hash^uint64(key.band+1))*1099511628211))
+	}
+	return keys
+}
+
+type entry struct {
+	doc   Document
+	title string
+	sig   signature
+}
+
+// Index — набор заметок, среди которых ищутся дубликаты
+type Index struct {
+	threshold float64
+	entries   []entry
+	byTitle   map[string][]int
+	byBand    map[bandKey][]int
+}
+
+// NewIndex создаёт пустой индекс; threshold — минимальная похожесть текста
+func NewIndex(threshold float64) *Index {
+	return &Index{
+		threshold: threshold,
+		byTitle:   make(map[string][]int),
+		byBand:    make(map[bandKey][]int),
+	}
+}
+
+// Add добавляет заметку в индекс
+func (idx *Index) Add(doc Document) {
+	e := entry{doc: doc, title: Normalize(doc.Title), sig: sign(doc.Content)}
+	i := len(idx.entries)
+	idx.entries = append(idx.entries, e)
+	if e.title != "" {
+		idx.byTitle[e.title] = append(idx.byTitle[e.title], i)
+	}
+	for _, key := range bandKeys(&e.sig) {
+		idx.byBand[key] = append(idx.byBand[key], i)
+	}
+}
+
+// Matches возвращает дубликаты документа среди добавленных заметок (сам документ по ID пропускается),
+// сначала самые похожие
+func (idx *Index) Matches(doc Document) []Match {
+	return idx.matches(entry{doc: doc, title: Normalize(doc.Title), sig: sign(doc.Content)})
+}
+
+func (idx *Index) matches(e entry) []Match {
+	candidates := make(map[int]bool)
+	if e.title != "" {
+		for _, i := range idx.byTitle[e.title] {
+			candidates[i] = true
+		}
+	}
+	for _, key := range bandKeys(&e.sig) {
+		for _, i := range idx.byBand[key] {
+			candidates[i] = true
+		}
+	}
+
+	matches := make([]Match, 0)
+	for i := range candidates {
+		other := &idx.entries[i]
+		if other.doc.ID == e.doc.ID {
+			continue
+		}
+		m := Match{
+			ID:         other.doc.ID,
+			TitleMatch: e.title != "" && e.title == other.title,
+			Similarity: similarity(&e.sig, &other.sig),
+		}
+		if m.TitleMatch || m.Similarity >= idx.threshold {
+			matches = append(matches, m)
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// Group — группа вероятных дубликатов. Similarity — наибольшая похожесть текста среди найденных пар.
+type Group struct {
+	IDs        []string
+	TitleMatch bool
+	Similarity float64
+}
+
+// Groups объединяет добавленные заметки в группы дубликатов (транзитивно):
+// если A похожа на B, а B на C, все три попадают в одну группу.
+// Группы упорядочены по похожести, ID внутри группы — в порядке добавления.
+func (idx *Index) Groups() []Group {
+	parent := make([]int, len(idx.entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	positions := make(map[string]int, len(idx.entries))
+	for i, e := range idx.entries {
+		positions[e.doc.ID] = i
+	}
+
+	type pair struct {
+		first int
+		match Match
+	}
+	pairs := make([]pair, 0)
+	for i, e := range idx.entries {
+		for _, m := range idx.matches(e) {
+			j := positions[m.ID]
+			if j <= i {
+				continue
+			}
+			pairs = append(pairs, pair{first: i, match: m})
+			if a, b := find(i), find(j); a != b {
+				parent[b] = a
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range idx.entries {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+	groupOf := make(map[int]*Group)
+	groups := make([]*Group, 0)
+	for root, list := range members {
+		if len(list) < 2 {
+			continue
+		}
+		sort.Ints(list)
+		g := &Group{IDs: make([]string, len(list))}
+		for k, i := range list {
+			g.IDs[k] = idx.entries[i].doc.ID
+		}
+		groupOf[root] = g
+		groups = append(groups, g)
+	}
+	for _, p := range pairs {
+		g := groupOf[find(p.first)]
+		g.TitleMatch = g.TitleMatch || p.match.TitleMatch
+		if p.match.Similarity > g.Similarity {
+			g.Similarity = p.match.Similarity
+		}
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		if groups[a].Similarity != groups[b].Similarity {
+			return groups[a].Similarity > groups[b].Similarity
+		}
+		return positions[groups[a].IDs[0]] < positions[groups[b].IDs[0]]
+	})
+	result := make([]Group, len(groups))
+	for i, g := range groups {
+		result[i] = *g
+	}
+	return result
+}
+
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		if matches[i].TitleMatch != matches[j].TitleMatch {
+			return matches[i].TitleMatch
+		}
+		return matches[i].ID < matches[j].ID
+	})
+}
@@ -0,0 +1,159 @@
+// Package diff строит построчную разницу между двумя текстами (алгоритм Майерса).
+package diff
+
+import "strings"
+
+// Операции построчного диффа
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Line — строка результата. OldLine и NewLine — номера строк (с 1)
+// в исходном и новом тексте; 0 означает, что строки там нет.
+type Line struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// SplitLines разбивает текст на строки; \r\n и \n считаются одинаковыми переводами строки
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Lines возвращает минимальный построчный дифф от old к new
+func Lines(old, new string) []Line {
+	a, b := SplitLines(old), SplitLines(new)
+
+	// Общие начало и конец не участвуют в поиске и сокращают работу алгоритма
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Line, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		ops = append(ops, Line{Op: OpEqual, Text: text})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		ops = append(ops, Line{Op: OpEqual, Text: text})
+	}
+
+	// Нумерация строк
+	oldLine, newLine := 0, 0
+	for i := range ops {
+		switch ops[i].Op {
+		case OpEqual:
+			oldLine++
+			newLine++
+			ops[i].OldLine, ops[i].NewLine = oldLine, newLine
+		case OpDelete:
+			oldLine++
+			ops[i].OldLine = oldLine
+		case OpInsert:
+			newLine++
+			ops[i].NewLine = newLine
+		}
+	}
+	return ops
+}
+
+// Stats возвращает число добавленных и удалённых строк
+func Stats(lines []Line) (added, removed int) {
+	for _, l := range lines {
+		switch l.Op {
+		case OpInsert:
+			added++
+		case OpDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// myers ищет кратчайший путь редактирования и восстанавливает его с конца
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0)
+
+search:
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	reversed := make([]Line, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: OpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, Line{Op: OpInsert, Text: b[y-1]})
+			} else {
+				reversed = append(reversed, Line{Op: OpDelete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]Line, len(reversed))
+	for i, l := range reversed {
+		ops[len(reversed)-1-i] = l
+	}
+	return ops
+}
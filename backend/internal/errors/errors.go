@@ -2,4 +2,36 @@ package errors
 
 import "errors"
 
-var ErrNotFound = errors.New("resource not found or access denied")
\ No newline at end of file
+var ErrNotFound = errors.New("resource not found or access denied")
+
+var ErrInvalidInput = errors.New("invalid input")
+
+// Ошибки экзаменов
+var (
+	ErrExamSubmitted      = errors.New("exam is already submitted")
+	ErrExamDeadlinePassed = errors.New("exam deadline has passed")
+	ErrQuestionAnswered   = errors.New("question is already answered")
+)
+
+var ErrPrerequisiteCycle = errors.New("prerequisite link would create a cycle")
+
+// ErrFolderCycle — папку нельзя перенести в неё саму или в её подпапку
+var ErrFolderCycle = errors.New("folder cannot be moved into itself or its subfolder")
+
+// ErrRestoreConflict — на месте восстанавливаемого объекта уже есть объект с тем же именем
+var ErrRestoreConflict = errors.New("an item with the same name already exists")
+
+// ErrDuplicateName — у пользователя уже есть объект с таким именем
+var ErrDuplicateName = errors.New("an item with this name already exists")
+
+// ErrNoteTypeInUse — тип заметки нельзя удалить, пока есть заметки этого типа
+var ErrNoteTypeInUse = errors.New("note type is used by notes")
+
+// ErrDuplicateNote — похожая заметка уже есть
+var ErrDuplicateNote = errors.New("note duplicates existing notes")
+
+// Оптимистичная блокировка: обновление требует версию, с которой работал клиент
+var (
+	ErrVersionRequired = errors.New("version is required: send If-Match header or version field")
+	ErrVersionConflict = errors.New("resource was modified by another request")
+)
@@ -0,0 +1,287 @@
+// Package fractional строит ключи дробной индексации для ручного порядка элементов.
+//
+// Ключ — строка из цифр base62, которая сравнивается побайтно. Между любыми двумя
+// ключами можно вставить новый, не меняя соседние, поэтому перестановка элемента
+// записывает только его собственный ключ. Ключ состоит из целой части переменной
+// длины (первый символ задаёт её длину) и дробной части без нулей в конце —
+// так при добавлении в конец ключи растут медленно.
+//
+// Схема совпадает с библиотекой fractional-indexing (rocicorp), поэтому ключи
+// совместимы с клиентами, которые считают их сами.
+package fractional
+
+import (
+	"errors"
+	"strings"
+)
+
+// Digits — алфавит ключей в порядке возрастания байтов
+const Digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidKey — ключ не соответствует формату или границы заданы не по возрастанию
+var ErrInvalidKey = errors.New("invalid fractional key")
+
+const zero = '0'
+
+// smallestInteger — наименьшая целая часть; ключ из неё одной недопустим
+var smallestInteger = "A" + strings.Repeat(string(zero), 26)
+
+// Between возвращает ключ строго между a и b. Пустая строка означает отсутствие
+// границы: Between("", "") — первый ключ, Between(a, "") — ключ после a.
+func Between(a, b string) (string, error) {
+	if a != "" {
+		if err := Validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := Validate(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalidKey
+	}
+
+	if a == "" {
+		if b == "" {
+			return "a0", nil
+		}
+		ib, _ := integerPart(b)
+		fb := b[len(ib):]
+		if ib == smallestInteger {
+			m, err := midpoint("", fb)
+			if err != nil {
+				return "", err
+			}
+			return ib + m, nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		res, ok := decrementInteger(ib)
+		if !ok {
+			return "", ErrInvalidKey
+		}
+		return res, nil
+	}
+
+	ia, _ := integerPart(a)
+	fa := a[len(ia):]
+	if b == "" {
+		if next, ok := incrementInteger(ia); ok {
+			return next, nil
+		}
+		m, err := midpoint(fa, "")
+		if err != nil {
+			return "", err
+		}
+		return ia + m, nil
+	}
+
+	ib, _ := integerPart(b)
+	fb := b[len(ib):]
+	if ia == ib {
+		m, err := midpoint(fa, fb)
+		if err != nil {
+			return "", err
+		}
+		return ia + m, nil
+	}
+	next, ok := incrementInteger(ia)
+	if !ok {
+		return "", ErrInvalidKey
+	}
+	if next < b {
+		return next, nil
+	}
+	m, err := midpoint(fa, "")
+	if err != nil {
+		return "", err
+	}
+	return ia + m, nil
+}
+
+// Sequence возвращает n возрастающих ключей между a и b, например для начальной расстановки
+func Sequence(a, b string, n int) ([]string, error) {
+	keys := make([]string, 0, n)
+	prev := a
+	for i := 0; i < n; i++ {
+		key, err := Between(prev, b)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		prev = key
+	}
+	return keys, nil
+}
+
+// Validate проверяет формат ключа
+func Validate(key string) error {
+	if key == smallestInteger {
+		return ErrInvalidKey
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(Digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+	i, err := integerPart(key)
+	if err != nil {
+		return err
+	}
+	if f := key[len(i):]; f != "" && f[len(f)-1] == zero {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// midpoint возвращает дробную часть строго между a и b (b == "" — без верхней границы)
+func midpoint(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", ErrInvalidKey
+	}
+	if (a != "" && a[len(a)-1] == zero) || (b != "" && b[len(b)-1] == zero) {
+		return "", ErrInvalidKey
+	}
+
+	if b != "" {
+		// общий префикс переносится как есть
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			m, err := midpoint(tail(a, n), b[n:])
+			if err != nil {
+				return "", err
+			}
+			return b[:n] + m, nil
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(Digits, a[0])
+	}
+	digitB := len(Digits)
+	if b != "" {
+		digitB = strings.IndexByte(Digits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(Digits[(digitA+digitB+1)/2]), nil
+	}
+	if len(b) > 1 {
+		return b[:1], nil
+	}
+	m, err := midpoint(tail(a, 1), "")
+	if err != nil {
+		return "", err
+	}
+	return string(Digits[digitA]) + m, nil
+}
+
+// integerLength — длина целой части по её первому символу
+func integerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	}
+	return 0, ErrInvalidKey
+}
+
+func integerPart(key string) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	n, err := integerLength(key[0])
+	if err != nil {
+		return "", err
+	}
+	if n > len(key) {
+		return "", ErrInvalidKey
+	}
+	return key[:n], nil
+}
+
+// incrementInteger возвращает следующую целую часть; false — целые части исчерпаны
+func incrementInteger(x string) (string, bool) {
+	head := x[0]
+	digits := []byte(x[1:])
+	carry := true
+	for i := len(digits) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(Digits, digits[i]) + 1
+		if d == len(Digits) {
+			digits[i] = zero
+		} else {
+			digits[i] = Digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(digits), true
+	}
+	switch head {
+	case 'Z':
+		return "a" + string(zero), true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		digits = append(digits, zero)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+// decrementInteger возвращает предыдущую целую часть; false — целые части исчерпаны
+func decrementInteger(x string) (string, bool) {
+	last := Digits[len(Digits)-1]
+	head := x[0]
+	digits := []byte(x[1:])
+	borrow := true
+	for i := len(digits) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(Digits, digits[i]) - 1
+		if d == -1 {
+			digits[i] = last
+		} else {
+			digits[i] = Digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(digits), true
+	}
+	switch head {
+	case 'a':
+		return "Z" + string(last), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		digits = append(digits, last)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return zero
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
@@ -0,0 +1,145 @@
+// Package graphexport сериализует граф знаний в GraphML и DOT (Graphviz).
+package graphexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"valibibe/internal/controller/dto"
+)
+
+// Форматы выгрузки графа
+const (
+	FormatJSON    = "json"
+	FormatGraphML = "graphml"
+	FormatDOT     = "dot"
+)
+
+// Content-Type для каждого формата
+var ContentTypes = map[string]string{
+	FormatJSON:    "application/json; charset=utf-8",
+	FormatGraphML: "application/graphml+xml; charset=utf-8",
+	FormatDOT:     "text/vnd.graphviz; charset=utf-8",
+}
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLItem `xml:"node"`
+	Edges       []graphMLItem `xml:"edge"`
+}
+
+type graphMLItem struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GraphML возвращает граф в формате GraphML (ориентированный граф)
+func GraphML(g *dto.Graph) ([]byte, error) {
+	doc := graphML{
+		XMLNS: graphMLNamespace,
+		Keys: []graphMLKey{
+			{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "memory_level", For: "node", AttrName: "memory_level", AttrType: "int"},
+			{ID: "archived", For: "node", AttrName: "archived", AttrType: "boolean"},
+			{ID: "edge_type", For: "edge", AttrName: "type", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          "knowledge",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLItem, 0, len(g.Nodes)),
+			Edges:       make([]graphMLItem, 0, len(g.Edges)),
+		},
+	}
+
+	for _, n := range g.Nodes {
+		item := graphMLItem{ID: n.ID, Data: []graphMLData{
+			{Key: "type", Value: n.Type},
+			{Key: "label", Value: n.Label},
+		}}
+		if n.MemoryLevel != nil {
+			item.Data = append(item.Data,
+				graphMLData{Key: "memory_level", Value: strconv.Itoa(*n.MemoryLevel)},
+				graphMLData{Key: "archived", Value: strconv.FormatBool(n.Archived)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, item)
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLItem{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: e.Source,
+			Target: e.Target,
+			Data:   []graphMLData{{Key: "edge_type", Value: e.Type}},
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// dotShapes — форма узла в Graphviz по типу
+var dotShapes = map[string]string{
+	dto.GraphNodeNote:   "box",
+	dto.GraphNodeTag:    "ellipse",
+	dto.GraphNodeFolder: "folder",
+}
+
+// dotStyles — стиль ребра в Graphviz по типу
+var dotStyles = map[string]string{
+	dto.GraphEdgeTagged:       "dashed",
+	dto.GraphEdgeInFolder:     "dotted",
+	dto.GraphEdgeChildOf:      "bold",
+	dto.GraphEdgeLinksTo:      "solid",
+	dto.GraphEdgePrerequisite: "solid",
+}
+
+// DOT возвращает граф на языке DOT (Graphviz)
+func DOT(g *dto.Graph) []byte {
+	var b strings.Builder
+	b.WriteString("digraph knowledge {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, type=%s, shape=%s];\n",
+			dotQuote(n.ID), dotQuote(n.Label), dotQuote(n.Type), dotShapes[n.Type])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [type=%s, style=%s];\n",
+			dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.Type), dotStyles[e.Type])
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,420 @@
+// Package jsonpatch применяет к JSON-документам изменения в форматах
+// JSON Merge Patch (RFC 7396) и JSON Patch (RFC 6902).
+// Документы — результат json.Unmarshal в interface{}: map[string]interface{},
+// []interface{}, float64, string, bool и nil. Исходный документ не изменяется.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch — патч составлен неверно (неизвестная операция, нет обязательного члена и т. п.)
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPathNotFound — путь операции не существует в документе
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTestFailed — операция test не совпала с документом
+	ErrTestFailed = errors.New("test operation failed")
+)
+
+// Операции JSON Patch
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation — одна операция JSON Patch
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// OpError — ошибка операции с номером index (с нуля); Unwrap возвращает одну из ошибок пакета
+type OpError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Err.Error())
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// ParseOperations разбирает документ JSON Patch — массив операций
+func ParseOperations(data []byte) ([]Operation, error) {
+	var raw []struct {
+		Op    string          `json:"op"`
+		Path  *string         `json:"path"`
+		From  *string         `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: patch must be an array of operations", ErrInvalidPatch)
+	}
+
+	ops := make([]Operation, len(raw))
+	for i, r := range raw {
+		op := Operation{Op: r.Op}
+		fail := func(msg string) error {
+			return &OpError{Index: i, Op: r.Op, Path: op.Path, Err: fmt.Errorf("%w: %s", ErrInvalidPatch, msg)}
+		}
+		if r.Path == nil {
+			return nil, fail(`"path" is required`)
+		}
+		op.Path = *r.Path
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, fail(err.Error())
+		}
+
+		switch r.Op {
+		case OpAdd, OpReplace, OpTest:
+			if r.Value == nil {
+				return nil, fail(`"value" is required`)
+			}
+			if err := decode(r.Value, &op.Value); err != nil {
+				return nil, fail(`"value" is not valid JSON`)
+			}
+		case OpMove, OpCopy:
+			if r.From == nil {
+				return nil, fail(`"from" is required`)
+			}
+			op.From = *r.From
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, fail(err.Error())
+			}
+		case OpRemove:
+		default:
+			return nil, fail(fmt.Sprintf("unknown operation %q", r.Op))
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+// Decode разбирает JSON-значение в представление, с которым работает пакет
+func Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := decode(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func decode(data []byte, v *interface{}) error {
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// MergePatch применяет JSON Merge Patch: члены объекта patch заменяют члены target,
+// null удаляет член, вложенные объекты сливаются рекурсивно, всё остальное заменяется целиком
+func MergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result := make(map[string]interface{})
+	if targetObj, ok := target.(map[string]interface{}); ok {
+		for k, v := range targetObj {
+			result[k] = v
+		}
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = MergePatch(result[k], v)
+	}
+	return result
+}
+
+// Apply применяет операции JSON Patch по порядку. Патч атомарен:
+// при ошибке любой операции возвращается ошибка, а не частично изменённый документ.
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		doc, err = applyOne(doc, op)
+		if err != nil {
+			return nil, &OpError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+func applyOne(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return add(doc, path, deepCopy(op.Value))
+	case OpRemove:
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case OpReplace:
+		return replace(doc, path, deepCopy(op.Value))
+	case OpMove:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+		}
+		if len(from) < len(path) && isPrefix(from, path) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(value))
+	case OpTest:
+		value, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901): "" — весь документ, "/a/0" — элемент 0 члена a
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		// после ~ допустимы только 0 и 1
+		for j := 0; j < len(t); j++ {
+			if t[j] != '~' {
+				continue
+			}
+			if j+1 == len(t) || (t[j+1] != '0' && t[j+1] != '1') {
+				return nil, fmt.Errorf("path %q has an invalid ~ escape", pointer)
+			}
+			j++
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex разбирает индекс массива длины n; "-" (конец массива) допустим, только если allowEnd
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return n, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrPathNotFound
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, ErrPathNotFound
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, ErrPathNotFound
+	}
+	limit := n - 1
+	if allowEnd {
+		limit = n
+	}
+	if i > limit {
+		return 0, ErrPathNotFound
+	}
+	return i, nil
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			node = child
+		case []interface{}:
+			i, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return node, nil
+}
+
+// modify находит контейнер, в котором лежит последний элемент пути, и меняет его через change.
+// change возвращает новый контейнер: массив при вставке может переехать в другой срез.
+func modify(node interface{}, path []string, change func(container interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return change(node, path[0])
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		updated, err := modify(child, path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = updated
+		return n, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := modify(n[i], path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	}
+	return nil, ErrPathNotFound
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+	var removed interface{}
+	doc, err := modify(doc, path, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			value, ok := c[key]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			removed = value
+			delete(c, key)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, ErrPathNotFound
+	})
+	return doc, removed, err
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[key]; !ok {
+				return nil, ErrPathNotFound
+			}
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			c[k] = deepCopy(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(t))
+		for i, item := range t {
+			c[i] = deepCopy(item)
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Статусы экзамена
+const (
+	ExamStatusInProgress = "in_progress"
+	ExamStatusSubmitted  = "submitted"
+)
+
+// Exam — экзамен на время по выборке заметок из папки или тегов
+type Exam struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID           uuid.UUID      `gorm:"type:uuid;not null;index" json:"-"`
+	FolderID         *uuid.UUID     `gorm:"type:uuid" json:"folder_id,omitempty"`
+	Status           string         `gorm:"type:varchar(20);not null;default:in_progress" json:"status"`
+	ApplyToSchedule  bool           `gorm:"not null;default:false" json:"apply_to_schedule"`
+	DurationSeconds  int            `gorm:"not null" json:"duration_seconds"`
+	StartedAt        time.Time      `gorm:"not null" json:"started_at"`
+	Deadline         time.Time      `gorm:"not null" json:"deadline"`
+	SubmittedAt      *time.Time     `json:"submitted_at,omitempty"`
+	TotalQuestions   int            `gorm:"not null;default:0" json:"total_questions"`
+	CorrectAnswers   int            `gorm:"not null;default:0" json:"correct_answers"`
+	Score            float64        `gorm:"not null;default:0" json:"score"`
+	TimeSpentSeconds int            `gorm:"not null;default:0" json:"time_spent_seconds"`
+	Questions        []ExamQuestion `gorm:"foreignKey:ExamID;constraint:OnDelete:CASCADE" json:"questions"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ExamQuestion — вопрос экзамена. Порядок фиксируется при старте через Position.
+type ExamQuestion struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ExamID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	NoteID           uuid.UUID  `gorm:"type:uuid;not null" json:"note_id"`
+	Position         int        `gorm:"not null" json:"position"`
+	Title            string     `gorm:"type:varchar(255);not null" json:"title"`
+	Content          string     `gorm:"type:text" json:"content"`
+	Correct          *bool      `json:"correct"`
+	AnsweredAt       *time.Time `json:"answered_at,omitempty"`
+	TimeSpentSeconds int        `gorm:"not null;default:0" json:"time_spent_seconds"`
+}
+
+func (e *Exam) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&e.ID)(tx)
+}
+
+func (q *ExamQuestion) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&q.ID)(tx)
+}
@@ -8,6 +8,10 @@ import (
      "valibibe/internal/utils"
 )
 
+// MaxFolderNameLength — наибольшая длина имени папки в символах; та же граница,
+// что у проверки max=200 во FolderCreateInput и FolderUpdateInput (колонка в БД — TEXT)
+const MaxFolderNameLength = 200
+
 type Folder struct {
     ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
     UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
@@ -15,9 +19,29 @@ type Folder struct {
     ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
     CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
     UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+    DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+    // Version растёт при каждом изменении; обновление с устаревшей версией отклоняется
+    Version int `gorm:"not null;default:1" json:"version"`
+    // Position — ключ ручного порядка среди папок того же родителя (internal/fractional)
+    Position string `gorm:"type:varchar(255);not null;default:''" json:"position"`
+    // Path — материализованный путь "/<id корня>/…/<id папки>/" для выборки поддерева
+    Path string `gorm:"type:text;not null;default:''" json:"-"`
+    // Settings — собственные настройки обучения папки (nil — всё наследуется), JSON-объект
+    Settings *FolderSettings `gorm:"type:text;serializer:json" json:"-"`
+}
+
+// SubfolderPath возвращает путь папки id внутри папки с путём parentPath ("" — в корне)
+func SubfolderPath(parentPath string, id uuid.UUID) string {
+    if parentPath == "" {
+        parentPath = "/"
+    }
+    return parentPath + id.String() + "/"
 }
 
 func (f *Folder) BeforeCreate(tx *gorm.DB) (err error) {
+    if f.Version == 0 {
+        f.Version = 1
+    }
     return utils.SetUUIDIfNil(&f.ID)(tx)
 }
 
@@ -0,0 +1,20 @@
+package models
+
+// FolderSettings — настройки обучения папки (deck options). Незаданное (nil) поле
+// наследуется от родительской папки, у корня — от настроек планировщика по умолчанию
+type FolderSettings struct {
+	// NewPerDay и ReviewsPerDay — дневные лимиты новых карточек и повторений (0 — без ограничения)
+	NewPerDay     *int `json:"new_per_day" example:"20"`
+	ReviewsPerDay *int `json:"reviews_per_day" example:"200"`
+	// LearningSteps — шаги обучения в минутах; пустой массив отключает шаги
+	LearningSteps []int `json:"learning_steps" example:"1,10"`
+	// LevelStep и IntervalDays — рост memory_level после ответа и интервалы по диапазонам
+	LevelStep    *int  `json:"level_step" example:"20"`
+	IntervalDays []int `json:"interval_days" example:"1,3,5,10,30"`
+	// TargetRetention — желаемая доля вспоминания, масштабирует интервалы
+	TargetRetention *float64 `json:"target_retention" example:"0.9"`
+	// Algorithm — алгоритм интервалов: levels или exponential
+	Algorithm *string `json:"algorithm" example:"levels"`
+	// MaxIntervalDays — предел интервала в днях (0 — без ограничения)
+	MaxIntervalDays *int `json:"max_interval_days" example:"180"`
+}
@@ -22,8 +22,24 @@ type Note struct {
 	NextReviewAt *time.Time `json:"next_review_at, omitempty"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	// DeletedAt — время перемещения в корзину; такие заметки скрыты из обычных выборок
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	// NoteTypeID — тип заметки; пустой у встроенного типа Basic (заголовок и текст)
+	NoteTypeID *uuid.UUID `gorm:"type:uuid;index" json:"note_type_id,omitempty"`
+	// Fields — значения полей типа, хранятся как JSON-объект
+	Fields map[string]string `gorm:"type:text;serializer:json" json:"fields,omitempty"`
+	// Version растёт при каждом изменении; обновление с устаревшей версией отклоняется
+	Version int `gorm:"not null;default:1" json:"version"`
+	// Position — ключ ручного порядка среди заметок той же папки (internal/fractional);
+	// при переносе в другую папку сохраняется
+	Position string `gorm:"type:varchar(255);not null;default:''" json:"position"`
+	// DuplicateIDs — похожие заметки, найденные при создании; в базе не хранится
+	DuplicateIDs []string `gorm:"-" json:"duplicate_ids,omitempty"`
 }
 
 func (n *Note) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.Version == 0 {
+		n.Version = 1
+	}
 	return utils.SetUUIDIfNil(&n.ID)(tx)
 }
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/google/uuid"
+
+// NoteDedupKey — ключ LSH-индекса дубликатов (см. dedup.Keys). Version — версия заметки,
+// по которой посчитаны ключи: после правки заметки ключи пересчитываются
+type NoteDedupKey struct {
+	NoteID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Key     int64     `gorm:"primaryKey;autoIncrement:false;index:idx_note_dedup_keys_user_key,priority:2" json:"-"`
+	UserID  uuid.UUID `gorm:"type:uuid;not null;index:idx_note_dedup_keys_user_key,priority:1" json:"-"`
+	Version int       `gorm:"not null" json:"-"`
+}
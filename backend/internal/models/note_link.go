@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NoteLink — вики-ссылка [[...]] из текста заметки SourceID.
+// Text — цель как она написана в тексте, Position — порядковый номер ссылки в тексте;
+// TargetID пуст, если ссылка ни на что не указывает.
+type NoteLink struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	SourceID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"source_id"`
+	TargetID  *uuid.UUID `gorm:"type:uuid;index" json:"target_id,omitempty"`
+	Text      string     `gorm:"type:varchar(255);not null" json:"text"`
+	Position  int        `gorm:"not null;default:0" json:"position"`
+	Source    *Note      `gorm:"foreignKey:SourceID" json:"-"`
+	Target    *Note      `gorm:"foreignKey:TargetID" json:"-"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (l *NoteLink) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&l.ID)(tx)
+}
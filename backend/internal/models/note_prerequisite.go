@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPrerequisiteLevel — уровень памяти пререквизита, после которого зависимая заметка попадает в очередь новых
+const DefaultPrerequisiteLevel = 60
+
+// NotePrerequisite — ребро графа зависимостей: PrerequisiteID нужно выучить до NoteID
+type NotePrerequisite struct {
+	NoteID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"note_id"`
+	PrerequisiteID uuid.UUID `gorm:"type:uuid;primaryKey" json:"prerequisite_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	RequiredLevel  int       `gorm:"type:int;not null;default:60;check:required_level >= 0 AND required_level <= 100" json:"required_level"`
+	Note           *Note     `gorm:"foreignKey:NoteID" json:"note,omitempty"`
+	Prerequisite   *Note     `gorm:"foreignKey:PrerequisiteID" json:"prerequisite,omitempty"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
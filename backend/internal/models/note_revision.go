@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NoteRevision — неизменяемый снимок заголовка и текста заметки.
+// Revision — порядковый номер в пределах заметки, начиная с 1.
+type NoteRevision struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	NoteID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:ux_note_revisions_note_revision" json:"note_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Revision     int       `gorm:"not null;uniqueIndex:ux_note_revisions_note_revision" json:"revision"`
+	Title        string    `gorm:"type:varchar(255);not null" json:"title"`
+	Content      string    `gorm:"type:text" json:"content"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (r *NoteRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&r.ID)(tx)
+}
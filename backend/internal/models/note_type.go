@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NoteType — пользовательский тип заметки: набор полей и шаблоны сторон карточки.
+// Шаблоны ссылаются на поля через {{Имя поля}}.
+type NoteType struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Name   string    `gorm:"type:varchar(100);not null" json:"name"`
+	// Fields хранится как JSON-массив, порядок полей задаёт пользователь
+	Fields         []NoteTypeField `gorm:"type:text;serializer:json" json:"fields"`
+	PromptTemplate string          `gorm:"type:text;not null" json:"prompt_template"`
+	AnswerTemplate string          `gorm:"type:text;not null" json:"answer_template"`
+	// Builtin — встроенный тип Basic, в базе не хранится
+	Builtin   bool      `gorm:"-" json:"builtin"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NoteTypeField — описание поля типа заметки
+type NoteTypeField struct {
+	Name string `json:"name"`
+	// Kind — text, long_text, url или audio
+	Kind     string `json:"kind"`
+	Required bool   `json:"required"`
+	// MaxLength — ограничение длины в символах, 0 — по умолчанию для вида поля
+	MaxLength int `json:"max_length,omitempty"`
+}
+
+func (t *NoteType) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&t.ID)(tx)
+}
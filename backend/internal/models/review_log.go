@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Источники записей журнала повторений
+const (
+	ReviewSourceReview = "review"
+	ReviewSourceExam   = "exam"
+)
+
+// ReviewLog — запись журнала повторений (один ответ на карточку)
+type ReviewLog struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	NoteID      uuid.UUID `gorm:"type:uuid;not null;index" json:"note_id"`
+	Remembered  bool      `gorm:"not null" json:"remembered"`
+	LevelBefore int       `gorm:"type:int;not null" json:"level_before"`
+	LevelAfter  int       `gorm:"type:int;not null" json:"level_after"`
+	Source      string    `gorm:"type:varchar(20);not null;default:review" json:"source"`
+	ReviewedAt  time.Time `gorm:"not null" json:"reviewed_at"`
+}
+
+func (l *ReviewLog) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&l.ID)(tx)
+}
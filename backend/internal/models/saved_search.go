@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"valibibe/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedSearch — сохранённый набор фильтров заметок («умная папка»)
+type SavedSearch struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	Name     string     `gorm:"type:varchar(200);not null" json:"name"`
+	Query    string     `gorm:"type:text;not null;default:''" json:"q"`
+	Search   string     `gorm:"type:text;not null;default:''" json:"search"`
+	FolderID *uuid.UUID `gorm:"type:uuid" json:"folder_id,omitempty"`
+	// TagIDs хранится как JSON-массив
+	TagIDs    []string  `gorm:"type:text;serializer:json" json:"tag_ids"`
+	Archived  *bool     `json:"archived,omitempty"`
+	SortBy    string    `gorm:"type:varchar(20);not null;default:''" json:"sort_by"`
+	Order     string    `gorm:"type:varchar(4);not null;default:''" json:"order"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *SavedSearch) BeforeCreate(tx *gorm.DB) (err error) {
+	return utils.SetUUIDIfNil(&s.ID)(tx)
+}
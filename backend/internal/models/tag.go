@@ -10,13 +10,19 @@ import (
 )
 
 type Tag struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	Name      string    `gorm:"not null" json:"name"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	// Version растёт при каждом изменении; обновление с устаревшей версией отклоняется
+	Version int `gorm:"not null;default:1" json:"version"`
 }
 
 // BeforeCreate — хук GORM, который вызывается перед созданием записи
 func (t *Tag) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.Version == 0 {
+		t.Version = 1
+	}
 	return utils.SetUUIDIfNil(&t.ID)(tx)
 }
@@ -0,0 +1,304 @@
+// Package notequery разбирает язык запросов к заметкам:
+//
+//	tag:biology -tag:draft folder:"Chem/Organic" is:due is:archived level:<40 created:>2025-01-01 "exact phrase"
+//
+// Условия разделяются пробелами и объединяются через AND, минус перед условием его отрицает.
+// Parse строит AST, а компиляция в SQL делается в репозитории.
+package notequery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DateLayout — формат дат в условии created:
+const DateLayout = "2006-01-02"
+
+// Операторы сравнения для level: и created:
+const (
+	OpEq  = "="
+	OpLt  = "<"
+	OpLte = "<="
+	OpGt  = ">"
+	OpGte = ">="
+)
+
+// Флаги условия is:
+const (
+	FlagDue      = "due"
+	FlagArchived = "archived"
+)
+
+// Expr — узел AST. Pos — позиция начала узла в запросе (в символах, с 1).
+type Expr interface {
+	Pos() int
+}
+
+// And — все условия должны выполняться
+type And struct {
+	Terms []Expr
+}
+
+// Not — отрицание условия (-tag:draft)
+type Not struct {
+	Expr     Expr
+	Position int
+}
+
+// Tag — у заметки есть тег с таким именем (без учёта регистра)
+type Tag struct {
+	Name     string
+	Position int
+}
+
+// Folder — заметка лежит в папке по пути вида "Chem/Organic"
+type Folder struct {
+	Path     []string
+	Position int
+}
+
+// Is — флаг заметки: due (пора повторять) или archived
+type Is struct {
+	Flag     string
+	Position int
+}
+
+// Level — сравнение memory_level с числом
+type Level struct {
+	Op       string
+	Value    int
+	Position int
+}
+
+// Created — сравнение даты создания с днём (сравнение по целым дням)
+type Created struct {
+	Op       string
+	Date     time.Time
+	Position int
+}
+
+// Text — подстрока в заголовке или тексте; Phrase — значение было в кавычках
+type Text struct {
+	Value    string
+	Phrase   bool
+	Position int
+}
+
+func (e *And) Pos() int {
+	if len(e.Terms) == 0 {
+		return 1
+	}
+	return e.Terms[0].Pos()
+}
+func (e *Not) Pos() int     { return e.Position }
+func (e *Tag) Pos() int     { return e.Position }
+func (e *Folder) Pos() int  { return e.Position }
+func (e *Is) Pos() int      { return e.Position }
+func (e *Level) Pos() int   { return e.Position }
+func (e *Created) Pos() int { return e.Position }
+func (e *Text) Pos() int    { return e.Position }
+
+// SyntaxError — ошибка разбора с позицией (в символах, с 1)
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// Parse разбирает запрос. Пустой запрос даёт nil без ошибки.
+func Parse(input string) (Expr, error) {
+	p := &parser{src: []rune(input)}
+	terms := make([]Expr, 0)
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	switch len(terms) {
+	case 0:
+		return nil, nil
+	case 1:
+		return terms[0], nil
+	}
+	return &And{Terms: terms}, nil
+}
+
+type parser struct {
+	src []rune
+	pos int // индекс в src, с 0
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() rune { return p.src[p.pos] }
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Position: pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// parseTerm: ['-'] (field ':' value | value)
+func (p *parser) parseTerm() (Expr, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+		if p.eof() || unicode.IsSpace(p.peek()) {
+			return nil, p.errorf(start, "expected condition after '-'")
+		}
+		if p.peek() == '-' {
+			return nil, p.errorf(p.pos, "unexpected '-'")
+		}
+		inner, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: inner, Position: start + 1}, nil
+	}
+
+	if p.peek() == '"' {
+		value, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(value) == "" {
+			return nil, p.errorf(start, "empty phrase")
+		}
+		return &Text{Value: value, Phrase: true, Position: start + 1}, nil
+	}
+
+	word := p.parseWord()
+	name, rest, isField := strings.Cut(word, ":")
+	if !isField {
+		return &Text{Value: word, Position: start + 1}, nil
+	}
+
+	valueStart := start + len([]rune(name)) + 1
+	value := rest
+	if rest == "" && !p.eof() && p.peek() == '"' {
+		quoted, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		value = quoted
+	}
+
+	return p.parseField(strings.ToLower(name), value, start, valueStart)
+}
+
+func (p *parser) parseField(name, value string, start, valueStart int) (Expr, error) {
+	position := start + 1
+	switch name {
+	case "tag":
+		if value == "" {
+			return nil, p.errorf(valueStart, "expected tag name")
+		}
+		return &Tag{Name: value, Position: position}, nil
+
+	case "folder":
+		path := SplitPath(value)
+		if len(path) == 0 {
+			return nil, p.errorf(valueStart, "expected folder path")
+		}
+		return &Folder{Path: path, Position: position}, nil
+
+	case "is":
+		flag := strings.ToLower(value)
+		if flag != FlagDue && flag != FlagArchived {
+			return nil, p.errorf(valueStart, "unknown flag %q, expected due or archived", value)
+		}
+		return &Is{Flag: flag, Position: position}, nil
+
+	case "level":
+		op, operand := splitOperator(value)
+		n, err := strconv.Atoi(operand)
+		if err != nil {
+			return nil, p.errorf(valueStart+len(op), "expected number, got %q", operand)
+		}
+		return &Level{Op: op, Value: n, Position: position}, nil
+
+	case "created":
+		op, operand := splitOperator(value)
+		date, err := time.Parse(DateLayout, operand)
+		if err != nil {
+			return nil, p.errorf(valueStart+len(op), "expected date in YYYY-MM-DD format, got %q", operand)
+		}
+		return &Created{Op: op, Date: date, Position: position}, nil
+	}
+
+	return nil, p.errorf(start, "unknown field %q", name)
+}
+
+// parseWord читает слово до пробела; кавычки внутри значения поля (folder:"A B") обрабатывает parseTerm
+func (p *parser) parseWord() string {
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.peek()) {
+		if p.peek() == '"' && p.pos > start && p.src[p.pos-1] == ':' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// parseQuoted читает строку в кавычках; \" и \\ экранируют символы
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // открывающая кавычка
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == '\\' && p.pos+1 < len(p.src):
+			b.WriteRune(p.src[p.pos+1])
+			p.pos += 2
+		case r == '"':
+			p.pos++
+			if !p.eof() && !unicode.IsSpace(p.peek()) {
+				return "", p.errorf(p.pos, "expected space after closing quote")
+			}
+			return b.String(), nil
+		default:
+			b.WriteRune(r)
+			p.pos++
+		}
+	}
+	return "", p.errorf(start, "unterminated quote")
+}
+
+func splitOperator(value string) (string, string) {
+	for _, op := range []string{OpLte, OpGte, OpLt, OpGt, OpEq} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return OpEq, value
+}
+
+// SplitPath разбивает путь папки "A/B/C" на имена; пустые части пропускаются
+func SplitPath(path string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(path, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,214 @@
+// Package notetype проверяет описания типов заметок и значения их полей
+// и подставляет значения в шаблоны сторон карточки:
+//
+//	prompt: {{Word}}
+//	answer: {{Translation}} — {{Example}}
+//
+// Заметки без типа относятся к встроенному типу Basic: сторона вопроса — заголовок, ответа — текст.
+package notetype
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"valibibe/internal/models"
+)
+
+// Виды полей
+const (
+	KindText     = "text"
+	KindLongText = "long_text"
+	KindURL      = "url"
+	KindAudio    = "audio"
+)
+
+// Ограничения описания типа
+const (
+	MaxFields       = 20
+	MaxFieldNameLen = 50
+	// DefaultTextLength — длина однострочного поля, если MaxLength не задан
+	DefaultTextLength = 255
+)
+
+// BasicName — имя встроенного типа
+const BasicName = "Basic"
+
+// Поля встроенного типа: сторона вопроса — заголовок, ответа — текст заметки
+const (
+	BasicFront = "Front"
+	BasicBack  = "Back"
+)
+
+var kinds = map[string]bool{KindText: true, KindLongText: true, KindURL: true, KindAudio: true}
+
+var audioExtensions = map[string]bool{".mp3": true, ".ogg": true, ".oga": true, ".opus": true, ".wav": true, ".m4a": true, ".webm": true, ".flac": true}
+
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// Basic возвращает описание встроенного типа
+func Basic() *models.NoteType {
+	return &models.NoteType{
+		Name: BasicName,
+		Fields: []models.NoteTypeField{
+			{Name: BasicFront, Kind: KindText, Required: true, MaxLength: DefaultTextLength},
+			{Name: BasicBack, Kind: KindLongText, Required: true},
+		},
+		PromptTemplate: "{{" + BasicFront + "}}",
+		AnswerTemplate: "{{" + BasicBack + "}}",
+		Builtin:        true,
+	}
+}
+
+// BasicValues — значения полей Basic для заметки без типа
+func BasicValues(title, content string) map[string]string {
+	return map[string]string{BasicFront: title, BasicBack: content}
+}
+
+// ValidateDefinition проверяет поля и шаблоны типа: имена полей уникальны без учёта регистра,
+// шаблоны ссылаются только на существующие поля, шаблон вопроса не пуст
+func ValidateDefinition(fields []models.NoteTypeField, prompt, answer string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+	if len(fields) > MaxFields {
+		return fmt.Errorf("too many fields, max %d", MaxFields)
+	}
+
+	names := make(map[string]bool, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if strings.TrimSpace(field.Name) == "" {
+			return fmt.Errorf("field name is required")
+		}
+		if field.Name != strings.TrimSpace(field.Name) || strings.ContainsAny(field.Name, "{}") {
+			return fmt.Errorf("field name %q must not contain braces or surrounding spaces", field.Name)
+		}
+		if utf8.RuneCountInString(field.Name) > MaxFieldNameLen {
+			return fmt.Errorf("field name %q is longer than %d characters", field.Name, MaxFieldNameLen)
+		}
+		key := strings.ToLower(field.Name)
+		if seen[key] {
+			return fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[key] = true
+		names[field.Name] = true
+
+		if !kinds[field.Kind] {
+			return fmt.Errorf("field %q: unknown kind %q, expected text, long_text, url or audio", field.Name, field.Kind)
+		}
+		if field.MaxLength < 0 {
+			return fmt.Errorf("field %q: max_length must not be negative", field.Name)
+		}
+	}
+
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("prompt_template is required")
+	}
+	for _, template := range []string{prompt, answer} {
+		for _, name := range Placeholders(template) {
+			if !names[name] {
+				return fmt.Errorf("template refers to unknown field %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultTemplates — шаблоны по умолчанию: вопрос — первое поле, ответ — остальные по строкам
+func DefaultTemplates(fields []models.NoteTypeField) (string, string) {
+	if len(fields) == 0 {
+		return "", ""
+	}
+	answer := make([]string, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		answer = append(answer, "{{"+field.Name+"}}")
+	}
+	return "{{" + fields[0].Name + "}}", strings.Join(answer, "\n")
+}
+
+// ValidateValues проверяет значения полей заметки по описанию типа
+func ValidateValues(fields []models.NoteTypeField, values map[string]string) error {
+	known := make(map[string]models.NoteTypeField, len(fields))
+	for _, field := range fields {
+		known[field.Name] = field
+	}
+	for name := range values {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+	}
+
+	for _, field := range fields {
+		value := values[field.Name]
+		if strings.TrimSpace(value) == "" {
+			if field.Required {
+				return fmt.Errorf("field %q is required", field.Name)
+			}
+			continue
+		}
+		if err := validateValue(field, value); err != nil {
+			return fmt.Errorf("field %q: %s", field.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func validateValue(field models.NoteTypeField, value string) error {
+	maxLength := field.MaxLength
+	if maxLength == 0 && field.Kind != KindLongText {
+		maxLength = DefaultTextLength
+	}
+	if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+		return fmt.Errorf("longer than %d characters", maxLength)
+	}
+
+	switch field.Kind {
+	case KindText:
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("must be a single line")
+		}
+	case KindURL, KindAudio:
+		u, err := url.ParseRequestURI(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("must be an absolute http or https URL")
+		}
+		if field.Kind == KindAudio && !audioExtensions[strings.ToLower(path.Ext(u.Path))] {
+			return fmt.Errorf("must point to an audio file")
+		}
+	}
+	return nil
+}
+
+// Placeholders возвращает имена полей, на которые ссылается шаблон
+func Placeholders(template string) []string {
+	matches := placeholderPattern.FindAllStringSubmatch(template, -1)
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, strings.TrimSpace(m[1]))
+	}
+	return names
+}
+
+// Render подставляет значения полей в шаблон; отсутствующее поле даёт пустую строку
+func Render(template string, values map[string]string) string {
+	rendered := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return values[strings.TrimSpace(placeholder[2:len(placeholder)-2])]
+	})
+	return strings.TrimSpace(rendered)
+}
+
+// Content собирает текст заметки из непустых значений полей в порядке описания,
+// чтобы поиск и вики-ссылки работали и для типизированных заметок
+func Content(fields []models.NoteTypeField, values map[string]string) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if value := strings.TrimSpace(values[field.Name]); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
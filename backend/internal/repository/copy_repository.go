@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/utils"
+)
+
+type copyRepository struct {
+	db *gorm.DB
+}
+
+func NewCopyRepository(db *gorm.DB) interfaces.CopyRepository {
+	return &copyRepository{db: db}
+}
+
+func (r *copyRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, r.db, fn)
+}
+
+func (r *copyRepository) CopyNote(ctx context.Context, sourceID uuid.UUID, clone *models.Note) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if clone.Position == "" {
+			position, err := nextPosition(tx, &models.Note{}, clone.UserID, "folder_id", clone.FolderID)
+			if err != nil {
+				return err
+			}
+			clone.Position = position
+		}
+		if err := tx.Omit("Tags", "Folder").Create(clone).Error; err != nil {
+			return err
+		}
+		if err := copyNoteTags(tx, sourceID, clone.ID); err != nil {
+			return err
+		}
+		return tx.Model(clone).Association("Tags").Find(&clone.Tags)
+	})
+}
+
+func (r *copyRepository) CopyFolder(ctx context.Context, userID, folderID uuid.UUID, targetID *uuid.UUID, resetScheduling bool) (*interfaces.FolderCopy, error) {
+	result := &interfaces.FolderCopy{}
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Поддерево папки: родители идут раньше детей
+		var folders []models.Folder
+		if err := tx.Raw(`
+			WITH RECURSIVE subfolders AS (
+				SELECT id, 0 AS depth FROM folders
+				WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+				UNION ALL
+				SELECT f.id, sf.depth + 1 FROM folders f
+				INNER JOIN subfolders sf ON sf.id = f.parent_id
+				WHERE f.deleted_at IS NULL
+			)
+			SELECT folders.* FROM folders
+			INNER JOIN subfolders ON subfolders.id = folders.id
+			ORDER BY subfolders.depth, folders.name
+		`, folderID, userID).Scan(&folders).Error; err != nil {
+			return err
+		}
+		if len(folders) == 0 {
+			return apperrors.ErrNotFound
+		}
+
+		ids := make([]uuid.UUID, len(folders))
+		for i, f := range folders {
+			ids[i] = f.ID
+		}
+		if targetID != nil {
+			for _, id := range ids {
+				if id == *targetID {
+					return fmt.Errorf("%w: cannot copy a folder into itself", apperrors.ErrInvalidInput)
+				}
+			}
+		}
+
+		name, err := copyFolderName(tx, userID, targetID, folders[0].Name)
+		if err != nil {
+			return err
+		}
+
+		// Копия встаёт последней в target, вложенные папки и заметки сохраняют свой порядок
+		rootPosition, err := nextPosition(tx, &models.Folder{}, userID, "parent_id", targetID)
+		if err != nil {
+			return err
+		}
+		var targetPath string
+		if targetID != nil {
+			var paths []string
+			if err := tx.Model(&models.Folder{}).Where("id = ?", *targetID).Pluck("path", &paths).Error; err != nil {
+				return err
+			}
+			if len(paths) > 0 {
+				targetPath = paths[0]
+			}
+		}
+
+		mapping := make(map[uuid.UUID]uuid.UUID, len(folders))
+		paths := make(map[uuid.UUID]string, len(folders))
+		for i, f := range folders {
+			clone := models.Folder{ID: uuid.New(), UserID: userID, Name: f.Name, ParentID: targetID, Position: f.Position, Settings: f.Settings}
+			if i == 0 {
+				clone.Name = name
+				clone.Position = rootPosition
+				clone.Path = models.SubfolderPath(targetPath, clone.ID)
+			} else {
+				parentID := mapping[*f.ParentID]
+				clone.ParentID = &parentID
+				clone.Path = models.SubfolderPath(paths[parentID], clone.ID)
+			}
+			paths[clone.ID] = clone.Path
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+			mapping[f.ID] = clone.ID
+			if i == 0 {
+				result.Root = &clone
+			}
+		}
+		result.Folders = len(folders)
+
+		var notes []models.Note
+		if err := tx.Where("user_id = ? AND folder_id IN ?", userID, ids).
+			Order("created_at ASC, id ASC").
+			Find(&notes).Error; err != nil {
+			return err
+		}
+		result.Notes = make([]models.Note, len(notes))
+		for i, n := range notes {
+			folderID := mapping[*n.FolderID]
+			clone := models.Note{
+				UserID:       userID,
+				Title:        n.Title,
+				Content:      n.Content,
+				FolderID:     &folderID,
+				MemoryLevel:  n.MemoryLevel,
+				Archived:     n.Archived,
+				NextReviewAt: n.NextReviewAt,
+				NoteTypeID:   n.NoteTypeID,
+				Fields:       n.Fields,
+				Position:     n.Position,
+			}
+			if resetScheduling {
+				clone.MemoryLevel = 0
+				clone.NextReviewAt = nil
+			}
+			if err := tx.Omit("Tags", "Folder").Create(&clone).Error; err != nil {
+				return err
+			}
+			if err := copyNoteTags(tx, n.ID, clone.ID); err != nil {
+				return err
+			}
+			result.Notes[i] = clone
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// copyNoteTags связывает заметку copyID с теми же тегами, что и sourceID
+func copyNoteTags(tx *gorm.DB, sourceID, copyID uuid.UUID) error {
+	return tx.Exec(`
+		INSERT INTO note_tags (note_id, tag_id)
+		SELECT ?, tag_id FROM note_tags WHERE note_id = ?
+	`, copyID, sourceID).Error
+}
+
+// copyFolderName подбирает имя копии, свободное среди папок parentID:
+// само имя, затем "имя (copy)", "имя (copy 2)" и т. д.
+func copyFolderName(tx *gorm.DB, userID uuid.UUID, parentID *uuid.UUID, name string) (string, error) {
+	query := tx.Model(&models.Folder{}).Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var names []string
+	if err := query.Pluck("name", &names).Error; err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(names))
+	for _, n := range names {
+		taken[n] = true
+	}
+
+	candidate := name
+	for i := 1; taken[candidate]; i++ {
+		suffix := " (copy)"
+		if i > 1 {
+			suffix = fmt.Sprintf(" (copy %d)", i)
+		}
+		candidate = utils.TruncateRunes(name, models.MaxFolderNameLength-utf8.RuneCountInString(suffix)) + suffix
+	}
+	return candidate, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type examRepository struct {
+	db *gorm.DB
+}
+
+func NewExamRepository(db *gorm.DB) interfaces.ExamRepository {
+	return &examRepository{db: db}
+}
+
+// Create сохраняет экзамен вместе с вопросами (GORM пишет ассоциации в одной транзакции)
+func (r *examRepository) Create(ctx context.Context, exam *models.Exam) error {
+	return dbFor(ctx, r.db).Create(exam).Error
+}
+
+func (r *examRepository) GetByID(ctx context.Context, userID, examID uuid.UUID) (*models.Exam, error) {
+	var exam models.Exam
+	err := dbFor(ctx, r.db).
+		Where("id = ? AND user_id = ?", examID, userID).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		First(&exam).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &exam, err
+}
+
+func (r *examRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Exam, error) {
+	var exams []models.Exam
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Order("created_at DESC").
+		Find(&exams).Error
+	return exams, err
+}
+
+// UpdateQuestion сохраняет ответ только если вопрос ещё не был отвечен, а экзамен не завершён:
+// ответ, пришедший параллельно с сабмитом, не должен изменить уже подсчитанный результат
+func (r *examRepository) UpdateQuestion(ctx context.Context, question *models.ExamQuestion) error {
+	inProgress := dbFor(ctx, r.db).
+		Model(&models.Exam{}).
+		Select("id").
+		Where("id = ? AND status = ?", question.ExamID, models.ExamStatusInProgress)
+	result := dbFor(ctx, r.db).
+		Model(&models.ExamQuestion{}).
+		Where("id = ? AND answered_at IS NULL AND exam_id IN (?)", question.ID, inProgress).
+		Updates(map[string]interface{}{
+			"correct":            question.Correct,
+			"answered_at":        question.AnsweredAt,
+			"time_spent_seconds": question.TimeSpentSeconds,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var open int64
+		if err := inProgress.Count(&open).Error; err != nil {
+			return err
+		}
+		if open == 0 {
+			return apperrors.ErrExamSubmitted
+		}
+		return apperrors.ErrQuestionAnswered
+	}
+	return nil
+}
+
+// Update сохраняет итоговые поля экзамена (без вопросов). Условие на статус не даёт
+// двум параллельным запросам завершить экзамен дважды: второй получит apperrors.ErrExamSubmitted
+func (r *examRepository) Update(ctx context.Context, exam *models.Exam) error {
+	result := dbFor(ctx, r.db).
+		Model(&models.Exam{}).
+		Where("id = ? AND user_id = ? AND status = ?", exam.ID, exam.UserID, models.ExamStatusInProgress).
+		Updates(map[string]interface{}{
+			"status":             exam.Status,
+			"submitted_at":       exam.SubmittedAt,
+			"total_questions":    exam.TotalQuestions,
+			"correct_answers":    exam.CorrectAnswers,
+			"score":              exam.Score,
+			"time_spent_seconds": exam.TimeSpentSeconds,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrExamSubmitted
+	}
+	return nil
+}
+
+func (r *examRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, r.db, fn)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"valibibe/internal/models"
+)
+
+// folderSubtree — подзапрос ID папки folderID и всех её подпапок по материализованному пути.
+// Путь читается заранее и передаётся в LIKE литеральным префиксом: колонка path в collation "C",
+// и такой шаблон PostgreSQL ищет по индексу idx_folders_user_path, а шаблон из подзапроса — перебором.
+// Если папки нет или путь пуст, остаётся только сама folderID, а не шаблон '%' на все папки.
+func folderSubtree(db *gorm.DB, userID, folderID string) (*gorm.DB, error) {
+	var paths []string
+	if err := db.Model(&models.Folder{}).
+		Where("id = ? AND user_id = ?", folderID, userID).
+		Pluck("path", &paths).Error; err != nil {
+		return nil, err
+	}
+
+	subtree := db.Model(&models.Folder{}).Select("folders.id")
+	if len(paths) == 0 || paths[0] == "" {
+		return subtree.Where("folders.user_id = ? AND folders.id = ?", userID, folderID), nil
+	}
+	return subtree.Where("folders.user_id = ? AND folders.path LIKE ?", userID, paths[0]+"%"), nil
+}
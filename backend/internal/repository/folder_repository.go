@@ -3,8 +3,15 @@ package repository
 import (
     "context"
     "errors"
+    "sort"
+    "strings"
+    "time"
 
+    "github.com/google/uuid"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "valibibe/internal/controller/dto"
+    "valibibe/internal/fractional"
     "valibibe/internal/models"
 	"valibibe/internal/repository/interfaces"
 )
@@ -17,16 +24,28 @@ func NewFolderRepo(db *gorm.DB) interfaces.FolderRepository {
     return &folderRepo{db: db}
 }
 
-// Create new folder
+func (r *folderRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    return withinTransaction(ctx, r.db, fn)
+}
+
+// Create new folder: без заданной позиции папка встаёт последней среди соседей
 func (r *folderRepo) Create(ctx context.Context, folder *models.Folder) error {
-    return r.db.WithContext(ctx).Create(folder).Error
+    db := dbFor(ctx, r.db)
+    if folder.Position == "" {
+        position, err := nextPosition(db, &models.Folder{}, folder.UserID, "parent_id", folder.ParentID)
+        if err != nil {
+            return err
+        }
+        folder.Position = position
+    }
+    return db.Create(folder).Error
 }
 
 // Get folder by ID (with user check)
 func (r *folderRepo) GetByID(ctx context.Context, userID, id string) (*models.Folder, error) {
     var folder models.Folder
 
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
            Where("id = ? AND user_id = ?", id, userID).
            First(&folder).Error
 
@@ -43,8 +62,9 @@ func (r *folderRepo) GetByID(ctx context.Context, userID, id string) (*models.Fo
 // List all folders of user
 func (r *folderRepo) ListByUser(ctx context.Context, userID string) ([]models.Folder, error) {
 	var folders []models.Folder
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("user_id = ?", userID).
+		Order("position ASC, name ASC, id ASC").
 		Find(&folders).Error
 	if err != nil {
 		return nil, err
@@ -52,23 +72,327 @@ func (r *folderRepo) ListByUser(ctx context.Context, userID string) ([]models.Fo
 	return folders, nil
 }
 
-// Update folder
+func (r *folderRepo) ListByIDs(ctx context.Context, userID string, ids []uuid.UUID) ([]models.Folder, error) {
+	var folders []models.Folder
+	if len(ids) == 0 {
+		return folders, nil
+	}
+	if err := dbFor(ctx, r.db).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&folders).Error; err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+func (r *folderRepo) GetByName(ctx context.Context, userID string, parentID *uuid.UUID, name string) (*models.Folder, error) {
+	query := dbFor(ctx, r.db).Where("user_id = ? AND name = ?", userID, name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var folder models.Folder
+	if err := query.First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (r *folderRepo) NoteStats(ctx context.Context, userID string, now time.Time) ([]interfaces.FolderNoteStats, error) {
+	var stats []interfaces.FolderNoteStats
+	err := dbFor(ctx, r.db).
+		Model(&models.Note{}).
+		Select(`folder_id,
+			COUNT(*) AS total,
+			SUM(CASE WHEN next_review_at IS NOT NULL AND next_review_at <= ? THEN 1 ELSE 0 END) AS due,
+			SUM(CASE WHEN next_review_at IS NULL THEN 1 ELSE 0 END) AS new_count,
+			SUM(memory_level) AS memory_sum`, now).
+		Where("user_id = ? AND archived = ? AND folder_id IS NOT NULL", userID, false).
+		Group("folder_id").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListSiblings возвращает папки родителя parentID (nil — корневые) в ручном порядке
+func (r *folderRepo) ListSiblings(ctx context.Context, userID string, parentID *uuid.UUID) ([]models.Folder, error) {
+	query := dbFor(ctx, r.db).Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var folders []models.Folder
+	if err := query.Order("position ASC, name ASC, id ASC").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+func (r *folderRepo) NameTaken(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	query := dbFor(ctx, r.db).Model(&models.Folder{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *folderRepo) Move(ctx context.Context, folder *models.Folder, positions map[uuid.UUID]string) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var oldPaths []string
+		if err := tx.Model(&models.Folder{}).Where("id = ?", folder.ID).Pluck("path", &oldPaths).Error; err != nil {
+			return err
+		}
+		if err := updateVersioned(tx, folder, &folder.Version); err != nil {
+			return err
+		}
+
+		// Пути потомков (в том числе удалённых в корзину) получают новый префикс
+		if len(oldPaths) == 1 && oldPaths[0] != "" && oldPaths[0] != folder.Path {
+			oldPath := oldPaths[0]
+			if err := tx.Unscoped().Model(&models.Folder{}).
+				Where("user_id = ? AND path LIKE ? AND id <> ?", folder.UserID, oldPath+"%", folder.ID).
+				Update("path", gorm.Expr("? || SUBSTR(path, ?)", folder.Path, len(oldPath)+1)).Error; err != nil {
+				return err
+			}
+		}
+		return setPositions(tx, &models.Folder{}, folder.UserID, positions)
+	})
+}
+
+// LockForMove: строки блокируются в порядке id, чтобы встречные переносы не ждали друг друга по кругу.
+// В SQLite блокировок строк нет — там запись и так идёт одной транзакцией за раз
+func (r *folderRepo) LockForMove(ctx context.Context, folder *models.Folder, parentID *uuid.UUID) error {
+    db := dbFor(ctx, r.db)
+    ids := []string{folder.ID.String()}
+    if parentID != nil {
+        var paths []string
+        if err := db.Model(&models.Folder{}).
+            Where("id = ? AND user_id = ?", *parentID, folder.UserID).
+            Pluck("path", &paths).Error; err != nil {
+            return err
+        }
+        for _, path := range paths {
+            for _, id := range strings.Split(strings.Trim(path, "/"), "/") {
+                if id != "" {
+                    ids = append(ids, id)
+                }
+            }
+        }
+    }
+    sort.Strings(ids)
+
+    var locked []uuid.UUID
+    return db.Model(&models.Folder{}).
+        Clauses(clause.Locking{Strength: "UPDATE"}).
+        Where("user_id = ? AND id IN ?", folder.UserID, ids).
+        Order("id").
+        Pluck("id", &locked).Error
+}
+
+// LockChildren: блокируется строка родителя, а для корня — строка пользователя,
+// потому что у корневых папок общей родительской строки нет
+func (r *folderRepo) LockChildren(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID) error {
+    query := dbFor(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"})
+    if parentID == nil {
+        query = query.Model(&models.User{}).Where("id = ?", userID)
+    } else {
+        query = query.Model(&models.Folder{}).Where("id = ? AND user_id = ?", *parentID, userID)
+    }
+    var locked []uuid.UUID
+    return query.Pluck("id", &locked).Error
+}
+
+// Update folder: сохраняет папку и увеличивает версию; устаревшая версия — apperrors.ErrVersionConflict
 func (r *folderRepo) Update(ctx context.Context, folder *models.Folder) error {
-	return r.db.WithContext(ctx).Save(folder).Error
+	return updateVersioned(dbFor(ctx, r.db), folder, &folder.Version)
+}
+
+// Contents: подпапки в порядке путей (родитель раньше потомков), заметки в ручном порядке
+func (r *folderRepo) Contents(ctx context.Context, folder *models.Folder) (*interfaces.FolderContents, error) {
+	db := dbFor(ctx, r.db)
+	ids, err := subtreeFolderIDs(db, folder.UserID, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &interfaces.FolderContents{}
+	if len(ids) == 0 {
+		return contents, nil
+	}
+	if err := db.Where("id IN ? AND id <> ?", ids, folder.ID).
+		Order("path ASC, position ASC, id ASC").
+		Find(&contents.Folders).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Note{}).
+		Select("id, folder_id").
+		Where("user_id = ? AND folder_id IN ?", folder.UserID, ids).
+		Order("position ASC, created_at ASC, id ASC").
+		Find(&contents.Notes).Error; err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// Delete folder: папка уходит в корзину, а её содержимое — по режиму mode:
+//   - cascade: все вложенные папки и их заметки уходят в корзину с тем же deleted_at,
+//     чтобы их можно было восстановить одной операцией;
+//   - move_to_parent: прямые подпапки (с поддеревьями) и заметки встают в конец родителя;
+//   - unfile: вложенные папки уходят в корзину, заметки поддерева — в конец списка без папки.
+func (r *folderRepo) Delete(ctx context.Context, folder *models.Folder, mode string) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		ids, err := subtreeFolderIDs(tx, folder.UserID, folder.ID)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		now := time.Now().Truncate(time.Microsecond)
+		switch mode {
+		case dto.FolderDeleteMoveToParent:
+			if err := liftSubfolders(tx, folder); err != nil {
+				return err
+			}
+			if err := refileNotes(tx, folder.UserID, []string{folder.ID.String()}, folder.ParentID); err != nil {
+				return err
+			}
+			ids = []string{folder.ID.String()}
+		case dto.FolderDeleteUnfile:
+			if err := refileNotes(tx, folder.UserID, ids, nil); err != nil {
+				return err
+			}
+		default:
+			if err := tx.Model(&models.Note{}).
+				Where("folder_id IN ?", ids).
+				Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Folder{}).
+			Where("id IN ?", ids).
+			Update("deleted_at", now).Error
+	})
+}
+
+// subtreeFolderIDs возвращает ID папки и всех её вложенных папок, не удалённых в корзину
+func subtreeFolderIDs(tx *gorm.DB, userID, id uuid.UUID) ([]string, error) {
+	var ids []string
+	err := tx.Raw(`
+		WITH RECURSIVE subfolders AS (
+			SELECT id FROM folders
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT f.id FROM folders f
+			INNER JOIN subfolders sf ON sf.id = f.parent_id
+			WHERE f.deleted_at IS NULL
+		)
+		SELECT id FROM subfolders
+	`, id, userID).Scan(&ids).Error
+	return ids, err
+}
+
+// liftSubfolders переносит прямые подпапки folder к её родителю: они встают последними
+// в прежнем порядке, а пути их поддеревьев теряют сегмент folder
+func liftSubfolders(tx *gorm.DB, folder *models.Folder) error {
+	var children []models.Folder
+	if err := tx.Where("user_id = ? AND parent_id = ?", folder.UserID, folder.ID).
+		Order("position ASC, name ASC, id ASC").
+		Find(&children).Error; err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	last, err := lastPosition(tx, &models.Folder{}, folder.UserID, "parent_id", folder.ParentID)
+	if err != nil {
+		return err
+	}
+	keys, err := fractional.Sequence(last, "", len(children))
+	if err != nil {
+		return err
+	}
+	for i, child := range children {
+		if err := tx.Model(&models.Folder{}).
+			Where("id = ?", child.ID).
+			Updates(map[string]interface{}{
+				"parent_id": folder.ParentID,
+				"position":  keys[i],
+				"version":   gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	if folder.Path == "" {
+		return nil
+	}
+	parentPath := strings.TrimSuffix(folder.Path, folder.ID.String()+"/")
+	return tx.Unscoped().Model(&models.Folder{}).
+		Where("user_id = ? AND path LIKE ? AND id <> ?", folder.UserID, folder.Path+"%", folder.ID).
+		Update("path", gorm.Expr("? || SUBSTR(path, ?)", parentPath, len(folder.Path)+1)).Error
 }
 
-// Delete folder (will cascade delete children + notes)
-func (r *folderRepo) Delete(ctx context.Context, userID, id string) error {
-	// доп. защита: удаляем только если принадлежит юзеру
-	return r.db.WithContext(ctx).
-		Where("id = ? AND user_id = ?", id, userID).
-		Delete(&models.Folder{}).Error
+// refileNotes переносит заметки папок folderIDs в папку target (nil — без папки):
+// они встают последними в прежнем порядке, версии растут
+func refileNotes(tx *gorm.DB, userID uuid.UUID, folderIDs []string, target *uuid.UUID) error {
+	var ids []uuid.UUID
+	if err := tx.Model(&models.Note{}).
+		Where("user_id = ? AND folder_id IN ?", userID, folderIDs).
+		Order("position ASC, created_at ASC, id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	last, err := lastPosition(tx, &models.Note{}, userID, "folder_id", target)
+	if err != nil {
+		return err
+	}
+	keys, err := fractional.Sequence(last, "", len(ids))
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if err := tx.Model(&models.Note{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"folder_id": target,
+				"position":  keys[i],
+				"version":   gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Check if candidateID is descendant of ancestorID
-// Используем рекурсивный CTE (только для PostgreSQL).
+// Check if candidateID is descendant of ancestorID (или совпадает с ним).
+// Рекурсивный CTE работает и в PostgreSQL, и в SQLite; UNION отбрасывает повторы,
+// поэтому запрос завершается даже на дереве, где цикл уже есть.
 func (r *folderRepo) IsDescendant(ctx context.Context, userID, ancestorID, candidateID string) (bool, error) {
-	var exists bool
+	var count int64
 	query := `
 		WITH RECURSIVE subfolders AS (
 			SELECT id, parent_id
@@ -80,15 +404,13 @@ func (r *folderRepo) IsDescendant(ctx context.Context, userID, ancestorID, candi
 			INNER JOIN subfolders sf ON sf.id = f.parent_id
 			WHERE f.user_id = ?
 		)
-		SELECT EXISTS (
-			SELECT 1 FROM subfolders WHERE id = ?
-		)
+		SELECT COUNT(*) FROM subfolders WHERE id = ?
 	`
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Raw(query, ancestorID, userID, userID, candidateID).
-		Scan(&exists).Error
+		Scan(&count).Error
 	if err != nil {
 		return false, err
 	}
-	return exists, nil
+	return count > 0, nil
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+// FolderCopy — результат копирования папки: корень копии и все скопированные заметки
+type FolderCopy struct {
+	Root    *models.Folder
+	Folders int
+	Notes   []models.Note
+}
+
+// CopyRepository — копирование заметок и поддеревьев папок
+type CopyRepository interface {
+	Transactor
+	// CopyNote сохраняет копию заметки sourceID вместе со связями с тегами в одной транзакции
+	CopyNote(ctx context.Context, sourceID uuid.UUID, clone *models.Note) error
+	// CopyFolder в одной транзакции копирует папку folderID со всеми подпапками, заметками
+	// и их тегами в targetID (nil — в корень). Если в целевой папке уже есть папка с таким именем,
+	// к имени копии добавляется " (copy)", " (copy 2)" и т. д.
+	// При resetScheduling копии заметок становятся новыми: уровень памяти 0, без даты повторения.
+	CopyFolder(ctx context.Context, userID, folderID uuid.UUID, targetID *uuid.UUID, resetScheduling bool) (*FolderCopy, error)
+}
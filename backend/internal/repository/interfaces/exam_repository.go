@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type ExamRepository interface {
+	Transactor
+	Create(ctx context.Context, exam *models.Exam) error
+	GetByID(ctx context.Context, userID, examID uuid.UUID) (*models.Exam, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Exam, error)
+	UpdateQuestion(ctx context.Context, question *models.ExamQuestion) error
+	// Update завершает экзамен; если он уже не в статусе in_progress — apperrors.ErrExamSubmitted
+	Update(ctx context.Context, exam *models.Exam) error
+}
@@ -2,15 +2,56 @@ package interfaces
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"valibibe/internal/models"
 )
 
+// FolderNoteStats — агрегаты по заметкам одной папки (без архивных)
+type FolderNoteStats struct {
+	FolderID  uuid.UUID
+	Total     int
+	Due       int
+	New       int `gorm:"column:new_count"`
+	MemorySum int
+}
+
+// FolderContents — содержимое поддерева папки без неё самой
+type FolderContents struct {
+	Folders []models.Folder
+	// Notes — заметки поддерева, заполнены только ID и FolderID
+	Notes []models.Note
+}
+
 type FolderRepository interface {
+	Transactor
 	Create(ctx context.Context, folder *models.Folder) error
 	GetByID(ctx context.Context, userID, id string) (*models.Folder, error)
 	ListByUser(ctx context.Context, userID string) ([]models.Folder, error)
+	// ListByIDs возвращает папки пользователя из ids в произвольном порядке
+	ListByIDs(ctx context.Context, userID string, ids []uuid.UUID) ([]models.Folder, error)
+	// GetByName ищет папку name у родителя parentID (nil — корень); (nil, nil), если её нет
+	GetByName(ctx context.Context, userID string, parentID *uuid.UUID, name string) (*models.Folder, error)
+	// NoteStats считает заметки по папкам пользователя одним запросом; due — к моменту now
+	NoteStats(ctx context.Context, userID string, now time.Time) ([]FolderNoteStats, error)
+	ListSiblings(ctx context.Context, userID string, parentID *uuid.UUID) ([]models.Folder, error)
+	// NameTaken — есть ли у родителя parentID (nil — корень) другая папка с именем name
+	NameTaken(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, name string, excludeID uuid.UUID) (bool, error)
+	// Move сохраняет папку (как Update), переносит пути её подпапок под новый folder.Path
+	// и записывает новые позиции соседей — одной транзакцией
+	Move(ctx context.Context, folder *models.Folder, positions map[uuid.UUID]string) error
+	// LockForMove блокирует до конца транзакции строки папки и нового родителя parentID
+	// со всеми его предками (по материализованному пути)
+	LockForMove(ctx context.Context, folder *models.Folder, parentID *uuid.UUID) error
+	// LockChildren блокирует до конца транзакции создание подпапок у parentID (nil — корень),
+	// чтобы поиск по имени и создание шли без гонки с параллельными запросами
+	LockChildren(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID) error
 	Update(ctx context.Context, folder *models.Folder) error
-	Delete(ctx context.Context, userID, id string) error
+	// Contents возвращает все вложенные папки и заметки поддерева папки
+	Contents(ctx context.Context, folder *models.Folder) (*FolderContents, error)
+	// Delete уносит папку в корзину; mode (dto.FolderDelete*) задаёт судьбу её содержимого
+	Delete(ctx context.Context, folder *models.Folder, mode string) error
 	IsDescendant(ctx context.Context, userID, ancestorID, candidateID string) (bool, error)
 }
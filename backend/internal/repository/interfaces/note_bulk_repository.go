@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+// BulkOperation — действие над группой заметок (dto.Bulk*)
+type BulkOperation struct {
+	Action  string
+	NoteIDs []uuid.UUID
+	// FolderID — папка для move (nil — без папки)
+	FolderID *uuid.UUID
+	// TagIDs — теги для действий с тегами
+	TagIDs []uuid.UUID
+}
+
+// NoteBulkRepository — массовые операции над заметками
+type NoteBulkRepository interface {
+	Transactor
+	// ListByIDs возвращает заметки пользователя с тегами и блокирует их строки до конца транзакции
+	ListByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]models.Note, error)
+	// Apply в одной транзакции проверяет, что все заметки принадлежат пользователю
+	// (иначе apperrors.ErrNotFound и ничего не меняется), применяет действие и увеличивает их версии.
+	// Удаление сюда не входит: оно идёт через NoteService.DeleteNote
+	Apply(ctx context.Context, userID uuid.UUID, op *BulkOperation) error
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+// NoteDuplicateRepository — выборки для поиска дубликатов и слияние заметок
+type NoteDuplicateRepository interface {
+	// ListCandidates возвращает заметки пользователя (без корзины) от старых к новым;
+	// заполнены только поля, нужные для сравнения и отчёта
+	ListCandidates(ctx context.Context, userID uuid.UUID) ([]models.Note, error)
+	// ListStale возвращает заметки пользователя, ключи которых в note_dedup_keys
+	// не посчитаны или посчитаны по старой версии (заполнены id, user_id, title, content, version)
+	ListStale(ctx context.Context, userID uuid.UUID) ([]models.Note, error)
+	// ReplaceKeys заменяет ключи заметок noteIDs на keys
+	ReplaceKeys(ctx context.Context, noteIDs []uuid.UUID, keys []models.NoteDedupKey) error
+	// ListByKeys возвращает заметки пользователя (без корзины), у которых совпал хотя бы один
+	// актуальный ключ; поля — как у ListCandidates
+	ListByKeys(ctx context.Context, userID uuid.UUID, keys []int64) ([]models.Note, error)
+	// ListByIDs возвращает заметки пользователя с тегами и папкой
+	ListByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]models.Note, error)
+	// Merge в одной транзакции переносит в target теги, журнал повторений и входящие ссылки
+	// заметок sourceIDs, сохраняет расписание target и отправляет sourceIDs в корзину
+	Merge(ctx context.Context, target *models.Note, sourceIDs []uuid.UUID) error
+}
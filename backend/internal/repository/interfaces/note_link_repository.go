@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type NoteLinkRepository interface {
+	// ReplaceForNote заменяет все исходящие ссылки заметки
+	ReplaceForNote(ctx context.Context, sourceID uuid.UUID, links []models.NoteLink) error
+	// ListBySource возвращает исходящие ссылки с целями (удалённая цель не подгружается)
+	ListBySource(ctx context.Context, userID, sourceID uuid.UUID) ([]models.NoteLink, error)
+	// ListByTarget возвращает входящие ссылки из неудалённых заметок
+	ListByTarget(ctx context.Context, userID, targetID uuid.UUID) ([]models.NoteLink, error)
+	// ListResolvedByUser возвращает все ссылки пользователя, у которых есть цель
+	ListResolvedByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error)
+	// ListDangling возвращает ссылки без цели или на удалённые заметки
+	ListDangling(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error)
+	// FindNoteIDByTitle ищет самую раннюю заметку с заголовком (без учёта регистра)
+	FindNoteIDByTitle(ctx context.Context, userID uuid.UUID, title string) (*uuid.UUID, error)
+	// ResolveDangling привязывает висячие ссылки с текстом title к заметке
+	ResolveDangling(ctx context.Context, userID uuid.UUID, title string, noteID uuid.UUID) error
+}
@@ -16,12 +16,22 @@ type NoteTag struct {
 }
 
 type NoteRepository interface {
+    Transactor
     CreateNote(ctx context.Context, note *models.Note) error
+    // ListSiblings возвращает заметки папки (nil — без папки) в ручном порядке
+    ListSiblings(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID) ([]models.Note, error)
+    SetPositions(ctx context.Context, userID uuid.UUID, positions map[uuid.UUID]string) error
     GetNoteByID(ctx context.Context, userID, noteID uuid.UUID) (*models.Note, error)
     GetNoteByIDAndUserID(ctx context.Context, noteID string, userID string) (*models.Note, error)
     CountNotesByIDsAndUserID(ctx context.Context, noteIDs []string, userID string) (int, error)
     GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilter) (*dto.PaginatedNotes, error)
     UpdateNote(ctx context.Context, note *models.Note) error
+    // UpdateNoteFields сохраняет только поля fields (имена колонок) и увеличивает версию без проверки
+    // ожидаемой: для внутренних изменений (повторение, экзамен, переписывание ссылок, восстановление
+    // ревизии), которые не должны падать из-за параллельной правки. note.Version становится новой версией
+    UpdateNoteFields(ctx context.Context, note *models.Note, fields ...string) error
+    // PatchNote сохраняет заметку и, если tagIDs не nil, заменяет её теги — в одной транзакции
+    PatchNote(ctx context.Context, note *models.Note, tagIDs []uuid.UUID) error
     ArchiveNote(ctx context.Context, id string) error
     UnArchiveNote(ctx context.Context, id string) error
     DeleteNote(ctx context.Context, id string) error
@@ -31,4 +41,5 @@ type NoteRepository interface {
     RemoveTag(ctx context.Context,noteID, tagID uuid.UUID) error
     AddTagsBatch(ctx context.Context, noteTags []NoteTag) error
     GetNotesForReview(ctx context.Context, userID uuid.UUID, filter *dto.ReviewSessionInput) ([]models.Note, error)
+    GetNotesForExam(ctx context.Context, userID uuid.UUID, filter *dto.ExamInput) ([]models.Note, error)
 }
\ No newline at end of file
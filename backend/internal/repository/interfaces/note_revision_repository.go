@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type NoteRevisionRepository interface {
+	// Create присваивает ревизии следующий номер в пределах заметки
+	Create(ctx context.Context, revision *models.NoteRevision) error
+	ListByNote(ctx context.Context, noteID uuid.UUID) ([]models.NoteRevision, error)
+	GetByNumber(ctx context.Context, noteID uuid.UUID, revision int) (*models.NoteRevision, error)
+	GetLatest(ctx context.Context, noteID uuid.UUID) (*models.NoteRevision, error)
+	// Prune оставляет keep последних ревизий заметки
+	Prune(ctx context.Context, noteID uuid.UUID, keep int) error
+}
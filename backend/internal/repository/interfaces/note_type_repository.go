@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type NoteTypeRepository interface {
+	Create(ctx context.Context, noteType *models.NoteType) error
+	GetByID(ctx context.Context, userID, typeID uuid.UUID) (*models.NoteType, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteType, error)
+	Update(ctx context.Context, noteType *models.NoteType) error
+	Delete(ctx context.Context, userID, typeID uuid.UUID) error
+	// ExistsByName проверяет имя без учёта регистра; excludeID — тип, который сейчас переименовывается
+	ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	// CountNotes считает заметки типа, включая лежащие в корзине
+	CountNotes(ctx context.Context, typeID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type PrerequisiteRepository interface {
+	Transactor
+	// LockGraph блокирует граф пререквизитов пользователя до конца транзакции:
+	// проверка циклов и вставка ребра не пересекаются с параллельными вставками
+	LockGraph(ctx context.Context, userID uuid.UUID) error
+	Create(ctx context.Context, link *models.NotePrerequisite) error
+	Delete(ctx context.Context, userID, noteID, prerequisiteID uuid.UUID) error
+	ListByNote(ctx context.Context, userID, noteID uuid.UUID) ([]models.NotePrerequisite, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.NotePrerequisite, error)
+	ListByFolder(ctx context.Context, userID, folderID uuid.UUID) ([]models.NotePrerequisite, error)
+}
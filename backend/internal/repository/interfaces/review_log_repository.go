@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+// RecallStat — сколько раз карточку с уровнем LevelBefore вспомнили из Total попыток
+type RecallStat struct {
+	LevelBefore int
+	Total       int64
+	Remembered  int64
+}
+
+// FolderReviewCount — ответы с момента since по папке заметки (FolderID nil — без папки);
+// New — первые ответы на карточки, которых раньше не повторяли
+type FolderReviewCount struct {
+	FolderID *uuid.UUID
+	Total    int
+	New      int `gorm:"column:new_count"`
+}
+
+type ReviewLogRepository interface {
+	Create(ctx context.Context, log *models.ReviewLog) error
+	GetRecallStats(ctx context.Context, userID uuid.UUID) ([]RecallStat, error)
+	// CountByFolderSince считает ответы пользователя с момента since по папкам заметок
+	CountByFolderSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]FolderReviewCount, error)
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+type SavedSearchRepository interface {
+	Create(ctx context.Context, search *models.SavedSearch) error
+	GetByID(ctx context.Context, userID, searchID uuid.UUID) (*models.SavedSearch, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.SavedSearch, error)
+	Update(ctx context.Context, search *models.SavedSearch) error
+	Delete(ctx context.Context, userID, searchID uuid.UUID) error
+	// ExistsByName проверяет имя без учёта регистра; excludeID — поиск, который сейчас переименовывается
+	ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+}
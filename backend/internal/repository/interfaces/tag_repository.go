@@ -8,6 +8,7 @@ import (
 )
 
 type TagRepository interface {
+    Transactor
     Create(ctx context.Context, tag *models.Tag) error
     GetByID(ctx context.Context, userID, tagID uuid.UUID) (*models.Tag, error)
     ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Tag, error)
@@ -15,6 +16,11 @@ type TagRepository interface {
     Delete(ctx context.Context, userID, tagID uuid.UUID) error
     ExistsByName(ctx context.Context, userID uuid.UUID, name string) (bool, error)
     CountTagsByIDsAndUserID(ctx context.Context, tagIDs []string, userID string) (int, error)
+    // Merge переносит связи sources на target, потомков sources — под target и удаляет sources;
+    // возвращает число перенесённых связей и связей-дублей, которые уже были у target
+    Merge(ctx context.Context, sources []models.Tag, target *models.Tag) (moved, duplicates int, err error)
+    // Rename задаёт тегам новые имена без каскада на потомков (массовое переименование)
+    Rename(ctx context.Context, userID uuid.UUID, names map[uuid.UUID]string) error
 
     // связи заметок и тегов
     AttachToNote(ctx context.Context, noteID, tagID uuid.UUID) error
@@ -0,0 +1,9 @@
+package interfaces
+
+import "context"
+
+// Transactor выполняет fn в одной транзакции: все репозитории, вызванные с ctx из fn,
+// пишут в неё же, а ошибка fn откатывает всё целиком
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/models"
+)
+
+// TrashRepository — операции над мягко удалёнными заметками, папками и тегами
+type TrashRepository interface {
+	ListDeletedNotes(ctx context.Context, userID uuid.UUID) ([]models.Note, error)
+	ListDeletedFolders(ctx context.Context, userID uuid.UUID) ([]models.Folder, error)
+	ListDeletedTags(ctx context.Context, userID uuid.UUID) ([]models.Tag, error)
+
+	GetDeletedNote(ctx context.Context, userID, id uuid.UUID) (*models.Note, error)
+	GetDeletedFolder(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error)
+	GetDeletedTag(ctx context.Context, userID, id uuid.UUID) (*models.Tag, error)
+
+	RestoreNote(ctx context.Context, note *models.Note) error
+	RestoreFolder(ctx context.Context, folder *models.Folder) error
+	RestoreTag(ctx context.Context, tag *models.Tag) error
+
+	// Purge окончательно удаляет всё, что попало в корзину раньше before
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// noteTagBatchSize — сколько связей заметка–тег вставляется одним запросом
+const noteTagBatchSize = 400
+
+type noteBulkRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteBulkRepository(db *gorm.DB) interfaces.NoteBulkRepository {
+	return &noteBulkRepository{db: db}
+}
+
+func (r *noteBulkRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, r.db, fn)
+}
+
+// ListByIDs блокирует строки заметок (FOR UPDATE): статусы, посчитанные по ним,
+// остаются верными до записи в той же транзакции
+func (r *noteBulkRepository) ListByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]models.Note, error) {
+	var notes []models.Note
+	err := dbFor(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Tags").
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *noteBulkRepository) Apply(ctx context.Context, userID uuid.UUID, op *interfaces.BulkOperation) error {
+	if len(op.NoteIDs) == 0 {
+		return nil
+	}
+
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Заметки могли удалить или передать между чтением и записью
+		var count int64
+		if err := tx.Model(&models.Note{}).
+			Where("user_id = ? AND id IN ?", userID, op.NoteIDs).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(op.NoteIDs) {
+			return apperrors.ErrNotFound
+		}
+
+		var changes map[string]interface{}
+		switch op.Action {
+		case dto.BulkArchive:
+			changes = map[string]interface{}{"archived": true}
+		case dto.BulkUnarchive:
+			changes = map[string]interface{}{"archived": false}
+		case dto.BulkMove:
+			changes = map[string]interface{}{"folder_id": op.FolderID}
+		case dto.BulkResetScheduling:
+			changes = map[string]interface{}{"memory_level": 0, "next_review_at": nil}
+		case dto.BulkAddTags:
+			if err := insertNoteTags(tx, op.NoteIDs, op.TagIDs); err != nil {
+				return err
+			}
+			changes = map[string]interface{}{}
+		case dto.BulkRemoveTags:
+			if err := tx.Where("note_id IN ? AND tag_id IN ?", op.NoteIDs, op.TagIDs).
+				Delete(&models.NoteTag{}).Error; err != nil {
+				return err
+			}
+			changes = map[string]interface{}{}
+		case dto.BulkReplaceTags:
+			if err := tx.Where("note_id IN ?", op.NoteIDs).Delete(&models.NoteTag{}).Error; err != nil {
+				return err
+			}
+			if err := insertNoteTags(tx, op.NoteIDs, op.TagIDs); err != nil {
+				return err
+			}
+			changes = map[string]interface{}{}
+		default:
+			return fmt.Errorf("%w: unknown action %q", apperrors.ErrInvalidInput, op.Action)
+		}
+
+		changes["version"] = gorm.Expr("version + 1")
+		return tx.Model(&models.Note{}).Where("id IN ?", op.NoteIDs).Updates(changes).Error
+	})
+}
+
+// insertNoteTags связывает каждую заметку с каждым тегом; существующие связи пропускаются
+func insertNoteTags(tx *gorm.DB, noteIDs, tagIDs []uuid.UUID) error {
+	values := make([]string, 0, noteTagBatchSize)
+	args := make([]interface{}, 0, noteTagBatchSize*2)
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		err := tx.Exec(fmt.Sprintf(`
+			INSERT INTO note_tags (note_id, tag_id)
+			VALUES %s
+			ON CONFLICT (note_id, tag_id) DO NOTHING
+		`, strings.Join(values, ",")), args...).Error
+		values, args = values[:0], args[:0]
+		return err
+	}
+
+	for _, noteID := range noteIDs {
+		for _, tagID := range tagIDs {
+			values = append(values, "(?, ?)")
+			args = append(args, noteID, tagID)
+			if len(values) == noteTagBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type noteDuplicateRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteDuplicateRepository(db *gorm.DB) interfaces.NoteDuplicateRepository {
+	return &noteDuplicateRepository{db: db}
+}
+
+func (r *noteDuplicateRepository) ListCandidates(ctx context.Context, userID uuid.UUID) ([]models.Note, error) {
+	var notes []models.Note
+	err := dbFor(ctx, r.db).
+		Select("id", "user_id", "title", "content", "memory_level", "next_review_at", "created_at").
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *noteDuplicateRepository) ListStale(ctx context.Context, userID uuid.UUID) ([]models.Note, error) {
+	var notes []models.Note
+	err := dbFor(ctx, r.db).
+		Select("id", "user_id", "title", "content", "version").
+		Where("user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM note_dedup_keys k WHERE k.note_id = notes.id AND k.version = notes.version)").
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *noteDuplicateRepository) ReplaceKeys(ctx context.Context, noteIDs []uuid.UUID, keys []models.NoteDedupKey) error {
+	if len(noteIDs) == 0 {
+		return nil
+	}
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(noteIDs); start += 500 {
+			end := start + 500
+			if end > len(noteIDs) {
+				end = len(noteIDs)
+			}
+			if err := tx.Where("note_id IN ?", noteIDs[start:end]).Delete(&models.NoteDedupKey{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		// Ключи одной заметки могут совпасть (заголовок и полоса) — повтор пропускается
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(keys, 500).Error
+	})
+}
+
+func (r *noteDuplicateRepository) ListByKeys(ctx context.Context, userID uuid.UUID, keys []int64) ([]models.Note, error) {
+	var notes []models.Note
+	if len(keys) == 0 {
+		return notes, nil
+	}
+	err := dbFor(ctx, r.db).
+		Select("id", "user_id", "title", "content", "memory_level", "next_review_at", "created_at").
+		Where("user_id = ?", userID).
+		Where(`id IN (
+			SELECT k.note_id FROM note_dedup_keys k
+			JOIN notes n ON n.id = k.note_id AND n.version = k.version
+			WHERE k.user_id = ? AND k.key IN ?
+		)`, userID, keys).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *noteDuplicateRepository) ListByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]models.Note, error) {
+	var notes []models.Note
+	err := dbFor(ctx, r.db).
+		Preload("Tags").
+		Preload("Folder").
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *noteDuplicateRepository) Merge(ctx context.Context, target *models.Note, sourceIDs []uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Теги объединяются; уже имеющиеся у target пропускаются
+		if err := tx.Exec(`
+			INSERT INTO note_tags (note_id, tag_id)
+			SELECT n.id, nt.tag_id FROM note_tags nt
+			JOIN notes n ON n.id = ?
+			WHERE nt.note_id IN ?
+			GROUP BY n.id, nt.tag_id
+			ON CONFLICT (note_id, tag_id) DO NOTHING
+		`, target.ID, sourceIDs).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Note{}).
+			Where("id = ?", target.ID).
+			Updates(map[string]interface{}{
+				"memory_level":   target.MemoryLevel,
+				"next_review_at": target.NextReviewAt,
+				"version":        gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		// История ответов переходит к target, чтобы статистика не потерялась
+		if err := tx.Model(&models.ReviewLog{}).
+			Where("note_id IN ?", sourceIDs).
+			Update("note_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		// Ссылки на дубликаты теперь ведут на target; ссылки target на дубликаты стали бы петлёй
+		if err := tx.Where("source_id = ? AND target_id IN ?", target.ID, sourceIDs).
+			Delete(&models.NoteLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NoteLink{}).
+			Where("target_id IN ?", sourceIDs).
+			Update("target_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", sourceIDs).Delete(&models.Note{}).Error
+	})
+}
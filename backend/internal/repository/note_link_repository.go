@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type noteLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteLinkRepository(db *gorm.DB) interfaces.NoteLinkRepository {
+	return &noteLinkRepository{db: db}
+}
+
+func (r *noteLinkRepository) ReplaceForNote(ctx context.Context, sourceID uuid.UUID, links []models.NoteLink) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_id = ?", sourceID).Delete(&models.NoteLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+func (r *noteLinkRepository) ListBySource(ctx context.Context, userID, sourceID uuid.UUID) ([]models.NoteLink, error) {
+	var links []models.NoteLink
+	err := dbFor(ctx, r.db).
+		Where("user_id = ? AND source_id = ?", userID, sourceID).
+		Preload("Target").
+		Order("position ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *noteLinkRepository) ListByTarget(ctx context.Context, userID, targetID uuid.UUID) ([]models.NoteLink, error) {
+	var links []models.NoteLink
+	err := dbFor(ctx, r.db).
+		InnerJoins("Source").
+		Where("note_links.user_id = ? AND note_links.target_id = ?", userID, targetID).
+		Order("Source.title ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *noteLinkRepository) ListResolvedByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error) {
+	var links []models.NoteLink
+	err := dbFor(ctx, r.db).
+		Where("user_id = ? AND target_id IS NOT NULL", userID).
+		Order("source_id ASC, position ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *noteLinkRepository) ListDangling(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error) {
+	var links []models.NoteLink
+	err := dbFor(ctx, r.db).
+		InnerJoins("Source").
+		Joins("LEFT JOIN notes t ON t.id = note_links.target_id AND t.deleted_at IS NULL").
+		Where("note_links.user_id = ? AND t.id IS NULL", userID).
+		Order("Source.title ASC, note_links.text ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *noteLinkRepository) FindNoteIDByTitle(ctx context.Context, userID uuid.UUID, title string) (*uuid.UUID, error) {
+	var note models.Note
+	result := dbFor(ctx, r.db).
+		Select("id").
+		Where("user_id = ? AND LOWER(title) = LOWER(?)", userID, title).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&note)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &note.ID, nil
+}
+
+func (r *noteLinkRepository) ResolveDangling(ctx context.Context, userID uuid.UUID, title string, noteID uuid.UUID) error {
+	return dbFor(ctx, r.db).
+		Model(&models.NoteLink{}).
+		Where("user_id = ? AND target_id IS NULL AND LOWER(text) = LOWER(?) AND source_id <> ?", userID, title, noteID).
+		Update("target_id", noteID).Error
+}
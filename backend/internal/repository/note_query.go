@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"valibibe/internal/models"
+	"valibibe/internal/notequery"
+)
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы подстрока искалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// noteQueryCompiler превращает AST языка запросов в SQL-условие над таблицей notes
+type noteQueryCompiler struct {
+	db     *gorm.DB
+	userID string
+	now    time.Time
+}
+
+func (c *noteQueryCompiler) compile(expr notequery.Expr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case *notequery.And:
+		parts := make([]string, 0, len(e.Terms))
+		args := make([]interface{}, 0)
+		for _, term := range e.Terms {
+			sql, termArgs, err := c.compile(term)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, "("+sql+")")
+			args = append(args, termArgs...)
+		}
+		return strings.Join(parts, " AND "), args, nil
+
+	case *notequery.Not:
+		sql, args, err := c.compile(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+
+	case *notequery.Tag:
+		return `EXISTS (SELECT 1 FROM note_tags qt JOIN tags t ON t.id = qt.tag_id
+			WHERE qt.note_id = notes.id AND t.deleted_at IS NULL AND LOWER(t.name) = ?)`,
+			[]interface{}{strings.ToLower(e.Name)}, nil
+
+	case *notequery.Folder:
+		folderID, err := c.resolveFolderPath(e.Path)
+		if err != nil {
+			return "", nil, err
+		}
+		if folderID == nil {
+			return "1 = 0", nil, nil // такой папки нет — условие ложно
+		}
+		return "notes.folder_id = ?", []interface{}{*folderID}, nil
+
+	case *notequery.Is:
+		if e.Flag == notequery.FlagArchived {
+			return "notes.archived = ?", []interface{}{true}, nil
+		}
+		return "notes.next_review_at IS NOT NULL AND notes.next_review_at <= ?", []interface{}{c.now}, nil
+
+	case *notequery.Level:
+		return "notes.memory_level " + e.Op + " ?", []interface{}{e.Value}, nil
+
+	case *notequery.Created:
+		// Сравнение по целым дням: created:>2025-01-01 — начиная со 2 января
+		day, next := e.Date, e.Date.AddDate(0, 0, 1)
+		switch e.Op {
+		case notequery.OpLt:
+			return "notes.created_at < ?", []interface{}{day}, nil
+		case notequery.OpLte:
+			return "notes.created_at < ?", []interface{}{next}, nil
+		case notequery.OpGt:
+			return "notes.created_at >= ?", []interface{}{next}, nil
+		case notequery.OpGte:
+			return "notes.created_at >= ?", []interface{}{day}, nil
+		}
+		return "notes.created_at >= ? AND notes.created_at < ?", []interface{}{day, next}, nil
+
+	case *notequery.Text:
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(e.Value)) + "%"
+		return `LOWER(notes.title) LIKE ? ESCAPE '\' OR LOWER(notes.content) LIKE ? ESCAPE '\'`,
+			[]interface{}{pattern, pattern}, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported query node %T", expr)
+}
+
+// resolveFolderPath находит папку пользователя по именам от корня; nil — если пути нет
+func (c *noteQueryCompiler) resolveFolderPath(path []string) (*uuid.UUID, error) {
+	var parentID *uuid.UUID
+	for _, name := range path {
+		var folder models.Folder
+		query := c.db.Model(&models.Folder{}).Where("user_id = ? AND name = ?", c.userID, name)
+		if parentID == nil {
+			query = query.Where("parent_id IS NULL")
+		} else {
+			query = query.Where("parent_id = ?", *parentID)
+		}
+		result := query.Limit(1).Find(&folder)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil
+		}
+		id := folder.ID
+		parentID = &id
+	}
+	return parentID, nil
+}
+
+// applyNoteQuery добавляет к запросу условие из разобранного выражения q
+func (r *NoteRepo) applyNoteQuery(ctx context.Context, query *gorm.DB, userID string, expr notequery.Expr) (*gorm.DB, error) {
+	compiler := &noteQueryCompiler{db: dbFor(ctx, r.db), userID: userID, now: time.Now()}
+	sql, args, err := compiler.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where("("+sql+")", args...), nil
+}
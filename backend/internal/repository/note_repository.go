@@ -23,13 +23,52 @@ func NewNoteRepository(db *gorm.DB) interfaces.NoteRepository {
 	return &NoteRepo{db: db}
 }
 
+func (r *NoteRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, r.db, fn)
+}
+
+// CreateNote сохраняет заметку; без заданной позиции она встаёт последней в своей папке
 func (r *NoteRepo) CreateNote(ctx context.Context, note *models.Note) error {
-	return r.db.WithContext(ctx).Create(note).Error
+	db := dbFor(ctx, r.db)
+	if note.Position == "" {
+		position, err := nextPosition(db, &models.Note{}, note.UserID, "folder_id", note.FolderID)
+		if err != nil {
+			return err
+		}
+		note.Position = position
+	}
+	return db.Create(note).Error
+}
+
+// ListSiblings возвращает заметки папки folderID (nil — без папки) в ручном порядке,
+// только с полями, нужными для перестановки
+func (r *NoteRepo) ListSiblings(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID) ([]models.Note, error) {
+	query := dbFor(ctx, r.db).
+		Select("id", "user_id", "folder_id", "position", "created_at").
+		Where("user_id = ?", userID)
+	if folderID == nil {
+		query = query.Where("folder_id IS NULL")
+	} else {
+		query = query.Where("folder_id = ?", *folderID)
+	}
+
+	var notes []models.Note
+	if err := query.Order("position ASC, created_at ASC, id ASC").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// SetPositions записывает новые позиции заметок одной транзакцией
+func (r *NoteRepo) SetPositions(ctx context.Context, userID uuid.UUID, positions map[uuid.UUID]string) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return setPositions(tx, &models.Note{}, userID, positions)
+	})
 }
 
 func (r *NoteRepo) GetNoteByID(ctx context.Context, userID, noteID uuid.UUID) (*models.Note, error) {
 	var note models.Note
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("id = ? AND user_id = ?", noteID, userID).
 		First(&note).Error
 
@@ -41,7 +80,7 @@ func (r *NoteRepo) GetNoteByID(ctx context.Context, userID, noteID uuid.UUID) (*
 
 func (r *NoteRepo) GetNoteByIDAndUserID(ctx context.Context, id string, userID string) (*models.Note, error) {
 	var note models.Note
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&note).Error
 
@@ -51,13 +90,10 @@ func (r *NoteRepo) GetNoteByIDAndUserID(ctx context.Context, id string, userID s
 	return &note, err
 }
 
-func (r *NoteRepo) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilter) (*dto.PaginatedNotes, error) {
-	var (
-		notes []models.Note
-		total int64
-	)
-
-	query := r.db.WithContext(ctx).
+// filteredNotesQuery строит запрос заметок пользователя по фильтру без сортировки и пагинации.
+// backend не nil, если в фильтре есть полнотекстовый поиск (тогда доступен s.rank).
+func (r *NoteRepo) filteredNotesQuery(ctx context.Context, filter *dto.NoteFilter) (*gorm.DB, searchBackend, error) {
+	query := dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("user_id = ?", filter.UserID)
 
@@ -65,31 +101,67 @@ func (r *NoteRepo) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilt
 		query = query.Where("archived = ?", *filter.Archived)
 	}
 
-	if filter.Search != "" {
-		query = query.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(filter.Search)+"%")
+	search := strings.TrimSpace(filter.Search)
+	var backend searchBackend
+	if search != "" {
+		backend = searchBackendFor(r.db)
+		query = query.Joins("JOIN (?) AS s ON s.note_id = notes.id",
+			backend.matchQuery(dbFor(ctx, r.db), filter.UserID, search))
 	}
 
 	if filter.FolderID != nil && *filter.FolderID != "" {
-		query = query.Where("folder_id = ?", *filter.FolderID)
+		if filter.IncludeSubfolders {
+			subtree, err := folderSubtree(dbFor(ctx, r.db), filter.UserID, *filter.FolderID)
+			if err != nil {
+				return nil, nil, err
+			}
+			query = query.Where("notes.folder_id IN (?)", subtree)
+		} else {
+			query = query.Where("folder_id = ?", *filter.FolderID)
+		}
 	}
 
 	if len(filter.TagIDs) > 0 {
-		query = query.Joins("JOIN note_tags nt ON nt.note_id = notes.id").
-			Where("nt.tag_id IN ?", filter.TagIDs)
-		// Если нужно только заметки, у которых есть все указанные теги, используйте HAVING COUNT(DISTINCT nt.tag_id) = len(filter.TagIDs)
-		// query = query.Group("notes.id").Having("COUNT(DISTINCT nt.tag_id) = ?", len(filter.TagIDs))
+		// Родительский тег совпадает и с заметками его потомков; подзапрос вместо JOIN,
+		// чтобы заметка с родителем и потомком не попала в выдачу дважды
+		query = query.Where("notes.id IN (?)", dbFor(ctx, r.db).Table("note_tags").Select("note_id").
+			Where("tag_id IN (?)", tagSubtree(dbFor(ctx, r.db), filter.UserID, filter.TagIDs)))
 	}
 
+	// Условия из языка запросов (параметр q)
+	if filter.Expr != nil {
+		var err error
+		if query, err = r.applyNoteQuery(ctx, query, filter.UserID, filter.Expr); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return query, backend, nil
+}
+
+func (r *NoteRepo) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilter) (*dto.PaginatedNotes, error) {
+	var (
+		notes []models.Note
+		total int64
+	)
+
+	query, backend, err := r.filteredNotesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	search := strings.TrimSpace(filter.Search)
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, err
 	}
 
 	sortField := map[string]string{
-		"created_at":     "created_at",
-		"next_review_at": "next_review_at",
+		"created_at":     "notes.created_at",
+		"next_review_at": "notes.next_review_at",
+		"position":       "notes.position",
 	}[filter.SortBy]
 	if sortField == "" {
-		sortField = "created_at"
+		sortField = "notes.created_at"
 	}
 
 	order := "desc"
@@ -97,7 +169,16 @@ func (r *NoteRepo) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilt
 		order = "asc"
 	}
 
-	query = query.Order(sortField + " " + order)
+	// При поиске по умолчанию сортируем по релевантности
+	if backend != nil && (filter.SortBy == "" || filter.SortBy == "relevance") {
+		query = query.Order("s.rank DESC").Order("notes.created_at DESC")
+	} else {
+		query = query.Order(sortField + " " + order)
+		if filter.SortBy == "position" {
+			// у заметок, перенесённых из другой папки, ключи могут совпадать
+			query = query.Order("notes.created_at " + order).Order("notes.id " + order)
+		}
+	}
 
 	if filter.Limit > 0 {
 		query = query.Limit(filter.Limit)
@@ -109,36 +190,98 @@ func (r *NoteRepo) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilt
 	// Preload tags to avoid N+1
 	query = query.Preload("Tags")
 
-	if err := query.Find(&notes).Error; err != nil {
+	if err := query.Select("notes.*").Find(&notes).Error; err != nil {
 		return nil, err
 	}
 
-	return &dto.PaginatedNotes{
+	result := &dto.PaginatedNotes{
 		Notes: notes,
 		Total: total,
-	}, nil
+	}
+
+	if backend != nil {
+		ids := make([]uuid.UUID, len(notes))
+		for i, n := range notes {
+			ids[i] = n.ID
+		}
+		highlights, err := r.searchHighlights(ctx, backend, search, ids)
+		if err != nil {
+			return nil, err
+		}
+		result.Highlights = highlights
+	}
+
+	return result, nil
 }
 
+// UpdateNote сохраняет заметку и увеличивает её версию, если версия в базе
+// не изменилась с момента чтения; иначе возвращает apperrors.ErrVersionConflict
 func (r *NoteRepo) UpdateNote(ctx context.Context, note *models.Note) error {
-	return r.db.WithContext(ctx).Save(note).Error
+	return updateVersioned(dbFor(ctx, r.db), note, &note.Version)
+}
+
+func (r *NoteRepo) UpdateNoteFields(ctx context.Context, note *models.Note, fields ...string) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(note).Select(fields).Updates(note).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Note{}).
+			Where("id = ?", note.ID).
+			UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Note{}).Where("id = ?", note.ID).Select("version").Scan(&note.Version).Error
+	})
+}
+
+// PatchNote в одной транзакции сохраняет заметку (с проверкой версии, как UpdateNote)
+// и, если tagIDs не nil, заменяет её теги на tagIDs. note.Tags перечитываются.
+func (r *NoteRepo) PatchNote(ctx context.Context, note *models.Note, tagIDs []uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, note, &note.Version); err != nil {
+			return err
+		}
+
+		if tagIDs != nil {
+			remove := tx.Where("note_id = ?", note.ID)
+			if len(tagIDs) > 0 {
+				remove = remove.Where("tag_id NOT IN ?", tagIDs)
+			}
+			if err := remove.Delete(&models.NoteTag{}).Error; err != nil {
+				return err
+			}
+			for _, tagID := range tagIDs {
+				if err := tx.Exec(`
+                    INSERT INTO note_tags (note_id, tag_id)
+                    VALUES (?, ?)
+                    ON CONFLICT (note_id, tag_id) DO NOTHING
+                `, note.ID, tagID).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		note.Tags = nil
+		return tx.Model(note).Association("Tags").Find(&note.Tags)
+	})
 }
 
 func (r *NoteRepo) ArchiveNote(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("id = ?", id).
 		Update("archived", true).Error
 }
 
 func (r *NoteRepo) UnArchiveNote(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("id = ?", id).
 		Update("archived", false).Error
 }
 
 func (r *NoteRepo) DeleteNote(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Where("id = ?", id).
 		Delete(&models.Note{}).Error
 }
@@ -146,7 +289,7 @@ func (r *NoteRepo) DeleteNote(ctx context.Context, id string) error {
 
 func (r *NoteRepo) CountNotesByIDsAndUserID(ctx context.Context, noteIDs []string, userID string) (int, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("id IN ? AND user_id = ?", noteIDs, userID).
 		Count(&count).Error
@@ -154,14 +297,14 @@ func (r *NoteRepo) CountNotesByIDsAndUserID(ctx context.Context, noteIDs []strin
 }
 
 func (r *NoteRepo) UpdateFolder(ctx context.Context, noteID uuid.UUID, folderID *uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("id = ?", noteID).
 		Update("folder_id", folderID).Error
 }
 
 func (r *NoteRepo) BatchUpdateFolder(ctx context.Context, noteIDs []string, folderID *uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("id IN ?", noteIDs).
 		Update("folder_id", folderID).Error
@@ -169,7 +312,7 @@ func (r *NoteRepo) BatchUpdateFolder(ctx context.Context, noteIDs []string, fold
 
 // Добавить тег к заметке (upsert)
 func (r *NoteRepo) AddTag(ctx context.Context, noteID, tagID uuid.UUID) error {
-	return r.db.WithContext(ctx).Exec(`
+	return dbFor(ctx, r.db).Exec(`
         INSERT INTO note_tags (note_id, tag_id)
         VALUES (?, ?)
         ON CONFLICT (note_id, tag_id) DO NOTHING
@@ -178,7 +321,7 @@ func (r *NoteRepo) AddTag(ctx context.Context, noteID, tagID uuid.UUID) error {
 
 // Удалить тег у заметки
 func (r *NoteRepo) RemoveTag(ctx context.Context, noteID, tagID uuid.UUID) error {
-	return r.db.WithContext(ctx).Exec(`
+	return dbFor(ctx, r.db).Exec(`
         DELETE FROM note_tags WHERE note_id = ? AND tag_id = ?
     `, noteID, tagID).Error
 }
@@ -202,7 +345,7 @@ func (r *NoteRepo) AddTagsBatch(ctx context.Context, noteTags []interfaces.NoteT
         ON CONFLICT (note_id, tag_id) DO NOTHING
     `, strings.Join(values, ","))
 
-	return r.db.WithContext(ctx).Exec(query, args...).Error
+	return dbFor(ctx, r.db).Exec(query, args...).Error
 }
 
 // GetNotesForReview возвращает заметки для повторения с учетом фильтров
@@ -211,24 +354,51 @@ func (r *NoteRepo) GetNotesForReview(ctx context.Context, userID uuid.UUID, filt
 
 	// Базовый запрос для заметок готовых к повторению
 	now := time.Now()
-	query := r.db.WithContext(ctx).
+	query := dbFor(ctx, r.db).
 		Model(&models.Note{}).
 		Where("user_id = ? AND archived = ? AND next_review_at IS NOT NULL AND next_review_at <= ?",
 			userID, false, now).
 		Preload("Tags").
 		Preload("Folder")
 
-	// Применяем фильтры
+	// Сохранённый поиск ограничивает выборку своими фильтрами
+	if filter.Filter != nil {
+		matched, _, err := r.filteredNotesQuery(ctx, filter.Filter)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("notes.id IN (?)", matched.Select("notes.id"))
+	}
+
+	// Применяем фильтры; поддерево папки нужно и основному запросу, и добору случайных заметок
+	var subtree *gorm.DB
+	if filter.FolderID != nil && *filter.FolderID != "" && filter.IncludeSubfolders {
+		var err error
+		if subtree, err = folderSubtree(dbFor(ctx, r.db), userID.String(), *filter.FolderID); err != nil {
+			return nil, err
+		}
+	}
 	if filter.FolderID != nil && *filter.FolderID != "" {
-		query = query.Where("folder_id = ?", *filter.FolderID)
+		if filter.IncludeSubfolders {
+			query = query.Where("notes.folder_id IN (?)", subtree)
+		} else {
+			query = query.Where("folder_id = ?", *filter.FolderID)
+		}
 	}
 
 	if len(filter.TagIDs) > 0 {
 		query = query.Joins("JOIN note_tags ON notes.id = note_tags.note_id").
-			Where("note_tags.tag_id IN (?)", filter.TagIDs).
+			Where("note_tags.tag_id IN (?)", tagSubtree(dbFor(ctx, r.db), userID.String(), filter.TagIDs)).
 			Group("notes.id")
 	}
 
+	// Папки с исчерпанным дневным лимитом повторений и уже отобранные заметки
+	reviewBlocked, reviewArgs := blockedFoldersCondition(filter.ReviewBlocked)
+	query = query.Where("NOT "+reviewBlocked, reviewArgs...)
+	if len(filter.ExcludeIDs) > 0 {
+		query = query.Where("notes.id NOT IN ?", filter.ExcludeIDs)
+	}
+
 	// Сортируем по приоритету повторения (сначала те, что давно не повторялись)
 	query = query.Order("next_review_at ASC, created_at ASC")
 
@@ -253,7 +423,7 @@ func (r *NoteRepo) GetNotesForReview(ctx context.Context, userID uuid.UUID, filt
 		var additionalNotes []models.Note
 
 		// Создаем запрос для случайных заметок (исключая уже выбранные)
-		randomQuery := r.db.WithContext(ctx).
+		randomQuery := dbFor(ctx, r.db).
 			Model(&models.Note{}).
 			Where("user_id = ? AND archived = ?", userID, false).
 			Preload("Tags").
@@ -261,21 +431,45 @@ func (r *NoteRepo) GetNotesForReview(ctx context.Context, userID uuid.UUID, filt
 
 		// Применяем те же фильтры
 		if filter.FolderID != nil && *filter.FolderID != "" {
-			randomQuery = randomQuery.Where("folder_id = ?", *filter.FolderID)
+			if filter.IncludeSubfolders {
+				randomQuery = randomQuery.Where("notes.folder_id IN (?)", subtree)
+			} else {
+				randomQuery = randomQuery.Where("folder_id = ?", *filter.FolderID)
+			}
 		}
 
 		if len(filter.TagIDs) > 0 {
 			randomQuery = randomQuery.Joins("JOIN note_tags ON notes.id = note_tags.note_id").
-				Where("note_tags.tag_id IN (?)", filter.TagIDs).
+				Where("note_tags.tag_id IN (?)", tagSubtree(dbFor(ctx, r.db), userID.String(), filter.TagIDs)).
 				Group("notes.id")
 		}
 
-		// Исключаем уже выбранные заметки
-		if len(notes) > 0 {
-			excludeIDs := make([]uuid.UUID, len(notes))
-			for i, note := range notes {
-				excludeIDs[i] = note.ID
+		if filter.Filter != nil {
+			matched, _, err := r.filteredNotesQuery(ctx, filter.Filter)
+			if err != nil {
+				return notes, err
 			}
+			randomQuery = randomQuery.Where("notes.id IN (?)", matched.Select("notes.id"))
+		}
+
+		// Новые карточки с невыученными пререквизитами в очередь не попадают
+		randomQuery = randomQuery.Where(`(next_review_at IS NOT NULL OR NOT EXISTS (
+			SELECT 1 FROM note_prerequisites np
+			JOIN notes p ON p.id = np.prerequisite_id
+			WHERE np.note_id = notes.id AND p.deleted_at IS NULL AND p.memory_level < np.required_level
+		))`)
+
+		// Новые карточки и повторения папок, где дневной лимит уже исчерпан, не добавляются
+		newBlocked, newArgs := blockedFoldersCondition(filter.NewBlocked)
+		randomQuery = randomQuery.Where("NOT ((next_review_at IS NULL AND "+newBlocked+") OR (next_review_at IS NOT NULL AND "+reviewBlocked+"))",
+			append(newArgs, reviewArgs...)...)
+
+		// Исключаем уже выбранные заметки
+		excludeIDs := append([]uuid.UUID{}, filter.ExcludeIDs...)
+		for _, note := range notes {
+			excludeIDs = append(excludeIDs, note.ID)
+		}
+		if len(excludeIDs) > 0 {
 			randomQuery = randomQuery.Where("id NOT IN (?)", excludeIDs)
 		}
 
@@ -291,3 +485,48 @@ func (r *NoteRepo) GetNotesForReview(ctx context.Context, userID uuid.UUID, filt
 
 	return notes, nil
 }
+
+// blockedFoldersCondition — условие «заметка лежит в одной из папок folderIDs»;
+// uuid.Nil означает заметки без папки, пустой список — всегда ложно
+func blockedFoldersCondition(folderIDs []uuid.UUID) (string, []interface{}) {
+	var ids []uuid.UUID
+	unfiled := false
+	for _, id := range folderIDs {
+		if id == uuid.Nil {
+			unfiled = true
+		} else {
+			ids = append(ids, id)
+		}
+	}
+	parts := []string{"1 = 0"}
+	var args []interface{}
+	if len(ids) > 0 {
+		parts = append(parts, "notes.folder_id IN ?")
+		args = append(args, ids)
+	}
+	if unfiled {
+		parts = append(parts, "notes.folder_id IS NULL")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// GetNotesForExam возвращает все неархивные заметки из выбранной папки и/или с выбранными тегами
+func (r *NoteRepo) GetNotesForExam(ctx context.Context, userID uuid.UUID, filter *dto.ExamInput) ([]models.Note, error) {
+	var notes []models.Note
+
+	query := dbFor(ctx, r.db).
+		Model(&models.Note{}).
+		Where("user_id = ? AND archived = ?", userID, false)
+
+	if filter.FolderID != nil && *filter.FolderID != "" {
+		query = query.Where("folder_id = ?", *filter.FolderID)
+	}
+
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("id IN (?)",
+			r.db.Table("note_tags").Select("note_id").Where("tag_id IN (?)", tagSubtree(dbFor(ctx, r.db), userID.String(), filter.TagIDs)))
+	}
+
+	err := query.Order("created_at ASC").Find(&notes).Error
+	return notes, err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type noteRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteRevisionRepository(db *gorm.DB) interfaces.NoteRevisionRepository {
+	return &noteRevisionRepository{db: db}
+}
+
+// Create присваивает ревизии следующий номер. Номер считается под блокировкой строки заметки
+// (до конца внешней транзакции, если она есть): параллельные правки одной заметки получают
+// номера по очереди, а не сталкиваются на уникальном индексе (note_id, revision)
+func (r *noteRevisionRepository) Create(ctx context.Context, revision *models.NoteRevision) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var locked []uuid.UUID
+		if err := tx.Unscoped().Model(&models.Note{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", revision.NoteID).
+			Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+
+		var last int
+		err := tx.Model(&models.NoteRevision{}).
+			Select("COALESCE(MAX(revision), 0)").
+			Where("note_id = ?", revision.NoteID).
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		revision.Revision = last + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// ListByNote возвращает ревизии заметки, начиная с последней
+func (r *noteRevisionRepository) ListByNote(ctx context.Context, noteID uuid.UUID) ([]models.NoteRevision, error) {
+	var revisions []models.NoteRevision
+	err := dbFor(ctx, r.db).
+		Where("note_id = ?", noteID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *noteRevisionRepository) GetByNumber(ctx context.Context, noteID uuid.UUID, revision int) (*models.NoteRevision, error) {
+	var rev models.NoteRevision
+	err := dbFor(ctx, r.db).
+		Where("note_id = ? AND revision = ?", noteID, revision).
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rev, err
+}
+
+func (r *noteRevisionRepository) GetLatest(ctx context.Context, noteID uuid.UUID) (*models.NoteRevision, error) {
+	var rev models.NoteRevision
+	err := dbFor(ctx, r.db).
+		Where("note_id = ?", noteID).
+		Order("revision DESC").
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rev, err
+}
+
+func (r *noteRevisionRepository) Prune(ctx context.Context, noteID uuid.UUID, keep int) error {
+	var threshold int
+	err := dbFor(ctx, r.db).
+		Model(&models.NoteRevision{}).
+		Select("revision").
+		Where("note_id = ?", noteID).
+		Order("revision DESC").
+		Offset(keep - 1).
+		Limit(1).
+		Scan(&threshold).Error
+	if err != nil || threshold == 0 {
+		return err
+	}
+	return dbFor(ctx, r.db).
+		Where("note_id = ? AND revision < ?", noteID, threshold).
+		Delete(&models.NoteRevision{}).Error
+}
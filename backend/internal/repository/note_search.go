@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller/dto"
+)
+
+// Полнотекстовый поиск по заметкам.
+//
+// PostgreSQL: генерируемая колонка notes.search_vector (миграция 000014) с GIN-индексом.
+// Конфигурация russian стеммит кириллицу русским стеммером, а латиницу — английским,
+// поэтому одной конфигурации хватает для обоих языков.
+//
+// SQLite (тесты и локальный запуск): виртуальная таблица notes_fts, которую
+// синхронизируют триггеры. С build tag sqlite_fts5 (драйвер собирается с FTS5) это FTS5
+// с ранжированием bm25, без него — FTS4 с рангом по числу совпадений
+// (note_search_fts5.go и note_search_fts4.go).
+//
+// Подсветка размечается служебными символами markStart/markStop, а не тегами:
+// текст заметки экранируется как HTML и только потом маркеры заменяются на <mark>.
+
+// searchBackend строит SQL поиска под конкретную СУБД
+type searchBackend interface {
+	// matchQuery возвращает подзапрос (note_id, rank) по совпавшим заметкам пользователя
+	matchQuery(db *gorm.DB, userID, search string) *gorm.DB
+	// highlightQuery возвращает (note_id, rank, title_highlight, snippet) для выбранных заметок
+	highlightQuery(db *gorm.DB, search string, noteIDs []uuid.UUID) *gorm.DB
+}
+
+// Маркеры подсветки; в тексте заметок они не встречаются
+const (
+	markStart = "\x01"
+	markStop  = "\x02"
+)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+// renderHighlight экранирует фрагмент как HTML и превращает маркеры подсветки в <mark>
+func renderHighlight(fragment string) string {
+	return markReplacer.Replace(html.EscapeString(fragment))
+}
+
+type searchHit struct {
+	NoteID         string
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+type postgresSearch struct{}
+
+func (postgresSearch) matchQuery(db *gorm.DB, userID, search string) *gorm.DB {
+	return db.Raw(`
+		SELECT id AS note_id, ts_rank_cd(search_vector, q) AS rank
+		FROM notes, websearch_to_tsquery('russian', ?) q
+		WHERE user_id = ? AND search_vector @@ q
+	`, search, userID)
+}
+
+func (postgresSearch) highlightQuery(db *gorm.DB, search string, noteIDs []uuid.UUID) *gorm.DB {
+	return db.Raw(`
+		SELECT id AS note_id,
+			ts_rank_cd(search_vector, q) AS rank,
+			ts_headline('russian', title, q, ?) AS title_highlight,
+			ts_headline('russian', COALESCE(content, ''), q, ?) AS snippet
+		FROM notes, websearch_to_tsquery('russian', ?) q
+		WHERE id IN ?
+	`,
+		"HighlightAll=true, StartSel="+markStart+", StopSel="+markStop,
+		"StartSel="+markStart+", StopSel="+markStop+`, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`,
+		search, noteIDs)
+}
+
+// ftsQuery превращает пользовательский ввод в безопасный запрос FTS:
+// каждое слово ищется по префиксу, слова объединяются через AND
+func ftsQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return `""`
+	}
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = w + "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchBackendFor выбирает реализацию поиска по диалекту подключения
+func searchBackendFor(db *gorm.DB) searchBackend {
+	if db.Dialector.Name() != "sqlite" {
+		return postgresSearch{}
+	}
+	return sqliteSearch
+}
+
+// EnsureSQLiteSearchIndex создаёт для SQLite таблицу notes_fts и триггеры синхронизации.
+// Для PostgreSQL ничего не делает — индекс создаётся миграцией.
+func EnsureSQLiteSearchIndex(db *gorm.DB) error {
+	if db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+
+	var exists int64
+	if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'notes_fts'").
+		Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	if err := db.Exec(sqliteSearchTable).Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE TRIGGER notes_fts_insert AFTER INSERT ON notes BEGIN
+			INSERT INTO notes_fts (title, content, note_id) VALUES (new.title, new.content, new.id);
+		END`,
+		`CREATE TRIGGER notes_fts_update AFTER UPDATE OF title, content ON notes BEGIN
+			DELETE FROM notes_fts WHERE note_id = old.id;
+			INSERT INTO notes_fts (title, content, note_id) VALUES (new.title, new.content, new.id);
+		END`,
+		`CREATE TRIGGER notes_fts_delete AFTER DELETE ON notes BEGIN
+			DELETE FROM notes_fts WHERE note_id = old.id;
+		END`,
+		`INSERT INTO notes_fts (title, content, note_id) SELECT title, content, id FROM notes`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("create search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// searchHighlights возвращает подсветку и ранг для заметок текущей страницы
+func (r *NoteRepo) searchHighlights(ctx context.Context, backend searchBackend, search string, noteIDs []uuid.UUID) (map[string]dto.NoteHighlight, error) {
+	if len(noteIDs) == 0 {
+		return nil, nil
+	}
+
+	var hits []searchHit
+	if err := backend.highlightQuery(dbFor(ctx, r.db), search, noteIDs).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+
+	highlights := make(map[string]dto.NoteHighlight, len(hits))
+	for _, hit := range hits {
+		highlights[hit.NoteID] = dto.NoteHighlight{
+			Title:   renderHighlight(hit.TitleHighlight),
+			Snippet: renderHighlight(hit.Snippet),
+			Rank:    hit.Rank,
+		}
+	}
+	return highlights, nil
+}
@@ -0,0 +1,39 @@
+//go:build !sqlite_fts5
+
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Без FTS5 драйвер SQLite умеет только FTS4: bm25 в нём нет, ранг — число совпадений
+var sqliteSearch searchBackend = fts4Search{}
+
+const sqliteSearchTable = `CREATE VIRTUAL TABLE notes_fts USING fts4(
+	title, content, note_id, notindexed=note_id, tokenize=unicode61
+)`
+
+type fts4Search struct{}
+
+func (fts4Search) matchQuery(db *gorm.DB, userID, search string) *gorm.DB {
+	// offsets() возвращает по четыре числа на каждое совпадение — считаем их по пробелам
+	return db.Raw(`
+		SELECT f.note_id AS note_id,
+			(LENGTH(offsets(notes_fts)) - LENGTH(REPLACE(offsets(notes_fts), ' ', '')) + 1) / 4.0 AS rank
+		FROM notes_fts f
+		JOIN notes n ON n.id = f.note_id
+		WHERE notes_fts MATCH ? AND n.user_id = ?
+	`, ftsQuery(search), userID)
+}
+
+func (fts4Search) highlightQuery(db *gorm.DB, search string, noteIDs []uuid.UUID) *gorm.DB {
+	return db.Raw(`
+		SELECT note_id,
+			(LENGTH(offsets(notes_fts)) - LENGTH(REPLACE(offsets(notes_fts), ' ', '')) + 1) / 4.0 AS rank,
+			snippet(notes_fts, ?, ?, '…', 0, 64) AS title_highlight,
+			snippet(notes_fts, ?, ?, '…', 1, 16) AS snippet
+		FROM notes_fts
+		WHERE notes_fts MATCH ? AND note_id IN ?
+	`, markStart, markStop, markStart, markStop, ftsQuery(search), noteIDs)
+}
@@ -0,0 +1,39 @@
+//go:build sqlite_fts5
+
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// С тегом sqlite_fts5 go-sqlite3 собирается с FTS5: ранжирование bm25, как у ts_rank_cd в PostgreSQL,
+// учитывает частоту слов, а совпадение в заголовке весит больше совпадения в тексте
+var sqliteSearch searchBackend = fts5Search{}
+
+const sqliteSearchTable = `CREATE VIRTUAL TABLE notes_fts USING fts5(
+	title, content, note_id UNINDEXED, tokenize = 'porter unicode61 remove_diacritics 2'
+)`
+
+type fts5Search struct{}
+
+func (fts5Search) matchQuery(db *gorm.DB, userID, search string) *gorm.DB {
+	// bm25 тем меньше, чем лучше совпадение; совпадение в заголовке весит в 10 раз больше
+	return db.Raw(`
+		SELECT f.note_id AS note_id, -bm25(notes_fts, 10.0, 1.0, 0.0) AS rank
+		FROM notes_fts f
+		JOIN notes n ON n.id = f.note_id
+		WHERE notes_fts MATCH ? AND n.user_id = ?
+	`, ftsQuery(search), userID)
+}
+
+func (fts5Search) highlightQuery(db *gorm.DB, search string, noteIDs []uuid.UUID) *gorm.DB {
+	return db.Raw(`
+		SELECT note_id,
+			-bm25(notes_fts, 10.0, 1.0, 0.0) AS rank,
+			highlight(notes_fts, 0, ?, ?) AS title_highlight,
+			snippet(notes_fts, 1, ?, ?, '…', 16) AS snippet
+		FROM notes_fts
+		WHERE notes_fts MATCH ? AND note_id IN ?
+	`, markStart, markStop, markStart, markStop, ftsQuery(search), noteIDs)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type noteTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteTypeRepository(db *gorm.DB) interfaces.NoteTypeRepository {
+	return &noteTypeRepository{db: db}
+}
+
+func (r *noteTypeRepository) Create(ctx context.Context, noteType *models.NoteType) error {
+	return dbFor(ctx, r.db).Create(noteType).Error
+}
+
+func (r *noteTypeRepository) GetByID(ctx context.Context, userID, typeID uuid.UUID) (*models.NoteType, error) {
+	var noteType models.NoteType
+	err := dbFor(ctx, r.db).
+		Where("id = ? AND user_id = ?", typeID, userID).
+		First(&noteType).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &noteType, err
+}
+
+func (r *noteTypeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteType, error) {
+	var noteTypes []models.NoteType
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&noteTypes).Error
+	return noteTypes, err
+}
+
+func (r *noteTypeRepository) Update(ctx context.Context, noteType *models.NoteType) error {
+	return dbFor(ctx, r.db).Save(noteType).Error
+}
+
+func (r *noteTypeRepository) Delete(ctx context.Context, userID, typeID uuid.UUID) error {
+	result := dbFor(ctx, r.db).
+		Where("id = ? AND user_id = ?", typeID, userID).
+		Delete(&models.NoteType{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *noteTypeRepository) ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	var count int64
+	query := dbFor(ctx, r.db).
+		Model(&models.NoteType{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *noteTypeRepository) CountNotes(ctx context.Context, typeID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFor(ctx, r.db).
+		Unscoped().
+		Model(&models.Note{}).
+		Where("note_type_id = ?", typeID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"valibibe/internal/fractional"
+)
+
+// nextPosition возвращает ключ после последнего элемента родителя:
+// column — колонка родителя (parent_id у папок, folder_id у заметок), parentID == nil — корень
+func nextPosition(tx *gorm.DB, model interface{}, userID uuid.UUID, column string, parentID *uuid.UUID) (string, error) {
+	last, err := lastPosition(tx, model, userID, column, parentID)
+	if err != nil {
+		return "", err
+	}
+	return fractional.Between(last, "")
+}
+
+func lastPosition(tx *gorm.DB, model interface{}, userID uuid.UUID, column string, parentID *uuid.UUID) (string, error) {
+	query := tx.Model(model).Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where(column + " IS NULL")
+	} else {
+		query = query.Where(column+" = ?", *parentID)
+	}
+
+	var positions []string
+	if err := query.Order("position DESC").Limit(1).Pluck("position", &positions).Error; err != nil {
+		return "", err
+	}
+	if len(positions) == 0 || fractional.Validate(positions[0]) != nil {
+		return "", nil
+	}
+	return positions[0], nil
+}
+
+// setPositions записывает новые ключи порядка и увеличивает версии изменённых строк
+func setPositions(tx *gorm.DB, model interface{}, userID uuid.UUID, positions map[uuid.UUID]string) error {
+	for id, position := range positions {
+		if err := tx.Model(model).
+			Where("id = ? AND user_id = ?", id, userID).
+			Updates(map[string]interface{}{
+				"position": position,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
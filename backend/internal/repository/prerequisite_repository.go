@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type prerequisiteRepository struct {
+	db *gorm.DB
+}
+
+func NewPrerequisiteRepository(db *gorm.DB) interfaces.PrerequisiteRepository {
+	return &prerequisiteRepository{db: db}
+}
+
+func (r *prerequisiteRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, r.db, fn)
+}
+
+// LockGraph: у графа нет своей строки, поэтому блокируется строка пользователя
+func (r *prerequisiteRepository) LockGraph(ctx context.Context, userID uuid.UUID) error {
+	var locked []uuid.UUID
+	return dbFor(ctx, r.db).
+		Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).
+		Pluck("id", &locked).Error
+}
+
+// Create добавляет ребро (или обновляет required_level, если ребро уже есть)
+func (r *prerequisiteRepository) Create(ctx context.Context, link *models.NotePrerequisite) error {
+	return dbFor(ctx, r.db).Exec(`
+        INSERT INTO note_prerequisites (note_id, prerequisite_id, user_id, required_level, created_at)
+        VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+        ON CONFLICT (note_id, prerequisite_id) DO UPDATE SET required_level = excluded.required_level
+    `, link.NoteID, link.PrerequisiteID, link.UserID, link.RequiredLevel).Error
+}
+
+func (r *prerequisiteRepository) Delete(ctx context.Context, userID, noteID, prerequisiteID uuid.UUID) error {
+	result := dbFor(ctx, r.db).
+		Where("note_id = ? AND prerequisite_id = ? AND user_id = ?", noteID, prerequisiteID, userID).
+		Delete(&models.NotePrerequisite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// ListByNote возвращает прямые пререквизиты заметки вместе с самими заметками-пререквизитами
+func (r *prerequisiteRepository) ListByNote(ctx context.Context, userID, noteID uuid.UUID) ([]models.NotePrerequisite, error) {
+	var links []models.NotePrerequisite
+	err := dbFor(ctx, r.db).
+		Where("note_id = ? AND user_id = ?", noteID, userID).
+		Preload("Prerequisite").
+		Order("created_at ASC").
+		Find(&links).Error
+	return links, err
+}
+
+// ListByUser возвращает весь граф пользователя (для проверки циклов)
+func (r *prerequisiteRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.NotePrerequisite, error) {
+	var links []models.NotePrerequisite
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Find(&links).Error
+	return links, err
+}
+
+// ListByFolder возвращает рёбра, у которых хотя бы один конец лежит в папке
+func (r *prerequisiteRepository) ListByFolder(ctx context.Context, userID, folderID uuid.UUID) ([]models.NotePrerequisite, error) {
+	var links []models.NotePrerequisite
+	folderNotes := dbFor(ctx, r.db).Model(&models.Note{}).Select("id").Where("folder_id = ? AND user_id = ?", folderID, userID)
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Where("note_id IN (?) OR prerequisite_id IN (?)", folderNotes, folderNotes).
+		Preload("Note").
+		Preload("Prerequisite").
+		Find(&links).Error
+	return links, err
+}
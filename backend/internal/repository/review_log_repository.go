@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type reviewLogRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewLogRepository(db *gorm.DB) interfaces.ReviewLogRepository {
+	return &reviewLogRepository{db: db}
+}
+
+func (r *reviewLogRepository) Create(ctx context.Context, log *models.ReviewLog) error {
+	return dbFor(ctx, r.db).Create(log).Error
+}
+
+// GetRecallStats агрегирует журнал повторений по уровню памяти до ответа
+func (r *reviewLogRepository) GetRecallStats(ctx context.Context, userID uuid.UUID) ([]interfaces.RecallStat, error) {
+	var stats []interfaces.RecallStat
+	err := dbFor(ctx, r.db).
+		Model(&models.ReviewLog{}).
+		Select("level_before, COUNT(*) AS total, SUM(CASE WHEN remembered THEN 1 ELSE 0 END) AS remembered").
+		Where("user_id = ?", userID).
+		Group("level_before").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (r *reviewLogRepository) CountByFolderSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]interfaces.FolderReviewCount, error) {
+	var counts []interfaces.FolderReviewCount
+	err := dbFor(ctx, r.db).
+		Table("review_logs l").
+		Select(`n.folder_id AS folder_id,
+			COUNT(*) AS total,
+			SUM(CASE WHEN NOT EXISTS (
+				SELECT 1 FROM review_logs e WHERE e.note_id = l.note_id AND e.reviewed_at < l.reviewed_at
+			) THEN 1 ELSE 0 END) AS new_count`).
+		Joins("JOIN notes n ON n.id = l.note_id").
+		Where("l.user_id = ? AND l.reviewed_at >= ?", userID, since).
+		Group("n.folder_id").
+		Scan(&counts).Error
+	return counts, err
+}
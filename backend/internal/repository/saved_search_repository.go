@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type savedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) interfaces.SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) Create(ctx context.Context, search *models.SavedSearch) error {
+	return dbFor(ctx, r.db).Create(search).Error
+}
+
+func (r *savedSearchRepository) GetByID(ctx context.Context, userID, searchID uuid.UUID) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	err := dbFor(ctx, r.db).
+		Where("id = ? AND user_id = ?", searchID, userID).
+		First(&search).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &search, err
+}
+
+func (r *savedSearchRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&searches).Error
+	return searches, err
+}
+
+func (r *savedSearchRepository) Update(ctx context.Context, search *models.SavedSearch) error {
+	return dbFor(ctx, r.db).Save(search).Error
+}
+
+func (r *savedSearchRepository) Delete(ctx context.Context, userID, searchID uuid.UUID) error {
+	result := dbFor(ctx, r.db).
+		Where("id = ? AND user_id = ?", searchID, userID).
+		Delete(&models.SavedSearch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *savedSearchRepository) ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	var count int64
+	query := dbFor(ctx, r.db).
+		Model(&models.SavedSearch{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// tagSubtree — подзапрос ID тегов tagIDs вместе со всеми их потомками по пути вида a::b::c.
+// Потомки сравниваются по префиксу без учёта регистра; SUBSTR, а не LIKE,
+// чтобы символы % и _ в именах тегов не работали как шаблон.
+func tagSubtree(db *gorm.DB, userID string, tagIDs []string) *gorm.DB {
+	return db.Table("tags AS d").
+		Select("d.id").
+		Joins("JOIN tags p ON p.user_id = d.user_id").
+		Where(`p.id IN ? AND p.user_id = ? AND (d.id = p.id OR (d.deleted_at IS NULL AND
+			LOWER(SUBSTR(d.name, 1, LENGTH(p.name) + 2)) = LOWER(p.name) || '::'))`, tagIDs, userID)
+}
@@ -3,10 +3,12 @@ package repository
 import (
     "context"
     "errors"
+    "unicode/utf8"
 
     "github.com/google/uuid"
     "gorm.io/gorm"
 
+    apperrors "valibibe/internal/errors"
     "valibibe/internal/models"
     "valibibe/internal/repository/interfaces"
 )
@@ -19,13 +21,17 @@ func NewTagRepository(db *gorm.DB) interfaces.TagRepository {
     return &tagRepository{db: db}
 }
 
+func (r *tagRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    return withinTransaction(ctx, r.db, fn)
+}
+
 func (r *tagRepository) Create(ctx context.Context, tag *models.Tag) error {
-    return r.db.WithContext(ctx).Create(tag).Error
+    return dbFor(ctx, r.db).Create(tag).Error
 }
 
 func (r *tagRepository) GetByID(ctx context.Context, userID, tagID uuid.UUID) (*models.Tag, error) {
     var tag models.Tag
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
         Where("id = ? AND user_id = ?", tagID, userID).
         First(&tag).Error
 
@@ -36,24 +42,49 @@ func (r *tagRepository) GetByID(ctx context.Context, userID, tagID uuid.UUID) (*
     return &tag, err
 }
 
+// Update сохраняет имя и увеличивает версию тега; если версия в базе уже другая,
+// возвращает apperrors.ErrVersionConflict. При смене пути потомки тега (старое_имя::...)
+// переименовываются в той же транзакции
 func (r *tagRepository) Update(ctx context.Context, tag *models.Tag) error {
-    result := r.db.WithContext(ctx).
-        Model(&models.Tag{}).
-        Where("id = ? AND user_id = ?", tag.ID, tag.UserID).
-        Updates(map[string]interface{}{
-            "name": tag.Name,
-        })
-
-    if result.RowsAffected == 0 {
-        return errors.New("tag not found or not owned by user")
+    err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+        var current models.Tag
+        err := tx.Where("id = ? AND user_id = ?", tag.ID, tag.UserID).First(&current).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return apperrors.ErrVersionConflict
+        }
+        if err != nil {
+            return err
+        }
+
+        result := tx.Model(&models.Tag{}).
+            Where("id = ? AND user_id = ? AND version = ?", tag.ID, tag.UserID, tag.Version).
+            Updates(map[string]interface{}{
+                "name":    tag.Name,
+                "version": tag.Version + 1,
+            })
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return apperrors.ErrVersionConflict
+        }
+
+        if current.Name == tag.Name {
+            return nil
+        }
+        return renameDescendants(tx, tag.UserID, current.Name, tag.Name)
+    })
+    if err != nil {
+        return err
     }
 
-    return result.Error
+    tag.Version++
+    return nil
 }
 
 func (r *tagRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Tag, error) {
     var tags []models.Tag
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
         Where("user_id = ?", userID).
         Order("created_at DESC").
         Find(&tags).Error
@@ -61,7 +92,7 @@ func (r *tagRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]mod
 }
 
 func (r *tagRepository) Delete(ctx context.Context, userID, tagID uuid.UUID) error {
-    result := r.db.WithContext(ctx).
+    result := dbFor(ctx, r.db).
         Where("id = ? AND user_id = ?", tagID, userID).
         Delete(&models.Tag{})
 
@@ -74,7 +105,7 @@ func (r *tagRepository) Delete(ctx context.Context, userID, tagID uuid.UUID) err
 
 func (r *tagRepository) ExistsByName(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
     var count int64
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
         Model(&models.Tag{}).
         Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).
         Count(&count).Error
@@ -84,29 +115,112 @@ func (r *tagRepository) ExistsByName(ctx context.Context, userID uuid.UUID, name
 
 func (r *tagRepository) CountTagsByIDsAndUserID(ctx context.Context, tagIDs []string, userID string) (int, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Model(&models.Tag{}).
 		Where("id IN ? AND user_id = ?", tagIDs, userID).
 		Count(&count).Error
 	return int(count), err
 }
 
+// Merge работает в одной транзакции: связь заметки с target создаётся один раз,
+// даже если у заметки было несколько исходных тегов или уже был target,
+// поэтому первичный ключ (note_id, tag_id) не нарушается
+func (r *tagRepository) Merge(ctx context.Context, sources []models.Tag, target *models.Tag) (int, int, error) {
+    sourceIDs := make([]uuid.UUID, len(sources))
+    for i, source := range sources {
+        sourceIDs[i] = source.ID
+    }
+
+    var moved, total int64
+    err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+        for _, source := range sources {
+            if err := renameDescendants(tx, target.UserID, source.Name, target.Name); err != nil {
+                return err
+            }
+        }
+
+        if err := tx.Model(&models.NoteTag{}).Where("tag_id IN ?", sourceIDs).Count(&total).Error; err != nil {
+            return err
+        }
+
+        // у заметок меняется набор тегов — как и при массовых операциях, растёт версия
+        if err := tx.Model(&models.Note{}).
+            Where("id IN (?)", tx.Table("note_tags").Select("note_id").Where("tag_id IN ?", sourceIDs)).
+            Update("version", gorm.Expr("version + 1")).Error; err != nil {
+            return err
+        }
+
+        // id target берётся из JOIN, а не параметром в списке SELECT: PostgreSQL
+        // вывел бы тип такого параметра как text, а колонка tag_id — uuid
+        result := tx.Exec(`
+            INSERT INTO note_tags (note_id, tag_id)
+            SELECT nt.note_id, t.id FROM note_tags nt
+            JOIN tags t ON t.id = ?
+            WHERE nt.tag_id IN ? AND nt.note_id NOT IN (SELECT note_id FROM note_tags WHERE tag_id = t.id)
+            GROUP BY nt.note_id, t.id
+        `, target.ID, sourceIDs)
+        if result.Error != nil {
+            return result.Error
+        }
+        moved = result.RowsAffected
+
+        if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&models.NoteTag{}).Error; err != nil {
+            return err
+        }
+        return tx.Where("id IN ? AND user_id = ?", sourceIDs, target.UserID).Delete(&models.Tag{}).Error
+    })
+    if err != nil {
+        return 0, 0, err
+    }
+    return int(moved), int(total - moved), nil
+}
+
+func (r *tagRepository) Rename(ctx context.Context, userID uuid.UUID, names map[uuid.UUID]string) error {
+    return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+        for id, name := range names {
+            if err := tx.Model(&models.Tag{}).
+                Where("id = ? AND user_id = ?", id, userID).
+                Updates(map[string]interface{}{
+                    "name":    name,
+                    "version": gorm.Expr("version + 1"),
+                }).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// renameDescendants заменяет префикс oldName:: на newName:: у потомков тега, включая
+// теги в корзине — иначе восстановленный потомок вернулся бы со старым путём.
+// Регистр сравнивается через LOWER: PostgreSQL сворачивает и не-ASCII буквы,
+// а SQLite в тестах — только латиницу, поэтому тесты проверяют регистр на латинских именах
+func renameDescendants(tx *gorm.DB, userID uuid.UUID, oldName, newName string) error {
+    prefix := oldName + "::"
+    return tx.Unscoped().Model(&models.Tag{}).
+        Where("user_id = ? AND LOWER(SUBSTR(name, 1, ?)) = LOWER(?)", userID, utf8.RuneCountInString(prefix), prefix).
+        Updates(map[string]interface{}{
+            "name":    gorm.Expr("? || SUBSTR(name, ?)", newName, utf8.RuneCountInString(oldName)+1),
+            "version": gorm.Expr("version + 1"),
+        }).Error
+}
+
 // ------------------- связи тегов и заметок -------------------
 
 func (r *tagRepository) AttachToNote(ctx context.Context, noteID, tagID uuid.UUID) error {
     relation := models.NoteTag{NoteID: noteID, TagID: tagID}
-    return r.db.WithContext(ctx).Create(&relation).Error
+    return dbFor(ctx, r.db).Create(&relation).Error
 }
 
 func (r *tagRepository) DetachFromNote(ctx context.Context, noteID, tagID uuid.UUID) error {
-    return r.db.WithContext(ctx).
+    return dbFor(ctx, r.db).
         Where("note_id = ? AND tag_id = ?", noteID, tagID).
         Delete(&models.NoteTag{}).Error
 }
 
 func (r *tagRepository) ListTagsByNote(ctx context.Context, noteID uuid.UUID) ([]models.Tag, error) {
     var tags []models.Tag
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
         Joins("JOIN note_tags nt ON nt.tag_id = tags.id").
         Where("nt.note_id = ?", noteID).
         Find(&tags).Error
@@ -115,7 +229,7 @@ func (r *tagRepository) ListTagsByNote(ctx context.Context, noteID uuid.UUID) ([
 
 func (r *tagRepository) ListNotesByTag(ctx context.Context, userID, tagID uuid.UUID) ([]models.Note, error) {
     var notes []models.Note
-    err := r.db.WithContext(ctx).
+    err := dbFor(ctx, r.db).
         Joins("JOIN note_tags nt ON nt.note_id = notes.id").
         Where("nt.tag_id = ? AND notes.user_id = ?", tagID, userID).
         Find(&notes).Error
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey — ключ контекста, под которым лежит открытая транзакция
+type txKey struct{}
+
+// dbFor возвращает транзакцию из ctx (см. withinTransaction) или обычное подключение.
+// Все методы репозиториев берут соединение через него, поэтому вызовы внутри
+// WithinTransaction попадают в одну транзакцию, даже если это разные репозитории
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// withinTransaction выполняет fn в транзакции и передаёт ей контекст с этой транзакцией;
+// вложенный вызов открывает точку сохранения во внешней транзакции
+func withinTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return dbFor(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) interfaces.TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) ListDeletedNotes(ctx context.Context, userID uuid.UUID) ([]models.Note, error) {
+	var notes []models.Note
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *trashRepository) ListDeletedFolders(ctx context.Context, userID uuid.UUID) ([]models.Folder, error) {
+	var folders []models.Folder
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&folders).Error
+	return folders, err
+}
+
+func (r *trashRepository) ListDeletedTags(ctx context.Context, userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *trashRepository) GetDeletedNote(ctx context.Context, userID, id uuid.UUID) (*models.Note, error) {
+	var note models.Note
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &note, err
+}
+
+func (r *trashRepository) GetDeletedFolder(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	var folder models.Folder
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&folder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &folder, err
+}
+
+func (r *trashRepository) GetDeletedTag(ctx context.Context, userID, id uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := dbFor(ctx, r.db).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &tag, err
+}
+
+// RestoreNote восстанавливает заметку вместе с цепочкой удалённых родительских папок.
+// Связи с тегами при мягком удалении не трогаются, поэтому возвращаются сами.
+func (r *trashRepository) RestoreNote(ctx context.Context, note *models.Note) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if note.FolderID != nil {
+			if err := restoreFolderChain(tx, note.UserID, *note.FolderID); err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&models.Note{}).
+			Where("id = ?", note.ID).
+			Update("deleted_at", nil).Error
+	})
+}
+
+// RestoreFolder восстанавливает папку, цепочку её родителей и всё,
+// что было удалено вместе с ней (вложенные папки и заметки с тем же deleted_at)
+func (r *trashRepository) RestoreFolder(ctx context.Context, folder *models.Folder) error {
+	deletedAt := folder.DeletedAt.Time
+
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if folder.ParentID != nil {
+			if err := restoreFolderChain(tx, folder.UserID, *folder.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := checkFolderNameFree(tx, folder); err != nil {
+			return err
+		}
+
+		var ids []string
+		err := tx.Raw(`
+			WITH RECURSIVE subfolders AS (
+				SELECT id FROM folders WHERE id = ?
+				UNION
+				SELECT f.id FROM folders f
+				INNER JOIN subfolders sf ON sf.id = f.parent_id
+				WHERE f.deleted_at = ?
+			)
+			SELECT id FROM subfolders
+		`, folder.ID, deletedAt).Scan(&ids).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Note{}).
+			Where("folder_id IN ? AND deleted_at = ?", ids, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Folder{}).
+			Where("id IN ?", ids).
+			Update("deleted_at", nil).Error
+	})
+}
+
+func (r *trashRepository) RestoreTag(ctx context.Context, tag *models.Tag) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.Tag{}).
+			Where("user_id = ? AND LOWER(name) = LOWER(?)", tag.UserID, tag.Name).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.ErrRestoreConflict
+		}
+		return tx.Unscoped().Model(&models.Tag{}).
+			Where("id = ?", tag.ID).
+			Update("deleted_at", nil).Error
+	})
+}
+
+// Purge удаляет просроченные записи корзины вместе со связями note_tags и note_links.
+// Ссылки на удалённые заметки остаются висячими. Заметки, которые ещё ссылаются на
+// удаляемую папку (живые или в корзине с более поздним сроком), остаются без папки.
+func (r *trashRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		expiredNotes := tx.Unscoped().Model(&models.Note{}).
+			Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		expiredTags := tx.Unscoped().Model(&models.Tag{}).
+			Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+
+		if err := tx.Where("note_id IN (?) OR tag_id IN (?)", expiredNotes, expiredTags).
+			Delete(&models.NoteTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("source_id IN (?)", expiredNotes).Delete(&models.NoteLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NoteLink{}).Where("target_id IN (?)", expiredNotes).
+			Update("target_id", nil).Error; err != nil {
+			return err
+		}
+
+		expiredFolders := tx.Unscoped().Model(&models.Folder{}).
+			Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if err := tx.Unscoped().Model(&models.Note{}).
+			Where("folder_id IN (?) AND (deleted_at IS NULL OR deleted_at >= ?)", expiredFolders, before).
+			Updates(map[string]interface{}{
+				"folder_id": nil,
+				"version":   gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.Note{}, &models.Tag{}, &models.Folder{}} {
+			result := tx.Unscoped().
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+				Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// restoreFolderChain поднимается от папки к корню и снимает пометку удаления
+// с каждой удалённой папки. Живая папка означает, что выше всё уже восстановлено.
+func restoreFolderChain(tx *gorm.DB, userID, folderID uuid.UUID) error {
+	current := &folderID
+	for current != nil {
+		var folder models.Folder
+		err := tx.Unscoped().
+			Where("id = ? AND user_id = ?", *current, userID).
+			First(&folder).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // папку уже удалили окончательно
+		}
+		if err != nil {
+			return err
+		}
+		if !folder.DeletedAt.Valid {
+			return nil
+		}
+
+		if err := checkFolderNameFree(tx, &folder); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Folder{}).
+			Where("id = ?", folder.ID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		current = folder.ParentID
+	}
+	return nil
+}
+
+// checkFolderNameFree проверяет, что среди живых соседей нет папки с тем же именем
+func checkFolderNameFree(tx *gorm.DB, folder *models.Folder) error {
+	query := tx.Model(&models.Folder{}).
+		Where("user_id = ? AND name = ? AND id <> ?", folder.UserID, folder.Name, folder.ID)
+	if folder.ParentID != nil {
+		query = query.Where("parent_id = ?", *folder.ParentID)
+	} else {
+		query = query.Where("parent_id IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrRestoreConflict
+	}
+	return nil
+}
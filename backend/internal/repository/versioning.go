@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apperrors "valibibe/internal/errors"
+)
+
+// updateVersioned сохраняет все поля model (без связей), если version в базе совпадает
+// с *version, и увеличивает версию. Запись с другой версией не трогается:
+// возвращается apperrors.ErrVersionConflict, а *version остаётся прежней.
+// В отличие от Save, не превращается в INSERT, когда строка не обновилась.
+func updateVersioned(db *gorm.DB, model interface{}, version *int) error {
+	expected := *version
+	*version = expected + 1
+
+	result := db.Model(model).
+		Where("version = ?", expected).
+		Select("*").
+		Omit(clause.Associations, "id", "user_id", "created_at", "deleted_at").
+		Updates(model)
+	if result.Error != nil {
+		*version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = expected
+		return apperrors.ErrVersionConflict
+	}
+	return nil
+}
@@ -12,15 +12,30 @@ import (
 	"valibibe/internal/service"
 )
 
-func SetupRoutes(r *gin.Engine,
-	tokenService service.TokenService,
-	authController *controller.AuthController,
-	noteController *controller.NoteController,
-	folderController *controller.FolderController,
-	tagController *controller.TagController,
-	noteTagController *controller.NoteTagController,
-	reviewSessionController *controller.ReviewSessionController,
-) {
+// Controllers — контроллеры, чьи маршруты регистрирует SetupRoutes.
+// Незаполненные поля допустимы, если соответствующие маршруты не вызываются (например, в тестах)
+type Controllers struct {
+	Auth          *controller.AuthController
+	Note          *controller.NoteController
+	Folder        *controller.FolderController
+	Tag           *controller.TagController
+	NoteTag       *controller.NoteTagController
+	ReviewSession *controller.ReviewSessionController
+	Exam          *controller.ExamController
+	Prerequisite  *controller.PrerequisiteController
+	Stats         *controller.StatsController
+	Trash         *controller.TrashController
+	NoteRevision  *controller.NoteRevisionController
+	SavedSearch   *controller.SavedSearchController
+	NoteLink      *controller.NoteLinkController
+	Graph         *controller.GraphController
+	NoteType      *controller.NoteTypeController
+	Duplicate     *controller.DuplicateController
+	NoteBulk      *controller.NoteBulkController
+	Copy          *controller.CopyController
+}
+
+func SetupRoutes(r *gin.Engine, tokenService service.TokenService, c Controllers) {
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -67,66 +82,163 @@ func SetupRoutes(r *gin.Engine,
 	// Auth routes
 	auth := r.Group("/auth")
 	{
-		auth.POST("/register", authController.RegisterUserHandler)
-		auth.POST("/login", authController.LoginUserHandler)
-		auth.GET("/me", middleware.AuthMiddleware(tokenService), authController.MeHandler)
-		auth.POST("/logout", middleware.AuthMiddleware(tokenService), authController.LogoutHandler)
+		auth.POST("/register", c.Auth.RegisterUserHandler)
+		auth.POST("/login", c.Auth.LoginUserHandler)
+		auth.GET("/me", middleware.AuthMiddleware(tokenService), c.Auth.MeHandler)
+		auth.POST("/logout", middleware.AuthMiddleware(tokenService), c.Auth.LogoutHandler)
 	}
 
 	// Notes
 	notes := r.Group("/notes")
 	notes.Use(middleware.AuthMiddleware(tokenService))
 	{
-		notes.POST("", noteController.CreateNote)
-		notes.GET("", noteController.GetAllNotes)
-		notes.GET("/:id", noteController.GetNoteByID)
-		notes.PUT("/:id", noteController.UpdateNote)
-		notes.DELETE("/:id", noteController.DeleteNote)
-		notes.POST("/:id/archive", noteController.ArchiveNote)
-		notes.POST("/:id/unarchive", noteController.UnArchiveNote)
-		notes.POST("/:id/review", noteController.ReviewNoteHandler)
-		notes.POST("/:id/folders", noteController.AssignFolder)
-		notes.DELETE("/:id/folders/:folderId", noteController.RemoveFolder)
-		notes.POST("/batch/folders", noteController.BatchAssignFolder)
+		notes.POST("", c.Note.CreateNote)
+		notes.GET("", c.Note.GetAllNotes)
+		notes.GET("/:id", c.Note.GetNoteByID)
+		notes.PUT("/:id", c.Note.UpdateNote)
+		notes.PATCH("/:id", c.Note.PatchNote)
+		notes.DELETE("/:id", c.Note.DeleteNote)
+		notes.POST("/:id/archive", c.Note.ArchiveNote)
+		notes.POST("/:id/unarchive", c.Note.UnArchiveNote)
+		notes.POST("/:id/move", c.Note.MoveNote)
+		notes.POST("/:id/review", c.Note.ReviewNoteHandler)
+		notes.POST("/:id/folders", c.Note.AssignFolder)
+		notes.DELETE("/:id/folders/:folderId", c.Note.RemoveFolder)
+		notes.POST("/batch/folders", c.Note.BatchAssignFolder)
 
 		// Note-Tag relationships
-		notes.POST("/:id/tags/:tagId", noteTagController.AddTag)
-		notes.DELETE("/:id/tags/:tagId", noteTagController.RemoveTag)
+		notes.POST("/:id/tags/:tagId", c.NoteTag.AddTag)
+		notes.DELETE("/:id/tags/:tagId", c.NoteTag.RemoveTag)
+
+		// Prerequisites
+		notes.POST("/:id/prerequisites", c.Prerequisite.AddPrerequisite)
+		notes.GET("/:id/prerequisites", c.Prerequisite.GetPrerequisites)
+		notes.DELETE("/:id/prerequisites/:prerequisiteId", c.Prerequisite.RemovePrerequisite)
+
+		// Revisions
+		notes.GET("/:id/revisions", c.NoteRevision.ListRevisions)
+		notes.GET("/:id/revisions/diff", c.NoteRevision.DiffRevisions)
+		notes.GET("/:id/revisions/:rev", c.NoteRevision.GetRevision)
+		notes.POST("/:id/revisions/:rev/restore", c.NoteRevision.RestoreRevision)
+
+		// Wiki links
+		notes.GET("/links/dangling", c.NoteLink.ListDangling)
+		notes.GET("/:id/links", c.NoteLink.ListLinks)
+		notes.GET("/:id/backlinks", c.NoteLink.ListBacklinks)
+
+		// Duplicates
+		notes.GET("/duplicates", c.Duplicate.ListDuplicates)
+		notes.POST("/:id/merge", c.Duplicate.MergeNotes)
+
+		// Bulk operations
+		notes.POST("/bulk", c.NoteBulk.BulkNotes)
+
+		// Copies
+		notes.POST("/:id/duplicate", c.Copy.DuplicateNote)
 	}
 
 	// Folders
 	folders := r.Group("/folders")
 	folders.Use(middleware.AuthMiddleware(tokenService))
 	{
-		folders.POST("", folderController.CreateFolder)
-		folders.GET("/tree", folderController.GetFolderTree)
-		folders.PUT("/:id", folderController.UpdateFolder)
-		folders.DELETE("/:id", folderController.DeleteFolder)
+		folders.POST("", c.Folder.CreateFolder)
+		folders.GET("/tree", c.Folder.GetFolderTree)
+		folders.GET("/by-path", c.Folder.GetFolderByPath)
+		folders.POST("/ensure-path", c.Folder.EnsureFolderPath)
+		folders.GET("/:id/path", c.Folder.GetFolderPath)
+		folders.GET("/:id/settings", c.Folder.GetFolderSettings)
+		folders.PUT("/:id/settings", c.Folder.UpdateFolderSettings)
+		folders.GET("/:id/settings/effective", c.Folder.GetEffectiveFolderSettings)
+		folders.PUT("/:id", c.Folder.UpdateFolder)
+		folders.DELETE("/:id", c.Folder.DeleteFolder)
+		folders.GET("/:id/delete-preview", c.Folder.PreviewDeleteFolder)
+		folders.POST("/:id/move", c.Folder.MoveFolder)
+		folders.GET("/:id/prerequisite-graph", c.Prerequisite.GetFolderGraph)
+		folders.POST("/:id/copy", c.Copy.CopyFolder)
 	}
 
 	// Tags
 	tags := r.Group("/tags")
 	tags.Use(middleware.AuthMiddleware(tokenService))
 	{
-		tags.POST("", tagController.CreateTag)
-		tags.GET("", tagController.ListTags)
-		tags.GET("/:id", tagController.GetTag)
-		tags.PUT("/:id", tagController.UpdateTag)
-		tags.DELETE("/:id", tagController.DeleteTag)
+		tags.POST("", c.Tag.CreateTag)
+		tags.GET("", c.Tag.ListTags)
+		tags.GET("/tree", c.Tag.GetTagTree)
+		tags.POST("/merge", c.Tag.MergeTags)
+		tags.POST("/bulk-rename", c.Tag.BulkRenameTags)
+		tags.GET("/:id", c.Tag.GetTag)
+		tags.PUT("/:id", c.Tag.UpdateTag)
+		tags.DELETE("/:id", c.Tag.DeleteTag)
 	}
 
 	// Note-Tag batch operations
 	noteTags := r.Group("/notes/tags")
 	noteTags.Use(middleware.AuthMiddleware(tokenService))
 	{
-		noteTags.POST("/batch", noteTagController.AddTagsBatch)
+		noteTags.POST("/batch", c.NoteTag.AddTagsBatch)
 	}
 
 	// Review Sessions
 	review := r.Group("/review")
 	review.Use(middleware.AuthMiddleware(tokenService))
 	{
-		review.POST("/sessions", reviewSessionController.CreateReviewSession)
+		review.POST("/sessions", c.ReviewSession.CreateReviewSession)
+	}
+
+	// Exams
+	exams := r.Group("/exams")
+	exams.Use(middleware.AuthMiddleware(tokenService))
+	{
+		exams.POST("", c.Exam.StartExam)
+		exams.GET("", c.Exam.ListExams)
+		exams.GET("/:id", c.Exam.GetExam)
+		exams.POST("/:id/answers", c.Exam.AnswerQuestion)
+		exams.POST("/:id/submit", c.Exam.SubmitExam)
+	}
+
+	// Stats
+	stats := r.Group("/stats")
+	stats.Use(middleware.AuthMiddleware(tokenService))
+	{
+		stats.POST("/simulate", c.Stats.Simulate)
+	}
+
+	// Trash
+	trash := r.Group("/trash")
+	trash.Use(middleware.AuthMiddleware(tokenService))
+	{
+		trash.GET("", c.Trash.ListTrash)
+		trash.POST("/:id/restore", c.Trash.RestoreItem)
+	}
+
+	// Knowledge graph
+	graph := r.Group("/graph")
+	graph.Use(middleware.AuthMiddleware(tokenService))
+	{
+		graph.GET("", c.Graph.GetGraph)
+	}
+
+	// Saved searches
+	savedSearches := r.Group("/saved-searches")
+	savedSearches.Use(middleware.AuthMiddleware(tokenService))
+	{
+		savedSearches.POST("", c.SavedSearch.CreateSavedSearch)
+		savedSearches.GET("", c.SavedSearch.ListSavedSearches)
+		savedSearches.GET("/:id", c.SavedSearch.GetSavedSearch)
+		savedSearches.PUT("/:id", c.SavedSearch.UpdateSavedSearch)
+		savedSearches.DELETE("/:id", c.SavedSearch.DeleteSavedSearch)
+		savedSearches.GET("/:id/notes", c.SavedSearch.RunSavedSearch)
+	}
+
+	// Note types
+	noteTypes := r.Group("/note-types")
+	noteTypes.Use(middleware.AuthMiddleware(tokenService))
+	{
+		noteTypes.POST("", c.NoteType.CreateNoteType)
+		noteTypes.GET("", c.NoteType.ListNoteTypes)
+		noteTypes.GET("/:id", c.NoteType.GetNoteType)
+		noteTypes.PUT("/:id", c.NoteType.UpdateNoteType)
+		noteTypes.DELETE("/:id", c.NoteType.DeleteNoteType)
 	}
 
 }
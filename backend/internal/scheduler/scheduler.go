@@ -0,0 +1,185 @@
+// Package scheduler содержит алгоритм интервальных повторений.
+// Его используют и ответы на карточки, и симулятор нагрузки, чтобы цифры совпадали.
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	MinLevel = 0
+	MaxLevel = 100
+	// BandWidth — ширина диапазона memory_level, для которого действует один интервал
+	BandWidth = 20
+)
+
+// Алгоритмы интервалов
+const (
+	// AlgorithmLevels — интервал берётся из IntervalDays по диапазону memory_level
+	AlgorithmLevels = "levels"
+	// AlgorithmExponential — интервал удваивается с каждым успешным ответом: 1, 2, 4, 8… дней
+	AlgorithmExponential = "exponential"
+)
+
+const (
+	// DefaultRetention — доля вспоминания, на которую рассчитаны IntervalDays
+	DefaultRetention = 0.9
+	MinRetention     = 0.7
+	MaxRetention     = 0.99
+	// MaxLearningSteps и MaxLearningStepMinutes ограничивают шаги обучения
+	MaxLearningSteps       = 10
+	MaxLearningStepMinutes = 24 * 60
+	// maxDoublings ограничивает рост интервала у exponential (2^15 дней)
+	maxDoublings = 15
+)
+
+// Settings — параметры планировщика и дневные лимиты
+type Settings struct {
+	// LevelStep — насколько растёт memory_level после успешного ответа
+	LevelStep int `json:"level_step" example:"20"`
+	// IntervalDays — интервал до следующего повторения для диапазонов 0-19, 20-39, 40-59, 60-79, 80-100
+	IntervalDays []int `json:"interval_days" example:"1,3,5,10,30"`
+	// MaxIntervalDays ограничивает интервал сверху (0 — без ограничения)
+	MaxIntervalDays int `json:"max_interval_days" example:"0"`
+	// NewPerDay — сколько новых карточек вводится в день (0 — без ограничения)
+	NewPerDay int `json:"new_per_day" example:"20"`
+	// ReviewsPerDay — сколько повторений делается в день (0 — без ограничения)
+	ReviewsPerDay int `json:"reviews_per_day" example:"200"`
+	// Algorithm — алгоритм интервалов: levels или exponential
+	Algorithm string `json:"algorithm" example:"levels"`
+	// TargetRetention — желаемая доля вспоминания; интервалы умножаются на ln(R)/ln(0.9)
+	TargetRetention float64 `json:"target_retention" example:"0.9"`
+	// LearningSteps — шаги обучения в минутах для новых и забытых карточек (пусто — без шагов).
+	// Карточка на шаге i держит memory_level = i и после последнего шага выходит на обычные интервалы
+	LearningSteps []int `json:"learning_steps" example:"1,10"`
+}
+
+// Default возвращает настройки по умолчанию — их получают папки без собственных настроек
+// и симулятор нагрузки. Интервалы применяются при каждом повторении; NewPerDay и ReviewsPerDay
+// ограничивают только сессии повторения (ReviewSessionService) и прогноз симулятора
+func Default() Settings {
+	return Settings{
+		LevelStep:       20,
+		IntervalDays:    []int{1, 3, 5, 10, 30},
+		MaxIntervalDays: 0,
+		NewPerDay:       20,
+		ReviewsPerDay:   200,
+		Algorithm:       AlgorithmLevels,
+		TargetRetention: DefaultRetention,
+		LearningSteps:   []int{},
+	}
+}
+
+// Validate проверяет, что настройки непротиворечивы
+func (s Settings) Validate() error {
+	if s.LevelStep <= 0 || s.LevelStep > MaxLevel {
+		return errors.New("level_step must be between 1 and 100")
+	}
+	if len(s.IntervalDays) != Bands() {
+		return errors.New("interval_days must contain exactly 5 values")
+	}
+	for _, d := range s.IntervalDays {
+		if d <= 0 {
+			return errors.New("interval_days must be positive")
+		}
+	}
+	if s.MaxIntervalDays < 0 || s.NewPerDay < 0 || s.ReviewsPerDay < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if s.Algorithm != AlgorithmLevels && s.Algorithm != AlgorithmExponential {
+		return errors.New("algorithm must be levels or exponential")
+	}
+	if s.TargetRetention < MinRetention || s.TargetRetention > MaxRetention {
+		return errors.New("target_retention must be between 0.7 and 0.99")
+	}
+	// Уровни шагов не должны доходить до первого обычного уровня
+	if len(s.LearningSteps) > MaxLearningSteps || len(s.LearningSteps) > s.LevelStep {
+		return errors.New("learning_steps must contain at most 10 values and fewer than level_step")
+	}
+	for _, m := range s.LearningSteps {
+		if m <= 0 || m > MaxLearningStepMinutes {
+			return errors.New("learning_steps must be between 1 and 1440 minutes")
+		}
+	}
+	return nil
+}
+
+// Bands — количество диапазонов memory_level
+func Bands() int {
+	return MaxLevel / BandWidth
+}
+
+// Band возвращает номер диапазона для уровня памяти
+func Band(level int) int {
+	band := level / BandWidth
+	if band >= Bands() {
+		band = Bands() - 1
+	}
+	if band < 0 {
+		band = 0
+	}
+	return band
+}
+
+// Next вычисляет новый уровень памяти и интервал в днях.
+// При забывании карточка сбрасывается на 0 и снимается с расписания (scheduled = false).
+// Шаги обучения короче дня, поэтому Next (и симулятор) их не учитывает — см. NextReviewAt.
+func (s Settings) Next(level int, remembered bool) (newLevel, intervalDays int, scheduled bool) {
+	if !remembered {
+		return MinLevel, 0, false
+	}
+
+	newLevel = level + s.LevelStep
+	if newLevel > MaxLevel {
+		newLevel = MaxLevel
+	}
+
+	switch s.Algorithm {
+	case AlgorithmExponential:
+		doublings := (newLevel+s.LevelStep-1)/s.LevelStep - 1
+		if doublings > maxDoublings {
+			doublings = maxDoublings
+		}
+		intervalDays = 1 << doublings
+	default:
+		intervalDays = s.IntervalDays[Band(newLevel)]
+	}
+
+	// Более высокая желаемая доля вспоминания сокращает интервалы, более низкая — растягивает
+	if s.TargetRetention > 0 && s.TargetRetention != DefaultRetention {
+		scaled := math.Round(float64(intervalDays) * math.Log(s.TargetRetention) / math.Log(DefaultRetention))
+		intervalDays = int(math.Max(1, scaled))
+	}
+	if s.MaxIntervalDays > 0 && intervalDays > s.MaxIntervalDays {
+		intervalDays = s.MaxIntervalDays
+	}
+	return newLevel, intervalDays, true
+}
+
+// NextReviewAt — дата следующего повторения после ответа в момент now (nil, если карточка сброшена).
+// С шагами обучения забытая карточка возвращается через первый шаг, а новая или забытая
+// карточка, которую вспомнили, проходит оставшиеся шаги, прежде чем получить интервал в днях.
+func (s Settings) NextReviewAt(level int, remembered bool, now time.Time) (int, *time.Time) {
+	if steps := len(s.LearningSteps); steps > 0 {
+		step := -1
+		switch {
+		case !remembered:
+			step = 0
+		case level+1 < steps:
+			step = level + 1
+		}
+		if step >= 0 {
+			t := now.Add(time.Duration(s.LearningSteps[step]) * time.Minute)
+			return step, &t
+		}
+	}
+
+	newLevel, days, scheduled := s.Next(level, remembered)
+	if !scheduled {
+		return newLevel, nil
+	}
+	t := now.AddDate(0, 0, days)
+	return newLevel, &t
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/utils"
+)
+
+// copySuffix добавляется к заголовку копии заметки
+const copySuffix = " (copy)"
+
+type CopyService struct {
+	repo        interfaces.CopyRepository
+	noteRepo    interfaces.NoteRepository
+	folderRepo  interfaces.FolderRepository
+	linkService *NoteLinkService
+}
+
+func NewCopyService(
+	repo interfaces.CopyRepository,
+	noteRepo interfaces.NoteRepository,
+	folderRepo interfaces.FolderRepository,
+	linkService *NoteLinkService,
+) *CopyService {
+	return &CopyService{
+		repo:        repo,
+		noteRepo:    noteRepo,
+		folderRepo:  folderRepo,
+		linkService: linkService,
+	}
+}
+
+// DuplicateNote создаёт копию заметки в той же папке с теми же тегами и заголовком "… (copy)".
+// Журнал повторений и история правок не копируются; при resetScheduling копия становится новой.
+func (s *CopyService) DuplicateNote(ctx context.Context, userID, noteID string, resetScheduling bool) (*models.Note, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	clone := &models.Note{
+		UserID:       note.UserID,
+		Title:        utils.TruncateRunes(note.Title, maxNoteTitleLen-utf8.RuneCountInString(copySuffix)) + copySuffix,
+		Content:      note.Content,
+		FolderID:     note.FolderID,
+		MemoryLevel:  note.MemoryLevel,
+		Archived:     note.Archived,
+		NextReviewAt: note.NextReviewAt,
+		NoteTypeID:   note.NoteTypeID,
+		Fields:       note.Fields,
+	}
+	if resetScheduling {
+		clone.MemoryLevel = 0
+		clone.NextReviewAt = nil
+	}
+
+	// Индекс вики-ссылок копии пишется в той же транзакции, что и сама копия
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CopyNote(ctx, note.ID, clone); err != nil {
+			return err
+		}
+		return s.linkService.Sync(ctx, clone)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// CopyFolder копирует папку со всем поддеревом в targetID ("" — в корень)
+func (s *CopyService) CopyFolder(ctx context.Context, userID, folderID, targetID string, resetScheduling bool) (*dto.FolderCopyResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := s.folderRepo.GetByID(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	var target *uuid.UUID
+	if targetID != "" {
+		parent, err := s.folderRepo.GetByID(ctx, userID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("%w: target folder not found", apperrors.ErrInvalidInput)
+		}
+		target = &parent.ID
+	}
+
+	// Индекс вики-ссылок строится по тексту копий в той же транзакции, что и копирование
+	var copied *interfaces.FolderCopy
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		copied, err = s.repo.CopyFolder(ctx, uid, folder.ID, target, resetScheduling)
+		if err != nil {
+			return err
+		}
+		for i := range copied.Notes {
+			if err := s.linkService.Sync(ctx, &copied.Notes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.FolderCopyResult{
+		Folder:  *copied.Root,
+		Folders: copied.Folders,
+		Notes:   len(copied.Notes),
+	}, nil
+}
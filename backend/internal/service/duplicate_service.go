@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/dedup"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// DuplicateNoteError — заметка не создана, потому что похожа на существующие
+type DuplicateNoteError struct {
+	NoteIDs []string
+}
+
+func (e *DuplicateNoteError) Error() string {
+	return fmt.Sprintf("%s: %s", apperrors.ErrDuplicateNote.Error(), strings.Join(e.NoteIDs, ", "))
+}
+
+func (e *DuplicateNoteError) Unwrap() error {
+	return apperrors.ErrDuplicateNote
+}
+
+type DuplicateService struct {
+	repo      interfaces.NoteDuplicateRepository
+	threshold float64
+}
+
+// NewDuplicateService создаёт сервис; threshold — минимальная похожесть текста (0..1]
+func NewDuplicateService(repo interfaces.NoteDuplicateRepository, threshold float64) *DuplicateService {
+	return &DuplicateService{repo: repo, threshold: threshold}
+}
+
+// FindDuplicates возвращает ID заметок пользователя, похожих на title и content, сначала самые похожие.
+// Кандидаты выбираются из БД по ключам LSH-индекса (note_dedup_keys), а не чтением всей коллекции
+func (s *DuplicateService) FindDuplicates(ctx context.Context, userID uuid.UUID, title, content string) ([]string, error) {
+	doc := dedup.Document{Title: title, Content: content}
+	keys := dedup.Keys(doc)
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+	if err := s.refreshKeys(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	notes, err := s.repo.ListByKeys(ctx, userID, keys)
+	if err != nil {
+		return nil, err
+	}
+	index := dedup.NewIndex(s.threshold)
+	for _, note := range notes {
+		index.Add(dedup.Document{ID: note.ID.String(), Title: note.Title, Content: note.Content})
+	}
+
+	matches := index.Matches(doc)
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	return ids, nil
+}
+
+// refreshKeys пересчитывает ключи индекса для новых и изменённых с прошлого раза заметок.
+// Заметке без ключей (пустые заголовок и текст) пишется ключ 0, чтобы она не считалась устаревшей
+func (s *DuplicateService) refreshKeys(ctx context.Context, userID uuid.UUID) error {
+	stale, err := s.repo.ListStale(ctx, userID)
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+
+	noteIDs := make([]uuid.UUID, len(stale))
+	keys := make([]models.NoteDedupKey, 0, len(stale)*17)
+	for i, note := range stale {
+		noteIDs[i] = note.ID
+		noteKeys := dedup.Keys(dedup.Document{Title: note.Title, Content: note.Content})
+		if len(noteKeys) == 0 {
+			noteKeys = []int64{0}
+		}
+		for _, key := range noteKeys {
+			keys = append(keys, models.NoteDedupKey{NoteID: note.ID, Key: key, UserID: note.UserID, Version: note.Version})
+		}
+	}
+	return s.repo.ReplaceKeys(ctx, noteIDs, keys)
+}
+
+// Report группирует вероятные дубликаты по всей коллекции пользователя
+func (s *DuplicateService) Report(ctx context.Context, userID string) (*dto.DuplicateReport, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	index, notes, err := s.buildIndex(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.Note, len(notes))
+	for i := range notes {
+		byID[notes[i].ID.String()] = &notes[i]
+	}
+
+	groups := index.Groups()
+	report := &dto.DuplicateReport{Groups: make([]dto.DuplicateGroup, len(groups)), Total: len(groups)}
+	for i, g := range groups {
+		group := dto.DuplicateGroup{
+			Notes:      make([]dto.DuplicateNote, len(g.IDs)),
+			TitleMatch: g.TitleMatch,
+			Similarity: g.Similarity,
+		}
+		for j, id := range g.IDs {
+			note := byID[id]
+			group.Notes[j] = dto.DuplicateNote{
+				ID:           id,
+				Title:        note.Title,
+				MemoryLevel:  note.MemoryLevel,
+				NextReviewAt: note.NextReviewAt,
+				CreatedAt:    note.CreatedAt,
+			}
+		}
+		report.Groups[i] = group
+	}
+	return report, nil
+}
+
+// MergeNotes вливает заметки source_ids в заметку targetID: теги объединяются,
+// остаётся лучшее расписание (больший уровень памяти, при равенстве — более позднее повторение),
+// журнал повторений и входящие ссылки переходят к target, а дубликаты уходят в корзину.
+// Заголовок и текст target не меняются.
+func (s *DuplicateService) MergeNotes(ctx context.Context, userID, targetID string, input *dto.MergeNotesInput) (*models.Note, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	tid, err := uuid.Parse(targetID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	sourceIDs := make([]uuid.UUID, 0, len(input.SourceIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range input.SourceIDs {
+		sid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid source id %q", apperrors.ErrInvalidInput, id)
+		}
+		if sid == tid {
+			return nil, fmt.Errorf("%w: note cannot be merged into itself", apperrors.ErrInvalidInput)
+		}
+		if !seen[sid] {
+			seen[sid] = true
+			sourceIDs = append(sourceIDs, sid)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("%w: source_ids is required", apperrors.ErrInvalidInput)
+	}
+
+	notes, err := s.repo.ListByIDs(ctx, uid, append([]uuid.UUID{tid}, sourceIDs...))
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) != len(sourceIDs)+1 {
+		return nil, apperrors.ErrNotFound
+	}
+
+	var target *models.Note
+	best := &notes[0]
+	for i := range notes {
+		if notes[i].ID == tid {
+			target = &notes[i]
+		}
+		if betterSchedule(&notes[i], best) {
+			best = &notes[i]
+		}
+	}
+	target.MemoryLevel = best.MemoryLevel
+	target.NextReviewAt = best.NextReviewAt
+
+	if err := s.repo.Merge(ctx, target, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	merged, err := s.repo.ListByIDs(ctx, uid, []uuid.UUID{tid})
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		return nil, apperrors.ErrNotFound
+	}
+	return &merged[0], nil
+}
+
+// buildIndex строит индекс по всем заметкам пользователя (для отчёта по коллекции)
+func (s *DuplicateService) buildIndex(ctx context.Context, userID uuid.UUID) (*dedup.Index, []models.Note, error) {
+	notes, err := s.repo.ListCandidates(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index := dedup.NewIndex(s.threshold)
+	for _, note := range notes {
+		index.Add(dedup.Document{ID: note.ID.String(), Title: note.Title, Content: note.Content})
+	}
+	return index, notes, nil
+}
+
+// betterSchedule — у a расписание лучше, чем у b: выше уровень памяти или, при равном уровне, повторение позже
+func betterSchedule(a, b *models.Note) bool {
+	if a.MemoryLevel != b.MemoryLevel {
+		return a.MemoryLevel > b.MemoryLevel
+	}
+	if a.NextReviewAt == nil || b.NextReviewAt == nil {
+		return a.NextReviewAt != nil && b.NextReviewAt == nil
+	}
+	return a.NextReviewAt.After(*b.NextReviewAt)
+}
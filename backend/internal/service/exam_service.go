@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+const (
+	defaultExamLimit    = 20
+	maxExamLimit        = 100
+	defaultExamDuration = 10 * 60
+	minExamDuration     = 30
+	maxExamDuration     = 4 * 60 * 60
+)
+
+type ExamService struct {
+	examRepo      interfaces.ExamRepository
+	noteRepo      interfaces.NoteRepository
+	reviewLogRepo interfaces.ReviewLogRepository
+	folderRepo    interfaces.FolderRepository
+}
+
+func NewExamService(examRepo interfaces.ExamRepository, noteRepo interfaces.NoteRepository, reviewLogRepo interfaces.ReviewLogRepository, folderRepo interfaces.FolderRepository) *ExamService {
+	return &ExamService{
+		examRepo:      examRepo,
+		noteRepo:      noteRepo,
+		reviewLogRepo: reviewLogRepo,
+		folderRepo:    folderRepo,
+	}
+}
+
+// StartExam выбирает заметки, фиксирует порядок вопросов и дедлайн
+func (s *ExamService) StartExam(ctx context.Context, userID string, input *dto.ExamInput) (*models.Exam, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (input.FolderID == nil || *input.FolderID == "") && len(input.TagIDs) == 0 {
+		return nil, fmt.Errorf("%w: folder_id or tag_ids is required", apperrors.ErrInvalidInput)
+	}
+
+	var folderID *uuid.UUID
+	if input.FolderID != nil && *input.FolderID != "" {
+		fid, err := uuid.Parse(*input.FolderID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid folder_id", apperrors.ErrInvalidInput)
+		}
+		folderID = &fid
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = defaultExamLimit
+	}
+	if input.Limit > maxExamLimit {
+		input.Limit = maxExamLimit
+	}
+
+	if input.DurationSeconds == 0 {
+		input.DurationSeconds = defaultExamDuration
+	}
+	if input.DurationSeconds < minExamDuration || input.DurationSeconds > maxExamDuration {
+		return nil, fmt.Errorf("%w: duration_seconds must be between %d and %d",
+			apperrors.ErrInvalidInput, minExamDuration, maxExamDuration)
+	}
+
+	notes, err := s.noteRepo.GetNotesForExam(ctx, uid, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, apperrors.ErrNotFound // Нет заметок под выбранные фильтры
+	}
+
+	// Порядок вопросов перемешивается один раз и дальше не меняется
+	rand.Shuffle(len(notes), func(i, j int) { notes[i], notes[j] = notes[j], notes[i] })
+	if len(notes) > input.Limit {
+		notes = notes[:input.Limit]
+	}
+
+	now := time.Now()
+	exam := &models.Exam{
+		UserID:          uid,
+		FolderID:        folderID,
+		Status:          models.ExamStatusInProgress,
+		ApplyToSchedule: input.ApplyToSchedule,
+		DurationSeconds: input.DurationSeconds,
+		StartedAt:       now,
+		Deadline:        now.Add(time.Duration(input.DurationSeconds) * time.Second),
+		TotalQuestions:  len(notes),
+		Questions:       make([]models.ExamQuestion, len(notes)),
+	}
+	for i, note := range notes {
+		exam.Questions[i] = models.ExamQuestion{
+			NoteID:   note.ID,
+			Position: i + 1,
+			Title:    note.Title,
+			Content:  note.Content,
+		}
+	}
+
+	if err := s.examRepo.Create(ctx, exam); err != nil {
+		return nil, err
+	}
+
+	return exam, nil
+}
+
+// GetExam возвращает экзамен; просроченный экзамен завершается автоматически
+func (s *ExamService) GetExam(ctx context.Context, userID, examID string) (*models.Exam, error) {
+	exam, err := s.getExam(ctx, userID, examID)
+	if err != nil {
+		return nil, err
+	}
+
+	if exam.Status == models.ExamStatusInProgress && time.Now().After(exam.Deadline) {
+		if exam, err = s.finalizeExpired(ctx, exam); err != nil {
+			return nil, err
+		}
+	}
+
+	return exam, nil
+}
+
+// ListExams возвращает результаты экзаменов пользователя
+func (s *ExamService) ListExams(ctx context.Context, userID string) ([]models.Exam, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exams, err := s.examRepo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range exams {
+		if exams[i].Status == models.ExamStatusInProgress && now.After(exams[i].Deadline) {
+			exam, err := s.finalizeExpired(ctx, &exams[i])
+			if err != nil {
+				return nil, err
+			}
+			exams[i] = *exam
+		}
+	}
+
+	return exams, nil
+}
+
+// AnswerQuestion сохраняет ответ; каждый вопрос отвечается один раз и только до дедлайна
+func (s *ExamService) AnswerQuestion(ctx context.Context, userID, examID string, input *dto.ExamAnswerInput) (*models.ExamQuestion, error) {
+	exam, err := s.getExam(ctx, userID, examID)
+	if err != nil {
+		return nil, err
+	}
+	if exam.Status != models.ExamStatusInProgress {
+		return nil, apperrors.ErrExamSubmitted
+	}
+
+	now := time.Now()
+	if now.After(exam.Deadline) {
+		return nil, apperrors.ErrExamDeadlinePassed
+	}
+
+	qid, err := uuid.Parse(input.QuestionID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	var question *models.ExamQuestion
+	// время ответа считается от предыдущего ответа (или от старта экзамена)
+	lastAnswerAt := exam.StartedAt
+	for i := range exam.Questions {
+		q := &exam.Questions[i]
+		if q.ID == qid {
+			question = q
+		}
+		if q.AnsweredAt != nil && q.AnsweredAt.After(lastAnswerAt) {
+			lastAnswerAt = *q.AnsweredAt
+		}
+	}
+	if question == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if question.AnsweredAt != nil {
+		return nil, apperrors.ErrQuestionAnswered
+	}
+
+	correct := input.Correct
+	question.Correct = &correct
+	question.AnsweredAt = &now
+	question.TimeSpentSeconds = int(now.Sub(lastAnswerAt).Seconds())
+
+	if err := s.examRepo.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// SubmitExam подсчитывает итог. После дедлайна сабмит разрешён, но учитываются только ответы до него.
+func (s *ExamService) SubmitExam(ctx context.Context, userID, examID string) (*models.Exam, error) {
+	exam, err := s.getExam(ctx, userID, examID)
+	if err != nil {
+		return nil, err
+	}
+	if exam.Status != models.ExamStatusInProgress {
+		return nil, apperrors.ErrExamSubmitted
+	}
+
+	if err := s.finalize(ctx, exam); err != nil {
+		return nil, err
+	}
+
+	return exam, nil
+}
+
+func (s *ExamService) getExam(ctx context.Context, userID, examID string) (*models.Exam, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	eid, err := uuid.Parse(examID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	exam, err := s.examRepo.GetByID(ctx, uid, eid)
+	if err != nil {
+		return nil, err
+	}
+	if exam == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return exam, nil
+}
+
+// finalizeExpired завершает просроченный экзамен при чтении. Если его параллельно уже
+// завершил другой запрос, возвращает сохранённый результат, не трогая расписание второй раз
+func (s *ExamService) finalizeExpired(ctx context.Context, exam *models.Exam) (*models.Exam, error) {
+	err := s.finalize(ctx, exam)
+	if errors.Is(err, apperrors.ErrExamSubmitted) {
+		current, err := s.examRepo.GetByID(ctx, exam.UserID, exam.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, apperrors.ErrNotFound
+		}
+		return current, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return exam, nil
+}
+
+// finalize считает баллы, фиксирует затраченное время и, если пользователь
+// согласился, переносит ответы в расписание повторений
+func (s *ExamService) finalize(ctx context.Context, exam *models.Exam) error {
+	now := time.Now()
+	finishedAt := now
+	if finishedAt.After(exam.Deadline) {
+		finishedAt = exam.Deadline
+	}
+
+	correct := 0
+	for _, q := range exam.Questions {
+		if q.Correct != nil && *q.Correct {
+			correct++
+		}
+	}
+
+	exam.Status = models.ExamStatusSubmitted
+	exam.SubmittedAt = &now
+	exam.TotalQuestions = len(exam.Questions)
+	exam.CorrectAnswers = correct
+	exam.TimeSpentSeconds = int(finishedAt.Sub(exam.StartedAt).Seconds())
+	if exam.TotalQuestions > 0 {
+		exam.Score = math.Round(float64(correct)/float64(exam.TotalQuestions)*10000) / 100
+	}
+
+	// Статус и расписание меняются в одной транзакции; Update с условием на статус
+	// гарантирует, что ответы попадут в расписание ровно один раз
+	return s.examRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.examRepo.Update(ctx, exam); err != nil {
+			return err
+		}
+		if !exam.ApplyToSchedule {
+			return nil
+		}
+		return s.applyExamToSchedule(ctx, exam)
+	})
+}
+
+// applyExamToSchedule переносит ответы экзамена в расписание повторений
+func (s *ExamService) applyExamToSchedule(ctx context.Context, exam *models.Exam) error {
+	// Неотвеченные вопросы расписание не трогают
+	for _, q := range exam.Questions {
+		if q.Correct == nil {
+			continue
+		}
+		note, err := s.noteRepo.GetNoteByID(ctx, exam.UserID, q.NoteID)
+		if err != nil {
+			return err
+		}
+		if note == nil {
+			continue // заметка удалена после старта экзамена
+		}
+		settings, err := noteSettings(ctx, s.folderRepo, exam.UserID.String(), note.FolderID)
+		if err != nil {
+			return err
+		}
+		reviewLog := applyReview(note, *q.Correct, models.ReviewSourceExam, settings)
+		if err := s.noteRepo.UpdateNoteFields(ctx, note, "memory_level", "next_review_at"); err != nil {
+			return err
+		}
+		if err := s.reviewLogRepo.Create(ctx, reviewLog); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// GetFolderPath возвращает цепочку папок от корня до folderID включительно (для хлебных крошек)
+func (s *FolderService) GetFolderPath(ctx context.Context, userID, folderID string) ([]models.Folder, error) {
+	folder, err := s.repo.GetByID(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return folderChain(ctx, s.repo, userID, folder)
+}
+
+// folderChain возвращает предков папки от корня и саму папку; предки известны
+// из материализованного пути "/<id корня>/…/<id папки>/"
+func folderChain(ctx context.Context, repo interfaces.FolderRepository, userID string, folder *models.Folder) ([]models.Folder, error) {
+	var ids []uuid.UUID
+	for _, segment := range strings.Split(strings.Trim(folder.Path, "/"), "/") {
+		if id, err := uuid.Parse(segment); err == nil && id != folder.ID {
+			ids = append(ids, id)
+		}
+	}
+	ancestors, err := repo.ListByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Folder, len(ancestors))
+	for _, f := range ancestors {
+		byID[f.ID] = f
+	}
+
+	chain := make([]models.Folder, 0, len(ids)+1)
+	for _, id := range ids {
+		if f, ok := byID[id]; ok {
+			chain = append(chain, f)
+		}
+	}
+	return append(chain, *folder), nil
+}
+
+// ResolveFolderPath находит папку по пути из имён "Languages/Spanish/Verbs";
+// если какой-то папки на пути нет — apperrors.ErrNotFound
+func (s *FolderService) ResolveFolderPath(ctx context.Context, userID, path string) (*models.Folder, error) {
+	names, err := splitFolderPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var folder *models.Folder
+	for _, name := range names {
+		folder, err = s.repo.GetByName(ctx, userID, folderIDOf(folder), name)
+		if err != nil {
+			return nil, err
+		}
+		if folder == nil {
+			return nil, apperrors.ErrNotFound
+		}
+	}
+	return folder, nil
+}
+
+// EnsureFolderPath находит папку по пути из имён, создавая недостающие папки по дороге.
+// Весь путь создаётся одной транзакцией; перед поиском каждого имени родитель блокируется,
+// так что параллельный запрос с тем же путём дождётся её и найдёт уже созданные папки
+func (s *FolderService) EnsureFolderPath(ctx context.Context, userID, path string) (*dto.FolderEnsurePathResult, error) {
+	names, err := splitFolderPath(path)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid userID")
+	}
+
+	result := &dto.FolderEnsurePathResult{Created: []models.Folder{}}
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		var folder *models.Folder
+		for _, name := range names {
+			parent := folder
+			if err := s.repo.LockChildren(ctx, uid, folderIDOf(parent)); err != nil {
+				return err
+			}
+			if folder, err = s.repo.GetByName(ctx, userID, folderIDOf(parent), name); err != nil {
+				return err
+			}
+			if folder != nil {
+				continue
+			}
+
+			input := dto.FolderCreateInput{Name: name}
+			if parent != nil {
+				parentID := parent.ID.String()
+				input.ParentID = &parentID
+			}
+			if folder, err = s.CreateFolder(ctx, userID, input); err != nil {
+				return err
+			}
+			result.Created = append(result.Created, *folder)
+		}
+		result.Folder = *folder
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// splitFolderPath разбирает путь на имена папок: пробелы вокруг имён и пустые сегменты
+// (ведущий, завершающий или двойной "/") отбрасываются
+func splitFolderPath(path string) ([]string, error) {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		name := strings.TrimSpace(segment)
+		if name == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > models.MaxFolderNameLength {
+			return nil, fmt.Errorf("%w: folder name %q is longer than %d characters", apperrors.ErrInvalidInput, name, models.MaxFolderNameLength)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: folder path is empty", apperrors.ErrInvalidInput)
+	}
+	return names, nil
+}
+
+// validateFolderName отклоняет имена с "/": он разделяет имена в пути,
+// и такую папку нельзя было бы найти через ResolveFolderPath и EnsureFolderPath
+func validateFolderName(name string) error {
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("%w: folder name %q must not contain \"/\"", apperrors.ErrInvalidInput, name)
+	}
+	return nil
+}
@@ -3,6 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
 	apperrors "valibibe/internal/errors"
 
 	"valibibe/internal/controller/dto"
@@ -26,8 +31,12 @@ func (s *FolderService) CreateFolder(ctx context.Context, userID string, input d
 	if err != nil {
 		return nil, errors.New("invalid userID")
 	}
+	if err := validateFolderName(input.Name); err != nil {
+		return nil, err
+	}
 
 	var parentID *uuid.UUID
+	var parentFolder *models.Folder
 	if input.ParentID != nil {
 		pid, err := uuid.Parse(*input.ParentID)
 		if err != nil {
@@ -35,7 +44,7 @@ func (s *FolderService) CreateFolder(ctx context.Context, userID string, input d
 		}
 
 		// Security check: убедиться, что родительская папка принадлежит пользователю
-		parentFolder, err := s.repo.GetByID(ctx, userID, *input.ParentID)
+		parentFolder, err = s.repo.GetByID(ctx, userID, *input.ParentID)
 		if err != nil {
 			return nil, err // DB error
 		}
@@ -46,10 +55,12 @@ func (s *FolderService) CreateFolder(ctx context.Context, userID string, input d
 	}
 
 	folder := &models.Folder{
+		ID:       uuid.New(),
 		UserID:   uid,
 		Name:     input.Name,
 		ParentID: parentID,
 	}
+	folder.Path = models.SubfolderPath(folderPath(parentFolder), folder.ID)
 
 	if err := s.repo.Create(ctx, folder); err != nil {
 		return nil, err
@@ -58,13 +69,17 @@ func (s *FolderService) CreateFolder(ctx context.Context, userID string, input d
 	return folder, nil
 }
 
-// Get folder tree
+// Get folder tree: папки и статистика заметок — два запроса, итоги по поддеревьям считаются в памяти
 func (s *FolderService) GetFolderTree(ctx context.Context, userID string) ([]dto.FolderNode, error) {
 	folders, err := s.repo.ListByUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	return buildFolderTree(folders), nil
+	stats, err := s.repo.NoteStats(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return buildFolderTree(folders, stats), nil
 }
 
 // Update folder
@@ -76,60 +91,391 @@ func (s *FolderService) UpdateFolder(ctx context.Context, userID, folderID strin
 	if folder == nil {
 		return nil, apperrors.ErrNotFound
 	}
+	if err := checkVersion(input.Version, folder.Version); err != nil {
+		if errors.Is(err, apperrors.ErrVersionConflict) {
+			return nil, &VersionConflictError{Current: folder, Version: folder.Version}
+		}
+		return nil, err
+	}
 
 	if input.Name != nil {
+		if err := validateFolderName(*input.Name); err != nil {
+			return nil, err
+		}
 		folder.Name = *input.Name
 	}
 
+	moved := false
 	if input.ParentID != nil {
-		if *input.ParentID == "" { // Unset parent
-			folder.ParentID = nil
-		} else {
-			pid, err := uuid.Parse(*input.ParentID)
-			if err != nil {
-				return nil, errors.New("invalid parentID")
-			}
-			// Security check: убедиться, что новая родительская папка принадлежит пользователю
-			parentFolder, err := s.repo.GetByID(ctx, userID, *input.ParentID)
+		parent, err := s.parseParent(ctx, userID, *input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		parentID := folderIDOf(parent)
+		if !sameParent(folder.ParentID, parentID) {
+			// В новом родителе папка встаёт последней
+			siblings, err := s.repo.ListSiblings(ctx, userID, parentID)
 			if err != nil {
-				return nil, err // DB error
+				return nil, err
 			}
-			if parentFolder == nil {
-				return nil, apperrors.ErrNotFound // Parent folder not found or access denied
+			if folder.Position, err = appendPosition(folderPositions(siblings)); err != nil {
+				return nil, err
 			}
-			folder.ParentID = &pid
+			folder.ParentID = parentID
+			folder.Path = models.SubfolderPath(folderPath(parent), folder.ID)
+			moved = true
 		}
 	}
 
-	if err := s.repo.Update(ctx, folder); err != nil {
+	// При смене родителя вместе с папкой меняются пути её подпапок
+	if moved {
+		err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.lockMoveTarget(ctx, userID, folder, folder.ParentID); err != nil {
+				return err
+			}
+			return s.repo.Move(ctx, folder, nil)
+		})
+	} else {
+		err = s.repo.Update(ctx, folder)
+	}
+	if errors.Is(err, apperrors.ErrVersionConflict) {
+		// Папку изменили между чтением и записью
+		current, err := s.repo.GetByID(ctx, userID, folderID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, &VersionConflictError{Current: current, Version: current.Version}
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return folder, nil
 }
 
-// Delete folder (cascade notes + children via DB constraints)
-func (s *FolderService) DeleteFolder(ctx context.Context, userID, folderID string) error {
+// MoveFolder переносит папку к родителю input.ParentID (без него — остаётся у прежнего)
+// и ставит её перед input.Before или после input.After, иначе — последней.
+// Папку нельзя перенести в неё саму или в её подпапку, а у нового родителя не должно быть папки
+// с тем же именем. Обычно записывается только ключ порядка самой папки (см. placeItem).
+func (s *FolderService) MoveFolder(ctx context.Context, userID, folderID string, input dto.FolderMoveInput) (*models.Folder, error) {
 	folder, err := s.repo.GetByID(ctx, userID, folderID)
 	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if err := checkVersion(input.Version, folder.Version); err != nil {
+		if errors.Is(err, apperrors.ErrVersionConflict) {
+			return nil, &VersionConflictError{Current: folder, Version: folder.Version}
+		}
+		return nil, err
+	}
+
+	anchorID, after, err := moveAnchor(input.MoveInput)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := folder.ParentID
+	var parent *models.Folder
+	if input.ParentID != nil {
+		if parent, err = s.parseParent(ctx, userID, *input.ParentID); err != nil {
+			return nil, err
+		}
+		parentID = folderIDOf(parent)
+	}
+	changesParent := !sameParent(folder.ParentID, parentID)
+
+	siblings, err := s.repo.ListSiblings(ctx, userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	items := folderPositions(siblings)
+	if changesParent {
+		// У нового родителя папка пока стоит последней, без ключа
+		items = append(items, positioned{ID: folder.ID})
+	}
+	positions, err := placeItem(items, folder.ID, anchorID, after)
+	if err != nil {
+		return nil, err
+	}
+	position, ok := positions[folder.ID]
+	delete(positions, folder.ID)
+	switch {
+	case ok:
+		folder.Position = position
+	case changesParent:
+		// У нового родителя папка и так последняя
+		if folder.Position, err = appendPosition(folderPositions(siblings)); err != nil {
+			return nil, err
+		}
+	case len(positions) == 0:
+		return folder, nil
+	}
+	if changesParent {
+		folder.ParentID = parentID
+		folder.Path = models.SubfolderPath(folderPath(parent), folder.ID)
+	}
+
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if changesParent {
+			if err := s.lockMoveTarget(ctx, userID, folder, parentID); err != nil {
+				return err
+			}
+		}
+		return s.repo.Move(ctx, folder, positions)
+	})
+	if errors.Is(err, apperrors.ErrVersionConflict) {
+		current, err := s.repo.GetByID(ctx, userID, folderID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, &VersionConflictError{Current: current, Version: current.Version}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// parseParent находит нового родителя по ID: "" — корень (nil); папка должна принадлежать пользователю
+func (s *FolderService) parseParent(ctx context.Context, userID, value string) (*models.Folder, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(value); err != nil {
+		return nil, fmt.Errorf("%w: invalid parent_id %q", apperrors.ErrInvalidInput, value)
+	}
+	parent, err := s.repo.GetByID(ctx, userID, value)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, apperrors.ErrNotFound // Parent folder not found or access denied
+	}
+	return parent, nil
+}
+
+func folderIDOf(folder *models.Folder) *uuid.UUID {
+	if folder == nil {
+		return nil
+	}
+	return &folder.ID
+}
+
+// folderPath — материализованный путь папки; у корня (nil) пустой
+func folderPath(folder *models.Folder) string {
+	if folder == nil {
+		return ""
+	}
+	return folder.Path
+}
+
+// lockMoveTarget блокирует переносимую папку и цепочку нового родителя до корня, перечитывает
+// родителя и уже под блокировкой проверяет перенос (см. checkMoveTarget). Встречные переносы
+// (A в B и B в A) так выполняются по очереди, и второй видит цикл. Вызывается в транзакции
+func (s *FolderService) lockMoveTarget(ctx context.Context, userID string, folder *models.Folder, parentID *uuid.UUID) error {
+	if err := s.repo.LockForMove(ctx, folder, parentID); err != nil {
 		return err
 	}
+	if parentID != nil {
+		parent, err := s.repo.GetByID(ctx, userID, parentID.String())
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return apperrors.ErrNotFound
+		}
+		folder.Path = models.SubfolderPath(parent.Path, folder.ID)
+	}
+	return s.checkMoveTarget(ctx, userID, folder, parentID)
+}
+
+// checkMoveTarget проверяет перенос папки к родителю parentID:
+// не в неё саму и не в подпапку, и без папки с тем же именем у нового родителя
+func (s *FolderService) checkMoveTarget(ctx context.Context, userID string, folder *models.Folder, parentID *uuid.UUID) error {
+	if parentID != nil {
+		inside, err := s.repo.IsDescendant(ctx, userID, folder.ID.String(), parentID.String())
+		if err != nil {
+			return err
+		}
+		if inside {
+			return apperrors.ErrFolderCycle
+		}
+	}
+
+	taken, err := s.repo.NameTaken(ctx, folder.UserID, parentID, folder.Name, folder.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: folder %q already exists in the target folder", apperrors.ErrDuplicateName, folder.Name)
+	}
+	return nil
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// DeleteFolder уносит папку в корзину; mode (dto.FolderDelete*, по умолчанию cascade) задаёт,
+// что происходит с подпапками и заметками. Результат перечисляет затронутое;
+// при dryRun ничего не меняется — так работает предпросмотр удаления
+func (s *FolderService) DeleteFolder(ctx context.Context, userID, folderID, mode string, dryRun bool) (*dto.FolderDeleteResult, error) {
+	switch mode {
+	case "":
+		mode = dto.FolderDeleteCascade
+	case dto.FolderDeleteCascade, dto.FolderDeleteMoveToParent, dto.FolderDeleteUnfile:
+	default:
+		return nil, fmt.Errorf("%w: unknown delete mode %q", apperrors.ErrInvalidInput, mode)
+	}
+
+	if dryRun {
+		folder, err := s.getFolder(ctx, userID, folderID)
+		if err != nil {
+			return nil, err
+		}
+		return s.planFolderDelete(ctx, folder, mode)
+	}
+
+	// Проверка имён и само удаление — в одной транзакции, под блокировкой родителя,
+	// чтобы между ними у родителя не появилась папка с именем одной из подпапок
+	var result *dto.FolderDeleteResult
+	err := s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		folder, err := s.getFolder(ctx, userID, folderID)
+		if err != nil {
+			return err
+		}
+		if result, err = s.planFolderDelete(ctx, folder, mode); err != nil {
+			return err
+		}
+		result.DryRun = false
+		return s.repo.Delete(ctx, folder, mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getFolder возвращает папку пользователя или apperrors.ErrNotFound
+func (s *FolderService) getFolder(ctx context.Context, userID, folderID string) (*models.Folder, error) {
+	folder, err := s.repo.GetByID(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
 	if folder == nil {
-		return apperrors.ErrNotFound
+		return nil, apperrors.ErrNotFound
 	}
-	return s.repo.Delete(ctx, userID, folderID)
+	return folder, nil
+}
+
+// planFolderDelete перечисляет, что затронет удаление папки в режиме mode, ничего не меняя;
+// для move_to_parent проверяет, что у родителя нет папок с именами переносимых подпапок
+func (s *FolderService) planFolderDelete(ctx context.Context, folder *models.Folder, mode string) (*dto.FolderDeleteResult, error) {
+	if mode == dto.FolderDeleteMoveToParent {
+		if err := s.repo.LockChildren(ctx, folder.UserID, folder.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	contents, err := s.repo.Contents(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.FolderDeleteResult{
+		Mode:           mode,
+		DryRun:         true,
+		Subfolders:     len(contents.Folders),
+		Notes:          len(contents.Notes),
+		TrashedFolders: []uuid.UUID{folder.ID},
+		TrashedNotes:   []uuid.UUID{},
+		MovedFolders:   []uuid.UUID{},
+		MovedNotes:     []uuid.UUID{},
+	}
+	switch mode {
+	case dto.FolderDeleteCascade:
+		for _, f := range contents.Folders {
+			result.TrashedFolders = append(result.TrashedFolders, f.ID)
+		}
+		for _, n := range contents.Notes {
+			result.TrashedNotes = append(result.TrashedNotes, n.ID)
+		}
+	case dto.FolderDeleteMoveToParent:
+		result.TargetFolderID = folder.ParentID
+		for _, f := range contents.Folders {
+			if f.ParentID == nil || *f.ParentID != folder.ID {
+				continue
+			}
+			// Удаляемая папка своё имя освобождает, остальные соседи — нет
+			taken, err := s.repo.NameTaken(ctx, folder.UserID, folder.ParentID, f.Name, folder.ID)
+			if err != nil {
+				return nil, err
+			}
+			if taken {
+				return nil, fmt.Errorf("%w: folder %q already exists in the parent folder", apperrors.ErrDuplicateName, f.Name)
+			}
+			result.MovedFolders = append(result.MovedFolders, f.ID)
+		}
+		for _, n := range contents.Notes {
+			if n.FolderID != nil && *n.FolderID == folder.ID {
+				result.MovedNotes = append(result.MovedNotes, n.ID)
+			}
+		}
+	case dto.FolderDeleteUnfile:
+		for _, f := range contents.Folders {
+			result.TrashedFolders = append(result.TrashedFolders, f.ID)
+		}
+		for _, n := range contents.Notes {
+			result.MovedNotes = append(result.MovedNotes, n.ID)
+		}
+	}
+	return result, nil
+}
+
+func folderPositions(folders []models.Folder) []positioned {
+	items := make([]positioned, len(folders))
+	for i, f := range folders {
+		items[i] = positioned{ID: f.ID, Position: f.Position}
+	}
+	return items
 }
 
-// helper: строим дерево из списка
-func buildFolderTree(folders []models.Folder) []dto.FolderNode {
+// helper: строим дерево из списка; соседи идут в ручном порядке (position, затем имя и ID)
+func buildFolderTree(folders []models.Folder, stats []interfaces.FolderNoteStats) []dto.FolderNode {
 	idToNode := make(map[string]*dto.FolderNode)
 	var roots []*dto.FolderNode
 
+	sorted := make([]models.Folder, len(folders))
+	copy(sorted, folders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
 	// создаём узлы
-	for _, f := range folders {
+	for _, f := range sorted {
 		node := &dto.FolderNode{
 			ID:       f.ID.String(),
 			Name:     f.Name,
+			Version:  f.Version,
+			Position: f.Position,
 			Children: []*dto.FolderNode{},
 		}
 		if f.ParentID != nil {
@@ -139,8 +485,9 @@ func buildFolderTree(folders []models.Folder) []dto.FolderNode {
 		idToNode[f.ID.String()] = node
 	}
 
-	// связываем parent-child
-	for _, node := range idToNode {
+	// связываем parent-child в отсортированном порядке, а не в порядке обхода map
+	for _, f := range sorted {
+		node := idToNode[f.ID.String()]
 		if node.ParentID != nil {
 			if parent, ok := idToNode[*node.ParentID]; ok {
 				parent.Children = append(parent.Children, node)
@@ -150,6 +497,14 @@ func buildFolderTree(folders []models.Folder) []dto.FolderNode {
 		}
 	}
 
+	own := make(map[string]interfaces.FolderNoteStats, len(stats))
+	for _, st := range stats {
+		own[st.FolderID.String()] = st
+	}
+	for _, r := range roots {
+		rollUpCounts(r, own)
+	}
+
 	// если надо вернуть []dto.FolderNode (а не []*dto.FolderNode)
 	result := make([]dto.FolderNode, 0, len(roots))
 	for _, r := range roots {
@@ -158,3 +513,27 @@ func buildFolderTree(folders []models.Folder) []dto.FolderNode {
 
 	return result
 }
+
+// rollUpCounts заполняет статистику узла и его поддерева; возвращает итог поддерева
+func rollUpCounts(node *dto.FolderNode, own map[string]interfaces.FolderNoteStats) interfaces.FolderNoteStats {
+	self := own[node.ID]
+	total := self
+	for _, child := range node.Children {
+		sub := rollUpCounts(child, own)
+		total.Total += sub.Total
+		total.Due += sub.Due
+		total.New += sub.New
+		total.MemorySum += sub.MemorySum
+	}
+	node.Counts = folderCounts(self)
+	node.SubtreeCounts = folderCounts(total)
+	return total
+}
+
+func folderCounts(st interfaces.FolderNoteStats) dto.FolderCounts {
+	counts := dto.FolderCounts{Total: st.Total, Due: st.Due, New: st.New}
+	if st.Total > 0 {
+		counts.AverageMemoryLevel = math.Round(float64(st.MemorySum)/float64(st.Total)*100) / 100
+	}
+	return counts
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/scheduler"
+)
+
+// GetFolderSettings возвращает папку с её собственными настройками обучения (незаданные поля — null)
+func (s *FolderService) GetFolderSettings(ctx context.Context, userID, folderID string) (*models.Folder, error) {
+	folder, err := s.repo.GetByID(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if folder.Settings == nil {
+		folder.Settings = &models.FolderSettings{}
+	}
+	return folder, nil
+}
+
+// UpdateFolderSettings заменяет собственные настройки папки целиком: null-поля снова наследуются.
+// Версия необязательна; если она передана и устарела — VersionConflictError
+func (s *FolderService) UpdateFolderSettings(ctx context.Context, userID, folderID string, settings models.FolderSettings, version *int) (*models.Folder, error) {
+	folder, err := s.GetFolderSettings(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if version != nil && *version != folder.Version {
+		return nil, &VersionConflictError{Current: folder.Settings, Version: folder.Version}
+	}
+
+	// Проверяем и сами значения, и то, что получится вместе с унаследованными
+	own := scheduler.Default()
+	applyFolderSettings(&own, &settings)
+	if err := own.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+	folder.Settings = &settings
+	chain, err := folderChain(ctx, s.repo, userID, folder)
+	if err != nil {
+		return nil, err
+	}
+	effective, _ := effectiveSettings(chain)
+	if err := effective.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+
+	err = s.repo.Update(ctx, folder)
+	if errors.Is(err, apperrors.ErrVersionConflict) {
+		current, err := s.GetFolderSettings(ctx, userID, folderID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &VersionConflictError{Current: current.Settings, Version: current.Version}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// GetEffectiveSettings сворачивает настройки цепочки папок от корня до folderID поверх настроек по умолчанию
+func (s *FolderService) GetEffectiveSettings(ctx context.Context, userID, folderID string) (*dto.FolderEffectiveSettings, error) {
+	chain, err := s.GetFolderPath(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	settings, sources := effectiveSettings(chain)
+	return &dto.FolderEffectiveSettings{
+		FolderID: chain[len(chain)-1].ID,
+		Settings: settings,
+		Sources:  sources,
+	}, nil
+}
+
+// effectiveSettings применяет настройки папок цепочки от корня к листу;
+// sources — какая папка задала каждое поле (по JSON-имени)
+func effectiveSettings(chain []models.Folder) (scheduler.Settings, map[string]uuid.UUID) {
+	settings := scheduler.Default()
+	sources := make(map[string]uuid.UUID)
+	for _, folder := range chain {
+		for _, field := range applyFolderSettings(&settings, folder.Settings) {
+			sources[field] = folder.ID
+		}
+	}
+	return settings, sources
+}
+
+// applyFolderSettings переносит заданные поля папки в settings и возвращает их JSON-имена
+func applyFolderSettings(settings *scheduler.Settings, o *models.FolderSettings) []string {
+	if o == nil {
+		return nil
+	}
+	var fields []string
+	if o.NewPerDay != nil {
+		settings.NewPerDay = *o.NewPerDay
+		fields = append(fields, "new_per_day")
+	}
+	if o.ReviewsPerDay != nil {
+		settings.ReviewsPerDay = *o.ReviewsPerDay
+		fields = append(fields, "reviews_per_day")
+	}
+	if o.LearningSteps != nil {
+		settings.LearningSteps = o.LearningSteps
+		fields = append(fields, "learning_steps")
+	}
+	if o.LevelStep != nil {
+		settings.LevelStep = *o.LevelStep
+		fields = append(fields, "level_step")
+	}
+	if o.IntervalDays != nil {
+		settings.IntervalDays = o.IntervalDays
+		fields = append(fields, "interval_days")
+	}
+	if o.TargetRetention != nil {
+		settings.TargetRetention = *o.TargetRetention
+		fields = append(fields, "target_retention")
+	}
+	if o.Algorithm != nil {
+		settings.Algorithm = *o.Algorithm
+		fields = append(fields, "algorithm")
+	}
+	if o.MaxIntervalDays != nil {
+		settings.MaxIntervalDays = *o.MaxIntervalDays
+		fields = append(fields, "max_interval_days")
+	}
+	return fields
+}
+
+// noteSettings — действующие настройки для заметки из папки folderID (nil — без папки)
+func noteSettings(ctx context.Context, repo interfaces.FolderRepository, userID string, folderID *uuid.UUID) (scheduler.Settings, error) {
+	if folderID == nil || repo == nil {
+		return scheduler.Default(), nil
+	}
+	folder, err := repo.GetByID(ctx, userID, folderID.String())
+	if err != nil {
+		return scheduler.Settings{}, err
+	}
+	if folder == nil {
+		return scheduler.Default(), nil
+	}
+	chain, err := folderChain(ctx, repo, userID, folder)
+	if err != nil {
+		return scheduler.Settings{}, err
+	}
+	settings, _ := effectiveSettings(chain)
+	return settings, nil
+}
+
+// folderSettingsResolver считает действующие настройки по уже загруженным папкам пользователя
+// (для сессии повторения и симулятора нагрузки, где нужны настройки многих папок сразу)
+type folderSettingsResolver struct {
+	folders map[uuid.UUID]*models.Folder
+	cache   map[uuid.UUID]scheduler.Settings
+}
+
+func newFolderSettingsResolver(folders []models.Folder) *folderSettingsResolver {
+	r := &folderSettingsResolver{
+		folders: make(map[uuid.UUID]*models.Folder, len(folders)),
+		cache:   make(map[uuid.UUID]scheduler.Settings, len(folders)),
+	}
+	for i := range folders {
+		r.folders[folders[i].ID] = &folders[i]
+	}
+	return r
+}
+
+// settings возвращает настройки папки folderID (nil или неизвестная папка — по умолчанию)
+func (r *folderSettingsResolver) settings(folderID *uuid.UUID) scheduler.Settings {
+	if folderID == nil {
+		return scheduler.Default()
+	}
+	if cached, ok := r.cache[*folderID]; ok {
+		return cached
+	}
+	folder, ok := r.folders[*folderID]
+	if !ok {
+		return scheduler.Default()
+	}
+
+	settings := r.settings(folder.ParentID)
+	applyFolderSettings(&settings, folder.Settings)
+	r.cache[*folderID] = settings
+	return settings
+}
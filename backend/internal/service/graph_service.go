@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type GraphService struct {
+	noteRepo         interfaces.NoteRepository
+	folderRepo       interfaces.FolderRepository
+	tagRepo          interfaces.TagRepository
+	prerequisiteRepo interfaces.PrerequisiteRepository
+	linkRepo         interfaces.NoteLinkRepository
+}
+
+func NewGraphService(
+	noteRepo interfaces.NoteRepository,
+	folderRepo interfaces.FolderRepository,
+	tagRepo interfaces.TagRepository,
+	prerequisiteRepo interfaces.PrerequisiteRepository,
+	linkRepo interfaces.NoteLinkRepository,
+) *GraphService {
+	return &GraphService{
+		noteRepo:         noteRepo,
+		folderRepo:       folderRepo,
+		tagRepo:          tagRepo,
+		prerequisiteRepo: prerequisiteRepo,
+		linkRepo:         linkRepo,
+	}
+}
+
+// BuildGraph собирает граф знаний пользователя.
+// С фильтром по папке в граф попадает её поддерево, с фильтром по тегам — заметки хотя бы с одним из тегов,
+// а из папок и тегов остаются только те, что связаны с отобранными заметками.
+func (s *GraphService) BuildGraph(ctx context.Context, userID string, filter *dto.GraphFilter) (*dto.Graph, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.folderRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	folderByID := make(map[uuid.UUID]*models.Folder, len(folders))
+	for i := range folders {
+		folderByID[folders[i].ID] = &folders[i]
+	}
+
+	// Поддерево папки из фильтра (nil — все папки)
+	var subtree map[uuid.UUID]bool
+	if filter.FolderID != nil && *filter.FolderID != "" {
+		rootID, err := uuid.Parse(*filter.FolderID)
+		if err != nil || folderByID[rootID] == nil {
+			return nil, apperrors.ErrNotFound
+		}
+		subtree = folderSubtree(folders, rootID)
+	}
+
+	tagFilter := make(map[uuid.UUID]bool, len(filter.TagIDs))
+	for _, id := range filter.TagIDs {
+		tid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid tag id %q", apperrors.ErrInvalidInput, id)
+		}
+		tagFilter[tid] = true
+	}
+
+	page, err := s.noteRepo.GetAllNotesByUserID(ctx, &dto.NoteFilter{UserID: userID, SortBy: "created_at", Order: "asc"})
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]models.Note, 0, len(page.Notes))
+	for _, n := range page.Notes {
+		if subtree != nil && (n.FolderID == nil || !subtree[*n.FolderID]) {
+			continue
+		}
+		if len(tagFilter) > 0 && !hasAnyTag(n, tagFilter) {
+			continue
+		}
+		notes = append(notes, n)
+	}
+
+	graph := &dto.Graph{Nodes: []dto.GraphNode{}, Edges: []dto.GraphEdge{}}
+	filtered := subtree != nil || len(tagFilter) > 0
+
+	// Папки: без фильтра по тегам — всё поддерево, с ним — папки отобранных заметок и их предки
+	includedFolders := make(map[uuid.UUID]bool)
+	for _, f := range folders {
+		if len(tagFilter) == 0 && (subtree == nil || subtree[f.ID]) {
+			includedFolders[f.ID] = true
+		}
+	}
+	if len(tagFilter) > 0 {
+		for _, n := range notes {
+			for id := n.FolderID; id != nil && !includedFolders[*id]; {
+				if subtree != nil && !subtree[*id] {
+					break
+				}
+				includedFolders[*id] = true
+				if f := folderByID[*id]; f != nil {
+					id = f.ParentID
+				} else {
+					id = nil
+				}
+			}
+		}
+	}
+
+	// Теги: без фильтров — все, иначе — теги отобранных заметок
+	tags, err := s.tagRepo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	includedTags := make(map[uuid.UUID]bool)
+	for _, n := range notes {
+		for _, t := range n.Tags {
+			includedTags[t.ID] = true
+		}
+	}
+
+	for _, f := range folders {
+		if includedFolders[f.ID] {
+			graph.Nodes = append(graph.Nodes, dto.GraphNode{ID: f.ID.String(), Type: dto.GraphNodeFolder, Label: f.Name})
+		}
+	}
+	for _, t := range tags {
+		if !filtered || includedTags[t.ID] {
+			includedTags[t.ID] = true
+			graph.Nodes = append(graph.Nodes, dto.GraphNode{ID: t.ID.String(), Type: dto.GraphNodeTag, Label: t.Name})
+		}
+	}
+
+	includedNotes := make(map[uuid.UUID]bool, len(notes))
+	for _, n := range notes {
+		includedNotes[n.ID] = true
+		level := n.MemoryLevel
+		graph.Nodes = append(graph.Nodes, dto.GraphNode{
+			ID:          n.ID.String(),
+			Type:        dto.GraphNodeNote,
+			Label:       n.Title,
+			MemoryLevel: &level,
+			Archived:    n.Archived,
+		})
+	}
+
+	addEdge := func(source, target uuid.UUID, edgeType string) {
+		graph.Edges = append(graph.Edges, dto.GraphEdge{Source: source.String(), Target: target.String(), Type: edgeType})
+	}
+
+	for _, f := range folders {
+		if includedFolders[f.ID] && f.ParentID != nil && includedFolders[*f.ParentID] {
+			addEdge(f.ID, *f.ParentID, dto.GraphEdgeChildOf)
+		}
+	}
+	for _, n := range notes {
+		if n.FolderID != nil && includedFolders[*n.FolderID] {
+			addEdge(n.ID, *n.FolderID, dto.GraphEdgeInFolder)
+		}
+		for _, t := range n.Tags {
+			if includedTags[t.ID] {
+				addEdge(n.ID, t.ID, dto.GraphEdgeTagged)
+			}
+		}
+	}
+
+	links, err := s.linkRepo.ListResolvedByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		if includedNotes[l.SourceID] && includedNotes[*l.TargetID] {
+			addEdge(l.SourceID, *l.TargetID, dto.GraphEdgeLinksTo)
+		}
+	}
+
+	prerequisites, err := s.prerequisiteRepo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prerequisites {
+		if includedNotes[p.PrerequisiteID] && includedNotes[p.NoteID] {
+			addEdge(p.PrerequisiteID, p.NoteID, dto.GraphEdgePrerequisite)
+		}
+	}
+
+	return graph, nil
+}
+
+// folderSubtree возвращает ID папки rootID и всех её потомков
+func folderSubtree(folders []models.Folder, rootID uuid.UUID) map[uuid.UUID]bool {
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, f := range folders {
+		if f.ParentID != nil {
+			children[*f.ParentID] = append(children[*f.ParentID], f.ID)
+		}
+	}
+
+	subtree := map[uuid.UUID]bool{rootID: true}
+	queue := []uuid.UUID{rootID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if !subtree[child] {
+				subtree[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return subtree
+}
+
+func hasAnyTag(note models.Note, tagIDs map[uuid.UUID]bool) bool {
+	for _, t := range note.Tags {
+		if tagIDs[t.ID] {
+			return true
+		}
+	}
+	return false
+}
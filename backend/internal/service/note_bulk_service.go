@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// MaxBulkNotes — сколько заметок можно изменить одним запросом
+const MaxBulkNotes = 1000
+
+type NoteBulkService struct {
+	repo        interfaces.NoteBulkRepository
+	noteService *NoteService
+	folderRepo  interfaces.FolderRepository
+	tagRepo     interfaces.TagRepository
+}
+
+func NewNoteBulkService(
+	repo interfaces.NoteBulkRepository,
+	noteService *NoteService,
+	folderRepo interfaces.FolderRepository,
+	tagRepo interfaces.TagRepository,
+) *NoteBulkService {
+	return &NoteBulkService{
+		repo:        repo,
+		noteService: noteService,
+		folderRepo:  folderRepo,
+		tagRepo:     tagRepo,
+	}
+}
+
+// Run применяет действие к заметкам, выбранным по ids или по фильтру.
+// Чужие и несуществующие ids попадают в результат как not_found и пропускаются;
+// остальные изменения выполняются одной транзакцией. При dry_run ничего не меняется.
+func (s *NoteBulkService) Run(ctx context.Context, userID string, input *dto.NoteBulkInput) (*dto.NoteBulkResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.operation(ctx, userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	requested, err := s.selectNotes(ctx, userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(requested))
+	for _, id := range requested {
+		if nid, err := uuid.Parse(id); err == nil {
+			ids = append(ids, nid)
+		}
+	}
+	result := &dto.NoteBulkResult{
+		Action: input.Action,
+		DryRun: input.DryRun,
+		Items:  make([]dto.NoteBulkItem, len(requested)),
+	}
+	// Статусы считаются по строкам, заблокированным в той же транзакции, что и запись
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		notes := []models.Note{}
+		if len(ids) > 0 {
+			if notes, err = s.repo.ListByIDs(ctx, uid, ids); err != nil {
+				return err
+			}
+		}
+		byID := make(map[string]*models.Note, len(notes))
+		for i := range notes {
+			byID[notes[i].ID.String()] = &notes[i]
+		}
+
+		for i, id := range requested {
+			item := dto.NoteBulkItem{ID: id, Status: dto.BulkItemNotFound}
+			if note, ok := byID[id]; ok {
+				item.ID = note.ID.String()
+				item.Status = dto.BulkItemUnchanged
+				if bulkChanges(note, op) {
+					item.Status = dto.BulkItemUpdated
+					op.NoteIDs = append(op.NoteIDs, note.ID)
+				}
+			}
+			switch item.Status {
+			case dto.BulkItemUpdated:
+				result.Updated++
+			case dto.BulkItemUnchanged:
+				result.Unchanged++
+			default:
+				result.NotFound++
+			}
+			result.Items[i] = item
+		}
+		result.Matched = result.Updated + result.Unchanged
+
+		if input.DryRun {
+			return nil
+		}
+		// Удаление — тем же путём, что и DELETE /notes/:id, со всей его очисткой
+		if op.Action == dto.BulkDelete {
+			for _, id := range op.NoteIDs {
+				if err := s.noteService.DeleteNote(ctx, userID, id.String()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return s.repo.Apply(ctx, uid, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// operation проверяет действие и его параметры: папка и теги должны принадлежать пользователю
+func (s *NoteBulkService) operation(ctx context.Context, userID string, input *dto.NoteBulkInput) (*interfaces.BulkOperation, error) {
+	op := &interfaces.BulkOperation{Action: input.Action}
+
+	switch input.Action {
+	case dto.BulkArchive, dto.BulkUnarchive, dto.BulkDelete, dto.BulkResetScheduling:
+	case dto.BulkMove:
+		if input.FolderID != nil && *input.FolderID != "" {
+			folder, err := s.folderRepo.GetByID(ctx, userID, *input.FolderID)
+			if err != nil {
+				return nil, err
+			}
+			if folder == nil {
+				return nil, fmt.Errorf("%w: folder not found", apperrors.ErrInvalidInput)
+			}
+			op.FolderID = &folder.ID
+		}
+	case dto.BulkAddTags, dto.BulkRemoveTags, dto.BulkReplaceTags:
+		if len(input.TagIDs) == 0 && input.Action != dto.BulkReplaceTags {
+			return nil, fmt.Errorf("%w: tag_ids is required", apperrors.ErrInvalidInput)
+		}
+		tagIDs, err := s.ownedTags(ctx, userID, input.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+		op.TagIDs = tagIDs
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", apperrors.ErrInvalidInput, input.Action)
+	}
+	return op, nil
+}
+
+func (s *NoteBulkService) ownedTags(ctx context.Context, userID string, ids []string) ([]uuid.UUID, error) {
+	tagIDs := make([]uuid.UUID, 0, len(ids))
+	unique := make([]string, 0, len(ids))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		tid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid tag id %q", apperrors.ErrInvalidInput, id)
+		}
+		if !seen[tid] {
+			seen[tid] = true
+			tagIDs = append(tagIDs, tid)
+			unique = append(unique, tid.String())
+		}
+	}
+	if len(unique) == 0 {
+		return tagIDs, nil
+	}
+
+	count, err := s.tagRepo.CountTagsByIDsAndUserID(ctx, unique, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count != len(unique) {
+		return nil, fmt.Errorf("%w: tag not found", apperrors.ErrInvalidInput)
+	}
+	return tagIDs, nil
+}
+
+// selectNotes возвращает ID выбранных заметок без повторов, в порядке запроса или выдачи фильтра
+func (s *NoteBulkService) selectNotes(ctx context.Context, userID string, input *dto.NoteBulkInput) ([]string, error) {
+	if (len(input.IDs) > 0) == (input.Filter != nil) {
+		return nil, fmt.Errorf("%w: exactly one of ids and filter is required", apperrors.ErrInvalidInput)
+	}
+
+	ids := input.IDs
+	if input.Filter != nil {
+		filter := &dto.NoteFilter{
+			UserID:   userID,
+			Query:    input.Filter.Query,
+			Search:   input.Filter.Search,
+			FolderID: input.Filter.FolderID,
+			TagIDs:   input.Filter.TagIDs,
+			Archived: input.Filter.Archived,
+			SortBy:   "created_at",
+			Order:    "asc",
+			Limit:    MaxBulkNotes + 1,
+
+			IncludeSubfolders: input.Filter.IncludeSubfolders,
+		}
+		page, err := s.noteService.GetAllNotesByUserID(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		ids = make([]string, len(page.Notes))
+		for i, note := range page.Notes {
+			ids[i] = note.ID.String()
+		}
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBulkNotes {
+		return nil, fmt.Errorf("%w: at most %d notes per request", apperrors.ErrInvalidInput, MaxBulkNotes)
+	}
+	return unique, nil
+}
+
+// bulkChanges — изменит ли действие заметку
+func bulkChanges(note *models.Note, op *interfaces.BulkOperation) bool {
+	has := make(map[uuid.UUID]bool, len(note.Tags))
+	for _, tag := range note.Tags {
+		has[tag.ID] = true
+	}
+
+	switch op.Action {
+	case dto.BulkArchive:
+		return !note.Archived
+	case dto.BulkUnarchive:
+		return note.Archived
+	case dto.BulkDelete:
+		return true
+	case dto.BulkMove:
+		if note.FolderID == nil || op.FolderID == nil {
+			return note.FolderID != op.FolderID
+		}
+		return *note.FolderID != *op.FolderID
+	case dto.BulkResetScheduling:
+		return note.MemoryLevel != 0 || note.NextReviewAt != nil
+	case dto.BulkAddTags:
+		for _, id := range op.TagIDs {
+			if !has[id] {
+				return true
+			}
+		}
+		return false
+	case dto.BulkRemoveTags:
+		for _, id := range op.TagIDs {
+			if has[id] {
+				return true
+			}
+		}
+		return false
+	case dto.BulkReplaceTags:
+		return !sameTags(note.Tags, op.TagIDs)
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/wikilink"
+)
+
+// maxLinkTextLength — ограничение колонки note_links.text; более длинные [[...]] не считаются ссылками
+const maxLinkTextLength = 255
+
+type NoteLinkService struct {
+	linkRepo interfaces.NoteLinkRepository
+	noteRepo interfaces.NoteRepository
+}
+
+func NewNoteLinkService(linkRepo interfaces.NoteLinkRepository, noteRepo interfaces.NoteRepository) *NoteLinkService {
+	return &NoteLinkService{
+		linkRepo: linkRepo,
+		noteRepo: noteRepo,
+	}
+}
+
+// Sync разбирает [[...]] в тексте заметки и пересохраняет её исходящие ссылки.
+// Заодно привязывает к заметке висячие ссылки на её заголовок.
+// NoteService вызывает его в транзакции записи заметки: ошибка откатывает и саму заметку.
+func (s *NoteLinkService) Sync(ctx context.Context, note *models.Note) error {
+	links := make([]models.NoteLink, 0)
+	for _, text := range wikilink.Targets(note.Content) {
+		if utf8.RuneCountInString(text) > maxLinkTextLength {
+			continue
+		}
+		targetID, err := s.resolve(ctx, note.UserID, text)
+		if err != nil {
+			return err
+		}
+		if targetID != nil && *targetID == note.ID {
+			continue // ссылка на саму себя
+		}
+		links = append(links, models.NoteLink{
+			UserID:   note.UserID,
+			SourceID: note.ID,
+			TargetID: targetID,
+			Text:     text,
+			Position: len(links),
+		})
+	}
+
+	if err := s.linkRepo.ReplaceForNote(ctx, note.ID, links); err != nil {
+		return err
+	}
+	return s.linkRepo.ResolveDangling(ctx, note.UserID, note.Title, note.ID)
+}
+
+// RenameReferences переписывает [[старый заголовок]] на новый в заметках, которые ссылаются на note.
+// Ссылки по id не меняются — они продолжают указывать на заметку. Возвращает изменённые заметки.
+func (s *NoteLinkService) RenameReferences(ctx context.Context, note *models.Note, oldTitle string) ([]*models.Note, error) {
+	backlinks, err := s.linkRepo.ListByTarget(ctx, note.UserID, note.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]*models.Note, 0)
+	for _, link := range backlinks {
+		if id, err := uuid.Parse(link.Text); err == nil && id == note.ID {
+			continue
+		}
+		source, err := s.noteRepo.GetNoteByID(ctx, note.UserID, link.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			continue
+		}
+
+		content, ok := wikilink.Rename(source.Content, oldTitle, note.Title)
+		if !ok {
+			continue
+		}
+		source.Content = content
+		if err := s.noteRepo.UpdateNoteFields(ctx, source, "content"); err != nil {
+			return nil, err
+		}
+		if err := s.Sync(ctx, source); err != nil {
+			return nil, err
+		}
+		changed = append(changed, source)
+	}
+	return changed, nil
+}
+
+// ListLinks возвращает исходящие ссылки заметки
+func (s *NoteLinkService) ListLinks(ctx context.Context, userID, noteID string) ([]dto.NoteLinkInfo, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.linkRepo.ListBySource(ctx, note.UserID, note.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.NoteLinkInfo, len(links))
+	for i, link := range links {
+		result[i] = dto.NoteLinkInfo{Text: link.Text, Dangling: link.Target == nil}
+		if link.Target != nil {
+			result[i].NoteID = link.Target.ID.String()
+			result[i].Title = link.Target.Title
+		}
+	}
+	return result, nil
+}
+
+// ListBacklinks возвращает заметки, которые ссылаются на noteID
+func (s *NoteLinkService) ListBacklinks(ctx context.Context, userID, noteID string) ([]dto.Backlink, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.linkRepo.ListByTarget(ctx, note.UserID, note.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Заметка может ссылаться и по заголовку, и по id — в списке она одна
+	seen := make(map[uuid.UUID]bool)
+	result := make([]dto.Backlink, 0, len(links))
+	for _, link := range links {
+		if seen[link.SourceID] {
+			continue
+		}
+		seen[link.SourceID] = true
+		backlink := dto.Backlink{NoteID: link.SourceID.String(), Text: link.Text}
+		if link.Source != nil {
+			backlink.Title = link.Source.Title
+		}
+		result = append(result, backlink)
+	}
+	return result, nil
+}
+
+// ListDangling возвращает отчёт о висячих ссылках пользователя
+func (s *NoteLinkService) ListDangling(ctx context.Context, userID string) ([]dto.DanglingLink, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.linkRepo.ListDangling(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.DanglingLink, len(links))
+	for i, link := range links {
+		result[i] = dto.DanglingLink{SourceID: link.SourceID.String(), Text: link.Text}
+		if link.Source != nil {
+			result[i].SourceTitle = link.Source.Title
+		}
+	}
+	return result, nil
+}
+
+// resolve находит цель ссылки: сначала по id, затем по заголовку
+func (s *NoteLinkService) resolve(ctx context.Context, userID uuid.UUID, text string) (*uuid.UUID, error) {
+	if id, err := uuid.Parse(text); err == nil {
+		note, err := s.noteRepo.GetNoteByID(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if note != nil {
+			return &note.ID, nil
+		}
+	}
+	return s.linkRepo.FindNoteIDByTitle(ctx, userID, text)
+}
+
+func (s *NoteLinkService) getNote(ctx context.Context, userID, noteID string) (*models.Note, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return note, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+)
+
+// MoveNote меняет место заметки среди заметок той же папки: перед input.Before или после input.After.
+// Записывается только новый ключ порядка перемещаемой заметки (см. placeItem).
+func (s *NoteService) MoveNote(ctx context.Context, userID, noteID string, input dto.MoveInput) (*models.Note, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	anchorID, after, err := moveAnchor(input)
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := s.noteRepo.ListSiblings(ctx, note.UserID, note.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]positioned, len(siblings))
+	for i, n := range siblings {
+		items[i] = positioned{ID: n.ID, Position: n.Position}
+	}
+	positions, err := placeItem(items, note.ID, anchorID, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return note, nil
+	}
+	if err := s.noteRepo.SetPositions(ctx, note.UserID, positions); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if moved == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return moved, nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/jsonpatch"
+	"valibibe/internal/models"
+	"valibibe/internal/notetype"
+)
+
+// maxNoteTitleLen — длина заголовка заметки (как у колонки notes.title)
+const maxNoteTitleLen = 255
+
+// PatchValidationError — результат патча не прошёл проверку; Fields — ошибки по полям документа
+type PatchValidationError struct {
+	Fields map[string]string
+}
+
+func (e *PatchValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + e.Fields[k]
+	}
+	return fmt.Sprintf("%s: %s", apperrors.ErrInvalidInput.Error(), strings.Join(parts, "; "))
+}
+
+func (e *PatchValidationError) Unwrap() error {
+	return apperrors.ErrInvalidInput
+}
+
+// notePatchFields — поля документа патча и их тип для сообщений об ошибках.
+// nullable — поле можно удалить или обнулить.
+var notePatchFields = map[string]struct {
+	kind     string
+	nullable bool
+}{
+	"title":          {kind: "a string"},
+	"content":        {kind: "a string"},
+	"folder_id":      {kind: "a string", nullable: true},
+	"archived":       {kind: "a boolean"},
+	"tag_ids":        {kind: "an array of strings"},
+	"memory_level":   {kind: "an integer"},
+	"next_review_at": {kind: "an RFC 3339 timestamp", nullable: true},
+	"version":        {kind: "an integer"},
+}
+
+// PatchNote применяет к заметке JSON Merge Patch или JSON Patch.
+// Патч применяется к dto.NotePatchDocument текущей заметки; результат проверяется целиком
+// и сохраняется одной транзакцией вместе с тегами, ревизией и ссылками — либо всё, либо ничего.
+// Версия берётся из input.Version (If-Match), иначе из члена version (Merge Patch)
+// или операции test по /version (JSON Patch).
+func (s *NoteService) PatchNote(ctx context.Context, userID, noteID string, input *dto.NotePatchInput) (*models.Note, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	apply, version, err := parseNotePatch(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Version != nil {
+		version = input.Version
+	}
+	if err := checkVersion(version, note.Version); err != nil {
+		if errors.Is(err, apperrors.ErrVersionConflict) {
+			return nil, &VersionConflictError{Current: note, Version: note.Version}
+		}
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.ListTagsByNote(ctx, note.ID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := toPatchValue(notePatchDocument(note, tags))
+	if err != nil {
+		return nil, err
+	}
+	patched, err := apply(current)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.validatePatchedNote(ctx, note, patched)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs, err := s.validatePatchedTags(ctx, note, doc.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if sameTags(tags, tagIDs) {
+		tagIDs = nil
+	}
+
+	// Ревизия и ссылки пишутся в той же транзакции, что и сама заметка
+	err = s.noteRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		// У заметок, созданных до появления истории, сначала фиксируем исходное состояние
+		if err := s.revisionService.RecordBaseline(ctx, note); err != nil {
+			return err
+		}
+
+		oldTitle := note.Title
+		if err := s.applyPatchDocument(ctx, note, doc); err != nil {
+			return err
+		}
+		if err := s.noteRepo.PatchNote(ctx, note, tagIDs); err != nil {
+			return err
+		}
+		return s.afterUpdate(ctx, note, oldTitle)
+	})
+	if errors.Is(err, apperrors.ErrVersionConflict) {
+		return nil, s.noteConflict(ctx, userID, noteID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// parseNotePatch разбирает патч и достаёт из него ожидаемую версию
+func parseNotePatch(input *dto.NotePatchInput) (func(interface{}) (interface{}, error), *int, error) {
+	switch input.Format {
+	case dto.PatchFormatMerge:
+		patch, err := jsonpatch.Decode(input.Patch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: patch is not valid JSON", apperrors.ErrInvalidInput)
+		}
+		var version *int
+		if obj, ok := patch.(map[string]interface{}); ok {
+			if raw, ok := obj["version"]; ok {
+				v, ok := integer(raw)
+				if !ok {
+					return nil, nil, &PatchValidationError{Fields: map[string]string{"version": "must be an integer"}}
+				}
+				version = &v
+				delete(obj, "version")
+			}
+		}
+		return func(doc interface{}) (interface{}, error) {
+			return jsonpatch.MergePatch(doc, patch), nil
+		}, version, nil
+
+	case dto.PatchFormatJSON:
+		ops, err := jsonpatch.ParseOperations(input.Patch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidInput, err)
+		}
+		var version *int
+		for _, op := range ops {
+			if op.Op == jsonpatch.OpTest && op.Path == "/version" {
+				if v, ok := integer(op.Value); ok {
+					version = &v
+				}
+				break
+			}
+		}
+		return func(doc interface{}) (interface{}, error) {
+			patched, err := jsonpatch.Apply(doc, ops)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidInput, err)
+			}
+			return patched, nil
+		}, version, nil
+	}
+	return nil, nil, fmt.Errorf("%w: unsupported patch format %q", apperrors.ErrInvalidInput, input.Format)
+}
+
+// validatePatchedNote проверяет документ после патча: состав и типы полей, заголовок, текст, папку и уровень памяти
+func (s *NoteService) validatePatchedNote(ctx context.Context, note *models.Note, patched interface{}) (*dto.NotePatchDocument, error) {
+	obj, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, &PatchValidationError{Fields: map[string]string{"document": "must be an object"}}
+	}
+
+	fieldErrors := make(map[string]string)
+	for key := range obj {
+		if _, ok := notePatchFields[key]; !ok {
+			fieldErrors[key] = "unknown field"
+		}
+	}
+
+	var doc dto.NotePatchDocument
+	targets := map[string]interface{}{
+		"title":          &doc.Title,
+		"content":        &doc.Content,
+		"folder_id":      &doc.FolderID,
+		"archived":       &doc.Archived,
+		"tag_ids":        &doc.TagIDs,
+		"memory_level":   &doc.MemoryLevel,
+		"next_review_at": &doc.NextReviewAt,
+		"version":        &doc.Version,
+	}
+	for key, field := range notePatchFields {
+		raw, present := obj[key]
+		if !present || raw == nil {
+			if !field.nullable {
+				fieldErrors[key] = "is required"
+			}
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err == nil {
+			err = json.Unmarshal(data, targets[key])
+		}
+		if err != nil {
+			fieldErrors[key] = "must be " + field.kind
+		}
+	}
+	if _, failed := fieldErrors["version"]; !failed && doc.Version != note.Version {
+		fieldErrors["version"] = "is read-only"
+	}
+
+	if _, failed := fieldErrors["title"]; !failed {
+		if strings.TrimSpace(doc.Title) == "" {
+			fieldErrors["title"] = "must not be empty"
+		} else if utf8.RuneCountInString(doc.Title) > maxNoteTitleLen {
+			fieldErrors["title"] = fmt.Sprintf("must be at most %d characters", maxNoteTitleLen)
+		}
+	}
+	if _, failed := fieldErrors["content"]; !failed && doc.Content == "" && note.NoteTypeID == nil {
+		fieldErrors["content"] = "must not be empty"
+	}
+	if _, failed := fieldErrors["memory_level"]; !failed && (doc.MemoryLevel < 0 || doc.MemoryLevel > 100) {
+		fieldErrors["memory_level"] = "must be between 0 and 100"
+	}
+	if _, failed := fieldErrors["folder_id"]; !failed && doc.FolderID != nil {
+		if _, err := uuid.Parse(*doc.FolderID); err != nil {
+			fieldErrors["folder_id"] = "must be a valid id"
+		} else {
+			folder, err := s.folderRepo.GetByID(ctx, note.UserID.String(), *doc.FolderID)
+			if err != nil {
+				return nil, err
+			}
+			if folder == nil {
+				fieldErrors["folder_id"] = "folder not found"
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &PatchValidationError{Fields: fieldErrors}
+	}
+	return &doc, nil
+}
+
+// validatePatchedTags проверяет, что все теги принадлежат пользователю; повторы убираются
+func (s *NoteService) validatePatchedTags(ctx context.Context, note *models.Note, ids []string) ([]uuid.UUID, error) {
+	tagIDs := make([]uuid.UUID, 0, len(ids))
+	unique := make([]string, 0, len(ids))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		tid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, &PatchValidationError{Fields: map[string]string{"tag_ids": fmt.Sprintf("%q is not a valid id", id)}}
+		}
+		if !seen[tid] {
+			seen[tid] = true
+			tagIDs = append(tagIDs, tid)
+			unique = append(unique, tid.String())
+		}
+	}
+	if len(unique) == 0 {
+		return tagIDs, nil
+	}
+
+	count, err := s.tagRepo.CountTagsByIDsAndUserID(ctx, unique, note.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+	if count != len(unique) {
+		return nil, &PatchValidationError{Fields: map[string]string{"tag_ids": "tag not found"}}
+	}
+	return tagIDs, nil
+}
+
+// applyPatchDocument переносит проверенный документ в заметку
+func (s *NoteService) applyPatchDocument(ctx context.Context, note *models.Note, doc *dto.NotePatchDocument) error {
+	note.Title = doc.Title
+	note.Content = doc.Content
+	if note.Content == "" {
+		// У заметок с типом текст собирается из полей, как при обновлении через PUT
+		noteType, err := s.noteTypeService.ForNote(ctx, note)
+		if err != nil {
+			return err
+		}
+		note.Content = notetype.Content(noteType.Fields, note.Fields)
+	}
+
+	note.FolderID = nil
+	if doc.FolderID != nil {
+		folderID := uuid.MustParse(*doc.FolderID)
+		note.FolderID = &folderID
+	}
+	note.Archived = doc.Archived
+	note.MemoryLevel = doc.MemoryLevel
+	note.NextReviewAt = doc.NextReviewAt
+	return nil
+}
+
+// notePatchDocument — текущее состояние заметки в виде документа патча; теги упорядочены по ID
+func notePatchDocument(note *models.Note, tags []models.Tag) *dto.NotePatchDocument {
+	doc := &dto.NotePatchDocument{
+		Title:        note.Title,
+		Content:      note.Content,
+		Archived:     note.Archived,
+		TagIDs:       make([]string, len(tags)),
+		MemoryLevel:  note.MemoryLevel,
+		NextReviewAt: note.NextReviewAt,
+		Version:      note.Version,
+	}
+	if note.FolderID != nil {
+		folderID := note.FolderID.String()
+		doc.FolderID = &folderID
+	}
+	for i, tag := range tags {
+		doc.TagIDs[i] = tag.ID.String()
+	}
+	sort.Strings(doc.TagIDs)
+	return doc
+}
+
+func toPatchValue(doc *dto.NotePatchDocument) (interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.Decode(data)
+}
+
+func sameTags(tags []models.Tag, ids []uuid.UUID) bool {
+	if len(tags) != len(ids) {
+		return false
+	}
+	current := make(map[uuid.UUID]bool, len(tags))
+	for _, tag := range tags {
+		current[tag.ID] = true
+	}
+	for _, id := range ids {
+		if !current[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// integer возвращает значение JSON-числа, если оно целое
+func integer(v interface{}) (int, bool) {
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return 0, false
+	}
+	return int(f), true
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/diff"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// DefaultRevisionLimit — сколько последних ревизий хранится для каждой заметки
+const DefaultRevisionLimit = 50
+
+type NoteRevisionService struct {
+	revisionRepo interfaces.NoteRevisionRepository
+	noteRepo     interfaces.NoteRepository
+	limit        int
+}
+
+// NewNoteRevisionService создаёт сервис истории правок; limit <= 0 — хранить все ревизии
+func NewNoteRevisionService(revisionRepo interfaces.NoteRevisionRepository, noteRepo interfaces.NoteRepository, limit int) *NoteRevisionService {
+	return &NoteRevisionService{
+		revisionRepo: revisionRepo,
+		noteRepo:     noteRepo,
+		limit:        limit,
+	}
+}
+
+// Record сохраняет текущее состояние заметки как новую ревизию, если оно отличается от последней
+func (s *NoteRevisionService) Record(ctx context.Context, note *models.Note, restoredFrom *int) error {
+	latest, err := s.revisionRepo.GetLatest(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Title == note.Title && latest.Content == note.Content && restoredFrom == nil {
+		return nil
+	}
+
+	revision := &models.NoteRevision{
+		NoteID:       note.ID,
+		UserID:       note.UserID,
+		Title:        note.Title,
+		Content:      note.Content,
+		RestoredFrom: restoredFrom,
+	}
+	if err := s.revisionRepo.Create(ctx, revision); err != nil {
+		return err
+	}
+
+	if s.limit > 0 {
+		return s.revisionRepo.Prune(ctx, note.ID, s.limit)
+	}
+	return nil
+}
+
+// RecordBaseline сохраняет исходное состояние заметки, у которой ещё нет истории
+// (заметки, созданные до появления ревизий)
+func (s *NoteRevisionService) RecordBaseline(ctx context.Context, note *models.Note) error {
+	latest, err := s.revisionRepo.GetLatest(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		return nil
+	}
+	return s.Record(ctx, note, nil)
+}
+
+// ListRevisions возвращает историю ревизий заметки, начиная с последней
+func (s *NoteRevisionService) ListRevisions(ctx context.Context, userID, noteID string) ([]dto.NoteRevisionInfo, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.ListByNote(ctx, note.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.NoteRevisionInfo, 0, len(revisions))
+	for i, rev := range revisions {
+		result = append(result, dto.NoteRevisionInfo{
+			Revision:     rev.Revision,
+			Title:        rev.Title,
+			RestoredFrom: rev.RestoredFrom,
+			Current:      i == 0,
+			CreatedAt:    rev.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// GetRevision возвращает ревизию целиком
+func (s *NoteRevisionService) GetRevision(ctx context.Context, userID, noteID string, revision int) (*models.NoteRevision, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRevision(ctx, note, revision)
+}
+
+// DiffRevisions строит построчный дифф между ревизиями from и to.
+// to == 0 означает последнюю ревизию.
+func (s *NoteRevisionService) DiffRevisions(ctx context.Context, userID, noteID string, from, to int) (*dto.NoteRevisionDiff, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newer *models.NoteRevision
+	if to == 0 {
+		newer, err = s.revisionRepo.GetLatest(ctx, note.ID)
+		if err == nil && newer == nil {
+			err = apperrors.ErrNotFound
+		}
+	} else {
+		newer, err = s.getRevision(ctx, note, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	older, err := s.getRevision(ctx, note, from)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := diff.Lines(older.Content, newer.Content)
+	added, removed := diff.Stats(lines)
+
+	return &dto.NoteRevisionDiff{
+		NoteID:   note.ID.String(),
+		From:     older.Revision,
+		To:       newer.Revision,
+		OldTitle: older.Title,
+		NewTitle: newer.Title,
+		Added:    added,
+		Removed:  removed,
+		Lines:    lines,
+	}, nil
+}
+
+// RestoreRevision возвращает заметке заголовок и текст ревизии.
+// История не переписывается: восстановление записывается новой ревизией.
+func (s *NoteRevisionService) RestoreRevision(ctx context.Context, userID, noteID string, revision int) (*models.Note, error) {
+	note, err := s.getNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.getRevision(ctx, note, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	note.Title = rev.Title
+	note.Content = rev.Content
+	if err := s.noteRepo.UpdateNoteFields(ctx, note, "title", "content"); err != nil {
+		return nil, err
+	}
+
+	restoredFrom := rev.Revision
+	if err := s.Record(ctx, note, &restoredFrom); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+func (s *NoteRevisionService) getNote(ctx context.Context, userID, noteID string) (*models.Note, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return note, nil
+}
+
+func (s *NoteRevisionService) getRevision(ctx context.Context, note *models.Note, revision int) (*models.NoteRevision, error) {
+	if revision <= 0 {
+		return nil, fmt.Errorf("%w: revision must be positive", apperrors.ErrInvalidInput)
+	}
+	rev, err := s.revisionRepo.GetByNumber(ctx, note.ID, revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return rev, nil
+}
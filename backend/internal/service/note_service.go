@@ -2,22 +2,51 @@ package service
 
 import (
     "context"
+    "errors"
 	apperrors "valibibe/internal/errors"
     "fmt"
     "time"
 
     "github.com/google/uuid"
     "valibibe/internal/models"
+    "valibibe/internal/notequery"
+    "valibibe/internal/notetype"
 	"valibibe/internal/controller/dto"
     "valibibe/internal/repository/interfaces"
+    "valibibe/internal/scheduler"
 )
 
 type NoteService struct {
-    noteRepo interfaces.NoteRepository
+    noteRepo         interfaces.NoteRepository
+    reviewLogRepo    interfaces.ReviewLogRepository
+    revisionService  *NoteRevisionService
+    linkService      *NoteLinkService
+    noteTypeService  *NoteTypeService
+    duplicateService *DuplicateService
+    folderRepo       interfaces.FolderRepository
+    tagRepo          interfaces.TagRepository
 }
 
-func NewNoteService(noteRepo interfaces.NoteRepository) *NoteService {
-    return &NoteService{noteRepo: noteRepo}
+func NewNoteService(
+    noteRepo interfaces.NoteRepository,
+    reviewLogRepo interfaces.ReviewLogRepository,
+    revisionService *NoteRevisionService,
+    linkService *NoteLinkService,
+    noteTypeService *NoteTypeService,
+    duplicateService *DuplicateService,
+    folderRepo interfaces.FolderRepository,
+    tagRepo interfaces.TagRepository,
+) *NoteService {
+    return &NoteService{
+        noteRepo:         noteRepo,
+        reviewLogRepo:    reviewLogRepo,
+        revisionService:  revisionService,
+        linkService:      linkService,
+        noteTypeService:  noteTypeService,
+        duplicateService: duplicateService,
+        folderRepo:       folderRepo,
+        tagRepo:          tagRepo,
+    }
 }
 
 func (s *NoteService) CreateNote(ctx context.Context, userID string, input *dto.NoteInput) (*models.Note, error) {
@@ -29,19 +58,58 @@ func (s *NoteService) CreateNote(ctx context.Context, userID string, input *dto.
     note := &models.Note{
         UserID:      uid,
         Title:       input.Title,
-        Content:     input.Content,
         MemoryLevel: 0,
         Archived:    false,
     }
+    if err := s.applyNoteType(ctx, note, input); err != nil {
+        return nil, err
+    }
 
-    err = s.noteRepo.CreateNote(ctx, note)
+    duplicateIDs, err := s.checkDuplicates(ctx, note, input.OnDuplicate)
     if err != nil {
         return nil, err
     }
 
+    // Заметка, её первая ревизия и ссылки пишутся одной транзакцией: у каждой заметки есть ревизия 1
+    err = s.noteRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.noteRepo.CreateNote(ctx, note); err != nil {
+            return err
+        }
+        // Первая ревизия — исходный текст заметки
+        if err := s.revisionService.Record(ctx, note, nil); err != nil {
+            return err
+        }
+        return s.linkService.Sync(ctx, note)
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    note.DuplicateIDs = duplicateIDs
     return note, nil
 }
 
+// checkDuplicates ищет похожие заметки перед созданием.
+// В режиме reject возвращает *DuplicateNoteError, в режиме warn — ID похожих заметок.
+func (s *NoteService) checkDuplicates(ctx context.Context, note *models.Note, mode string) ([]string, error) {
+    switch mode {
+    case "", dto.OnDuplicateWarn, dto.OnDuplicateReject:
+    case dto.OnDuplicateAllow:
+        return nil, nil
+    default:
+        return nil, fmt.Errorf("%w: on_duplicate must be warn, reject or allow", apperrors.ErrInvalidInput)
+    }
+
+    ids, err := s.duplicateService.FindDuplicates(ctx, note.UserID, note.Title, note.Content)
+    if err != nil {
+        return nil, err
+    }
+    if len(ids) > 0 && mode == dto.OnDuplicateReject {
+        return nil, &DuplicateNoteError{NoteIDs: ids}
+    }
+    return ids, nil
+}
+
 func (s *NoteService) GetNoteByID(ctx context.Context, userID, noteID string) (*models.Note, error) {
     note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
     if err != nil {
@@ -54,9 +122,26 @@ func (s *NoteService) GetNoteByID(ctx context.Context, userID, noteID string) (*
 }
 
 func (s *NoteService) GetAllNotesByUserID(ctx context.Context, filter *dto.NoteFilter) (*dto.PaginatedNotes, error) {
+    if err := parseNoteQuery(filter); err != nil {
+        return nil, err
+    }
     return s.noteRepo.GetAllNotesByUserID(ctx, filter)
 }
 
+// parseNoteQuery разбирает q в AST фильтра.
+// Синтаксическая ошибка возвращается как *notequery.SyntaxError с позицией.
+func parseNoteQuery(filter *dto.NoteFilter) error {
+    if filter.Query == "" || filter.Expr != nil {
+        return nil
+    }
+    expr, err := notequery.Parse(filter.Query)
+    if err != nil {
+        return err
+    }
+    filter.Expr = expr
+    return nil
+}
+
 func (s *NoteService) UpdateNote(ctx context.Context, userID, noteID string, input *dto.NoteInput) (*models.Note, error) {
     note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
     if err != nil {
@@ -66,17 +151,116 @@ func (s *NoteService) UpdateNote(ctx context.Context, userID, noteID string, inp
         return nil, apperrors.ErrNotFound
     }
 
-    note.Title = input.Title
-    note.Content = input.Content
+    if err := checkVersion(input.Version, note.Version); err != nil {
+        if errors.Is(err, apperrors.ErrVersionConflict) {
+            return nil, &VersionConflictError{Current: note, Version: note.Version}
+        }
+        return nil, err
+    }
 
-    err = s.noteRepo.UpdateNote(ctx, note)
+    // Ревизия и ссылки пишутся в той же транзакции, что и сама заметка
+    err = s.noteRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+        // У заметок, созданных до появления истории, сначала фиксируем исходное состояние
+        if err := s.revisionService.RecordBaseline(ctx, note); err != nil {
+            return err
+        }
+
+        oldTitle := note.Title
+        note.Title = input.Title
+        if err := s.applyNoteType(ctx, note, input); err != nil {
+            return err
+        }
+        if err := s.noteRepo.UpdateNote(ctx, note); err != nil {
+            return err
+        }
+        return s.afterUpdate(ctx, note, oldTitle)
+    })
+    if errors.Is(err, apperrors.ErrVersionConflict) {
+        // Заметку изменили между чтением и записью
+        return nil, s.noteConflict(ctx, userID, noteID)
+    }
     if err != nil {
         return nil, err
     }
-
     return note, nil
 }
 
+// afterUpdate сохраняет ревизию и обновляет ссылки после изменения заметки
+func (s *NoteService) afterUpdate(ctx context.Context, note *models.Note, oldTitle string) error {
+    if err := s.revisionService.Record(ctx, note, nil); err != nil {
+        return err
+    }
+
+    if err := s.linkService.Sync(ctx, note); err != nil {
+        return err
+    }
+
+    // При переименовании ссылки [[старый заголовок]] в других заметках переписываются
+    if oldTitle != note.Title {
+        sources, err := s.linkService.RenameReferences(ctx, note, oldTitle)
+        if err != nil {
+            return err
+        }
+        for _, source := range sources {
+            if err := s.revisionService.Record(ctx, source, nil); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// noteConflict перечитывает заметку для ответа о конфликте версий
+func (s *NoteService) noteConflict(ctx context.Context, userID, noteID string) error {
+    current, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+    if err != nil {
+        return err
+    }
+    if current == nil {
+        return apperrors.ErrNotFound
+    }
+    return &VersionConflictError{Current: current, Version: current.Version}
+}
+
+// applyNoteType проверяет тип и значения полей из ввода и переносит их в заметку.
+// Без note_type_id в вводе заметка сохраняет текущий тип.
+func (s *NoteService) applyNoteType(ctx context.Context, note *models.Note, input *dto.NoteInput) error {
+    var noteType *models.NoteType
+    var err error
+    if input.NoteTypeID != nil {
+        noteType, err = s.noteTypeService.Resolve(ctx, note.UserID, *input.NoteTypeID)
+    } else {
+        noteType, err = s.noteTypeService.ForNote(ctx, note)
+    }
+    if err != nil {
+        return err
+    }
+
+    if noteType.Builtin {
+        if len(input.Fields) > 0 {
+            return fmt.Errorf("%w: fields are not used by %s notes", apperrors.ErrInvalidInput, notetype.BasicName)
+        }
+        if input.Content == "" {
+            return fmt.Errorf("%w: content is required", apperrors.ErrInvalidInput)
+        }
+        note.NoteTypeID = nil
+        note.Fields = nil
+        note.Content = input.Content
+        return nil
+    }
+
+    if err := notetype.ValidateValues(noteType.Fields, input.Fields); err != nil {
+        return fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+    }
+    note.NoteTypeID = &noteType.ID
+    note.Fields = input.Fields
+    note.Content = input.Content
+    if note.Content == "" {
+        note.Content = notetype.Content(noteType.Fields, input.Fields)
+    }
+    return nil
+}
+
 func (s *NoteService) ArchiveNote(ctx context.Context, userID, noteID string) (*models.Note, error) {
     note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
     if err != nil {
@@ -88,7 +272,7 @@ func (s *NoteService) ArchiveNote(ctx context.Context, userID, noteID string) (*
 
     note.Archived = true
 
-    err = s.noteRepo.UpdateNote(ctx, note)
+    err = s.noteRepo.UpdateNoteFields(ctx, note, "archived")
     if err != nil {
         return nil, err
     }
@@ -107,7 +291,7 @@ func (s *NoteService) UnArchiveNote(ctx context.Context, userID, noteID string)
 
     note.Archived = false
 
-    err = s.noteRepo.UpdateNote(ctx, note)
+    err = s.noteRepo.UpdateNoteFields(ctx, note, "archived")
     if err != nil {
         return nil, err
     }
@@ -115,6 +299,8 @@ func (s *NoteService) UnArchiveNote(ctx context.Context, userID, noteID string)
     return note, nil
 }
 
+// DeleteNote уносит заметку в корзину: теги, ссылки и ревизии остаются до очистки корзины,
+// чтобы восстановление вернуло всё как было. Массовое удаление идёт через этот же метод
 func (s *NoteService) DeleteNote(ctx context.Context, userID, noteID string) error {
     note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
     if err != nil {
@@ -136,48 +322,37 @@ func (s *NoteService) UpdateMemoryLevel(ctx context.Context, userID, noteID stri
         return apperrors.ErrNotFound
     }
 
-    if !remembered {
-        note.MemoryLevel = 0
-        note.NextReviewAt = nil
-    } else {
-        note.MemoryLevel += 20
-        if note.MemoryLevel > 100 {
-            note.MemoryLevel = 100
-        }
-        nextReview := calcNextReviewAt(note.MemoryLevel)
-        fmt.Printf("Calculated next review: %v\n", nextReview)
-        note.NextReviewAt = nextReview
-    }
-
-    err = s.noteRepo.UpdateNote(ctx, note)
+    // Интервалы и шаги обучения — по действующим настройкам папки заметки
+    settings, err := noteSettings(ctx, s.folderRepo, userID, note.FolderID)
     if err != nil {
         return err
     }
+    reviewLog := applyReview(note, remembered, models.ReviewSourceReview, settings)
 
-    updatedNote, _ := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
-    fmt.Printf("Saved in DB - MemoryLevel: %d, NextReviewAt: %v\n",
-        updatedNote.MemoryLevel, updatedNote.NextReviewAt)
-
-    return nil
+    // Расписание и запись журнала сохраняются вместе: повторение без записи исказило бы статистику
+    return s.noteRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.noteRepo.UpdateNoteFields(ctx, note, "memory_level", "next_review_at"); err != nil {
+            return err
+        }
+        // Журнал повторений нужен для статистики вспоминания и симулятора нагрузки
+        return s.reviewLogRepo.Create(ctx, reviewLog)
+    })
 }
 
-func calcNextReviewAt(level int) *time.Time {
-    var days int
-    switch {
-    case level < 20:
-        days = 1
-    case level < 40:
-        days = 3
-    case level < 60:
-        days = 5
-    case level < 80:
-        days = 10
-    default:
-        days = 30
+// applyReview меняет уровень памяти и дату следующего повторения по результату ответа
+// с настройками settings и возвращает запись для журнала повторений
+func applyReview(note *models.Note, remembered bool, source string, settings scheduler.Settings) *models.ReviewLog {
+    now := time.Now()
+    reviewLog := &models.ReviewLog{
+        UserID:      note.UserID,
+        NoteID:      note.ID,
+        Remembered:  remembered,
+        LevelBefore: note.MemoryLevel,
+        Source:      source,
+        ReviewedAt:  now,
     }
-    t := time.Now().AddDate(0, 0, days)
-    return &t
-}
-
-
 
+    note.MemoryLevel, note.NextReviewAt = settings.NextReviewAt(note.MemoryLevel, remembered, now)
+    reviewLog.LevelAfter = note.MemoryLevel
+    return reviewLog
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/notetype"
+	"valibibe/internal/repository/interfaces"
+)
+
+// maxNoteTypeNameLen — длина имени типа (как у колонки note_types.name)
+const maxNoteTypeNameLen = 100
+
+type NoteTypeService struct {
+	repo interfaces.NoteTypeRepository
+}
+
+func NewNoteTypeService(repo interfaces.NoteTypeRepository) *NoteTypeService {
+	return &NoteTypeService{repo: repo}
+}
+
+func (s *NoteTypeService) CreateNoteType(ctx context.Context, userID string, input *dto.NoteTypeInput) (*models.NoteType, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteType := &models.NoteType{UserID: uid}
+	if err := s.apply(ctx, noteType, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, noteType); err != nil {
+		return nil, err
+	}
+	return noteType, nil
+}
+
+// ListNoteTypes возвращает встроенный Basic и типы пользователя по алфавиту
+func (s *NoteTypeService) ListNoteTypes(ctx context.Context, userID string) ([]models.NoteType, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes, err := s.repo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.NoteType{*notetype.Basic()}, noteTypes...), nil
+}
+
+func (s *NoteTypeService) GetNoteType(ctx context.Context, userID, typeID string) (*models.NoteType, error) {
+	return s.getOwned(ctx, userID, typeID)
+}
+
+// UpdateNoteType заменяет имя, поля и шаблоны типа.
+// Значения удалённых полей остаются в заметках, но в карточки больше не попадают.
+func (s *NoteTypeService) UpdateNoteType(ctx context.Context, userID, typeID string, input *dto.NoteTypeInput) (*models.NoteType, error) {
+	noteType, err := s.getOwned(ctx, userID, typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, noteType, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, noteType); err != nil {
+		return nil, err
+	}
+	return noteType, nil
+}
+
+// DeleteNoteType удаляет тип, если на него не ссылается ни одна заметка (в том числе в корзине)
+func (s *NoteTypeService) DeleteNoteType(ctx context.Context, userID, typeID string) error {
+	noteType, err := s.getOwned(ctx, userID, typeID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountNotes(ctx, noteType.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrNoteTypeInUse
+	}
+	return s.repo.Delete(ctx, noteType.UserID, noteType.ID)
+}
+
+// Resolve находит тип по ID из ввода заметки: пустой ID и нулевой UUID означают Basic.
+// Неизвестный тип — ошибка ввода, а не 404: не найден не ресурс запроса, а его поле.
+func (s *NoteTypeService) Resolve(ctx context.Context, userID uuid.UUID, typeID string) (*models.NoteType, error) {
+	if typeID == "" {
+		return notetype.Basic(), nil
+	}
+	id, err := uuid.Parse(typeID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid note_type_id", apperrors.ErrInvalidInput)
+	}
+	if id == uuid.Nil {
+		return notetype.Basic(), nil
+	}
+
+	noteType, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if noteType == nil {
+		return nil, fmt.Errorf("%w: unknown note_type_id", apperrors.ErrInvalidInput)
+	}
+	return noteType, nil
+}
+
+// ForNote возвращает текущий тип заметки
+func (s *NoteTypeService) ForNote(ctx context.Context, note *models.Note) (*models.NoteType, error) {
+	if note.NoteTypeID == nil {
+		return notetype.Basic(), nil
+	}
+	return s.Resolve(ctx, note.UserID, note.NoteTypeID.String())
+}
+
+// getOwned находит пользовательский тип; встроенный Basic не редактируется и не удаляется
+func (s *NoteTypeService) getOwned(ctx context.Context, userID, typeID string) (*models.NoteType, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(typeID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	noteType, err := s.repo.GetByID(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if noteType == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return noteType, nil
+}
+
+// apply проверяет ввод и переносит его в модель
+func (s *NoteTypeService) apply(ctx context.Context, noteType *models.NoteType, input *dto.NoteTypeInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", apperrors.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > maxNoteTypeNameLen {
+		return fmt.Errorf("%w: name is longer than %d characters", apperrors.ErrInvalidInput, maxNoteTypeNameLen)
+	}
+	if strings.EqualFold(name, notetype.BasicName) {
+		return fmt.Errorf("%w: %q is a built-in note type", apperrors.ErrDuplicateName, notetype.BasicName)
+	}
+
+	prompt, answer := input.PromptTemplate, input.AnswerTemplate
+	if strings.TrimSpace(prompt) == "" && strings.TrimSpace(answer) == "" {
+		prompt, answer = notetype.DefaultTemplates(input.Fields)
+	}
+	if err := notetype.ValidateDefinition(input.Fields, prompt, answer); err != nil {
+		return fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+
+	var excludeID *uuid.UUID
+	if noteType.ID != uuid.Nil {
+		excludeID = &noteType.ID
+	}
+	exists, err := s.repo.ExistsByName(ctx, noteType.UserID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperrors.ErrDuplicateName
+	}
+
+	noteType.Name = name
+	noteType.Fields = input.Fields
+	noteType.PromptTemplate = prompt
+	noteType.AnswerTemplate = answer
+	return nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/fractional"
+)
+
+// positioned — элемент ручного порядка: папка или заметка
+type positioned struct {
+	ID       uuid.UUID
+	Position string
+}
+
+// moveAnchor разбирает before/after: возвращает соседа и признак "после него"; nil — в конец
+func moveAnchor(input dto.MoveInput) (*uuid.UUID, bool, error) {
+	if input.Before != nil && input.After != nil {
+		return nil, false, fmt.Errorf("%w: only one of before and after is allowed", apperrors.ErrInvalidInput)
+	}
+	value, after := input.Before, false
+	if input.After != nil {
+		value, after = input.After, true
+	}
+	if value == nil {
+		return nil, false, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: invalid sibling id %q", apperrors.ErrInvalidInput, *value)
+	}
+	return &id, after, nil
+}
+
+// placeItem ставит movedID перед anchorID (после — при after, в конец — при anchorID == nil)
+// и возвращает изменившиеся позиции. items — соседи в текущем порядке, включая movedID.
+// Обычно меняется только ключ перемещаемого элемента; если ключи соседей совпадают
+// и между ними ничего не вставить, ключи всех соседей расставляются заново.
+func placeItem(items []positioned, movedID uuid.UUID, anchorID *uuid.UUID, after bool) (map[uuid.UUID]string, error) {
+	if anchorID != nil && *anchorID == movedID {
+		return nil, fmt.Errorf("%w: cannot move an item relative to itself", apperrors.ErrInvalidInput)
+	}
+
+	order := make([]positioned, 0, len(items))
+	var moved *positioned
+	for i := range items {
+		if items[i].ID == movedID {
+			moved = &items[i]
+			continue
+		}
+		order = append(order, items[i])
+	}
+	if moved == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	idx := len(order)
+	if anchorID != nil {
+		idx = -1
+		for i, item := range order {
+			if item.ID == *anchorID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: sibling must have the same parent", apperrors.ErrInvalidInput)
+		}
+		if after {
+			idx++
+		}
+	}
+
+	reordered := make([]positioned, 0, len(items))
+	reordered = append(reordered, order[:idx]...)
+	reordered = append(reordered, *moved)
+	reordered = append(reordered, order[idx:]...)
+	if samePlaces(items, reordered) {
+		return map[uuid.UUID]string{}, nil
+	}
+
+	var lower, upper string
+	if idx > 0 {
+		lower = order[idx-1].Position
+	}
+	if idx < len(order) {
+		upper = order[idx].Position
+	}
+	if key, err := fractional.Between(lower, upper); err == nil {
+		return map[uuid.UUID]string{movedID: key}, nil
+	}
+
+	keys, err := fractional.Sequence("", "", len(reordered))
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[uuid.UUID]string)
+	for i, item := range reordered {
+		if item.Position != keys[i] {
+			positions[item.ID] = keys[i]
+		}
+	}
+	return positions, nil
+}
+
+// appendPosition возвращает ключ после последнего из items
+func appendPosition(items []positioned) (string, error) {
+	var last string
+	for _, item := range items {
+		if fractional.Validate(item.Position) == nil && item.Position > last {
+			last = item.Position
+		}
+	}
+	return fractional.Between(last, "")
+}
+
+func samePlaces(a, b []positioned) bool {
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+type PrerequisiteService struct {
+	prerequisiteRepo interfaces.PrerequisiteRepository
+	noteRepo         interfaces.NoteRepository
+	folderRepo       interfaces.FolderRepository
+}
+
+func NewPrerequisiteService(
+	prerequisiteRepo interfaces.PrerequisiteRepository,
+	noteRepo interfaces.NoteRepository,
+	folderRepo interfaces.FolderRepository,
+) *PrerequisiteService {
+	return &PrerequisiteService{
+		prerequisiteRepo: prerequisiteRepo,
+		noteRepo:         noteRepo,
+		folderRepo:       folderRepo,
+	}
+}
+
+// AddPrerequisite объявляет заметку input.PrerequisiteID пререквизитом noteID
+func (s *PrerequisiteService) AddPrerequisite(ctx context.Context, userID, noteID string, input *dto.PrerequisiteInput) (*dto.Prerequisite, error) {
+	if noteID == input.PrerequisiteID {
+		return nil, fmt.Errorf("%w: note cannot be its own prerequisite", apperrors.ErrInvalidInput)
+	}
+
+	// 1. Проверить, что обе заметки принадлежат пользователю
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	prerequisite, err := s.noteRepo.GetNoteByIDAndUserID(ctx, input.PrerequisiteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prerequisite == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	requiredLevel := models.DefaultPrerequisiteLevel
+	if input.RequiredLevel != nil {
+		requiredLevel = *input.RequiredLevel
+	}
+	link := &models.NotePrerequisite{
+		NoteID:         note.ID,
+		PrerequisiteID: prerequisite.ID,
+		UserID:         note.UserID,
+		RequiredLevel:  requiredLevel,
+	}
+
+	// 2–3. Проверка цикла и сохранение ребра — в одной транзакции под блокировкой графа,
+	// иначе две встречные вставки могли бы вместе замкнуть цикл
+	err = s.prerequisiteRepo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.prerequisiteRepo.LockGraph(ctx, note.UserID); err != nil {
+			return err
+		}
+		links, err := s.prerequisiteRepo.ListByUser(ctx, note.UserID)
+		if err != nil {
+			return err
+		}
+		if dependsOn(links, prerequisite.ID, note.ID) {
+			return apperrors.ErrPrerequisiteCycle
+		}
+		return s.prerequisiteRepo.Create(ctx, link)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link.Prerequisite = prerequisite
+	result := toPrerequisiteDTO(*link)
+	return &result, nil
+}
+
+// RemovePrerequisite удаляет ребро графа
+func (s *PrerequisiteService) RemovePrerequisite(ctx context.Context, userID, noteID, prerequisiteID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+	nid, err := uuid.Parse(noteID)
+	if err != nil {
+		return apperrors.ErrNotFound
+	}
+	pid, err := uuid.Parse(prerequisiteID)
+	if err != nil {
+		return apperrors.ErrNotFound
+	}
+
+	return s.prerequisiteRepo.Delete(ctx, uid, nid, pid)
+}
+
+// GetPrerequisites возвращает прямые пререквизиты заметки
+func (s *PrerequisiteService) GetPrerequisites(ctx context.Context, userID, noteID string) ([]dto.Prerequisite, error) {
+	note, err := s.noteRepo.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	links, err := s.prerequisiteRepo.ListByNote(ctx, note.UserID, note.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Prerequisite, 0, len(links))
+	for _, link := range links {
+		result = append(result, toPrerequisiteDTO(link))
+	}
+	return result, nil
+}
+
+// GetFolderGraph строит граф зависимостей заметок папки.
+// В граф попадают и заметки из других папок, если они связаны с заметками этой папки.
+func (s *PrerequisiteService) GetFolderGraph(ctx context.Context, userID, folderID string) (*dto.PrerequisiteGraph, error) {
+	folder, err := s.folderRepo.GetByID(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	notes, err := s.noteRepo.GetAllNotesByUserID(ctx, &dto.NoteFilter{
+		UserID:   userID,
+		FolderID: &folderID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.prerequisiteRepo.ListByFolder(ctx, folder.UserID, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &dto.PrerequisiteGraph{
+		Nodes: []dto.PrerequisiteGraphNode{},
+		Edges: make([]dto.PrerequisiteGraphEdge, 0, len(links)),
+	}
+	seen := make(map[uuid.UUID]struct{})
+	addNode := func(n *models.Note) {
+		if n == nil {
+			return
+		}
+		if _, ok := seen[n.ID]; ok {
+			return
+		}
+		seen[n.ID] = struct{}{}
+		graph.Nodes = append(graph.Nodes, dto.PrerequisiteGraphNode{
+			ID:          n.ID.String(),
+			Title:       n.Title,
+			MemoryLevel: n.MemoryLevel,
+			InFolder:    n.FolderID != nil && *n.FolderID == folder.ID,
+		})
+	}
+
+	for i := range notes.Notes {
+		addNode(&notes.Notes[i])
+	}
+	for _, link := range links {
+		addNode(link.Note)
+		addNode(link.Prerequisite)
+		graph.Edges = append(graph.Edges, dto.PrerequisiteGraphEdge{
+			From:          link.PrerequisiteID.String(),
+			To:            link.NoteID.String(),
+			RequiredLevel: link.RequiredLevel,
+			Satisfied:     link.Prerequisite != nil && link.Prerequisite.MemoryLevel >= link.RequiredLevel,
+		})
+	}
+
+	return graph, nil
+}
+
+// dependsOn проверяет, зависит ли from (транзитивно) от to по рёбрам графа
+func dependsOn(links []models.NotePrerequisite, from, to uuid.UUID) bool {
+	prerequisites := make(map[uuid.UUID][]uuid.UUID)
+	for _, l := range links {
+		prerequisites[l.NoteID] = append(prerequisites[l.NoteID], l.PrerequisiteID)
+	}
+
+	visited := map[uuid.UUID]bool{from: true}
+	queue := []uuid.UUID{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			return true
+		}
+		for _, next := range prerequisites[current] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+func toPrerequisiteDTO(link models.NotePrerequisite) dto.Prerequisite {
+	item := dto.Prerequisite{
+		NoteID:         link.NoteID.String(),
+		PrerequisiteID: link.PrerequisiteID.String(),
+		RequiredLevel:  link.RequiredLevel,
+	}
+	if link.Prerequisite != nil {
+		item.Title = link.Prerequisite.Title
+		item.MemoryLevel = link.Prerequisite.MemoryLevel
+		item.Satisfied = link.Prerequisite.MemoryLevel >= link.RequiredLevel
+	}
+	return item
+}
@@ -7,16 +7,33 @@ import (
 	"github.com/google/uuid"
 
 	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/notetype"
 	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/scheduler"
 )
 
 type ReviewSessionService struct {
-	noteRepo interfaces.NoteRepository
+	noteRepo        interfaces.NoteRepository
+	savedSearchRepo interfaces.SavedSearchRepository
+	noteTypeRepo    interfaces.NoteTypeRepository
+	folderRepo      interfaces.FolderRepository
+	reviewLogRepo   interfaces.ReviewLogRepository
 }
 
-func NewReviewSessionService(noteRepo interfaces.NoteRepository) *ReviewSessionService {
+func NewReviewSessionService(
+	noteRepo interfaces.NoteRepository,
+	savedSearchRepo interfaces.SavedSearchRepository,
+	noteTypeRepo interfaces.NoteTypeRepository,
+	folderRepo interfaces.FolderRepository,
+	reviewLogRepo interfaces.ReviewLogRepository,
+) *ReviewSessionService {
 	return &ReviewSessionService{
-		noteRepo: noteRepo,
+		noteRepo:        noteRepo,
+		savedSearchRepo: savedSearchRepo,
+		noteTypeRepo:    noteTypeRepo,
+		folderRepo:      folderRepo,
+		reviewLogRepo:   reviewLogRepo,
 	}
 }
 
@@ -36,8 +53,24 @@ func (s *ReviewSessionService) CreateReviewSession(ctx context.Context, userID s
 		return nil, err
 	}
 
+	// Сессия по сохранённому поиску берёт заметки только из его выборки
+	if input.SavedSearchID != nil && *input.SavedSearchID != "" {
+		search, err := getSavedSearch(ctx, s.savedSearchRepo, userID, *input.SavedSearchID)
+		if err != nil {
+			return nil, err
+		}
+		if input.Filter, err = savedSearchFilter(search); err != nil {
+			return nil, err
+		}
+	}
+
 	// Получаем заметки для повторения
-	notes, err := s.noteRepo.GetNotesForReview(ctx, userUUID, input)
+	notes, err := s.notesWithinDailyLimits(ctx, userUUID, input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes, err := s.noteTypesByID(ctx, userUUID, notes)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +106,9 @@ func (s *ReviewSessionService) CreateReviewSession(ctx context.Context, userID s
 				Name: tag.Name,
 			}
 		}
+
+		// Стороны карточки по шаблонам типа
+		renderSides(&reviewNotes[i], &note, noteTypes)
 	}
 
 	return &dto.ReviewSessionResponse{
@@ -80,3 +116,214 @@ func (s *ReviewSessionService) CreateReviewSession(ctx context.Context, userID s
 		Total: len(reviewNotes),
 	}, nil
 }
+
+// notesWithinDailyLimits набирает сессию с учётом дневных лимитов. Запрос к репозиторию
+// исключает папки, где лимит уже исчерпан; если часть выборки всё же отсеялась (лимит кончился
+// на ней), запрос повторяется без уже отобранных заметок — так повторения сверх лимита
+// не вытесняют новые карточки, которые в лимит ещё помещаются
+func (s *ReviewSessionService) notesWithinDailyLimits(ctx context.Context, userID uuid.UUID, input *dto.ReviewSessionInput, now time.Time) ([]models.Note, error) {
+	limiter, err := s.todayLimiter(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []models.Note
+	for len(notes) < input.Limit {
+		query := *input
+		query.Limit = input.Limit - len(notes)
+		query.NewBlocked, query.ReviewBlocked = limiter.blocked()
+		for _, note := range notes {
+			query.ExcludeIDs = append(query.ExcludeIDs, note.ID)
+		}
+
+		batch, err := s.noteRepo.GetNotesForReview(ctx, userID, &query)
+		if err != nil {
+			return nil, err
+		}
+		admitted := 0
+		for _, note := range batch {
+			if limiter.admit(note) {
+				notes = append(notes, note)
+				admitted++
+			}
+		}
+		// Всё подошло — значит, подходящих заметок больше нет или сессия набрана.
+		// Иначе какая-то папка только что исчерпала лимит и в следующем запросе исключится
+		if admitted == len(batch) {
+			break
+		}
+	}
+	return notes, nil
+}
+
+// dailyLimiter считает дневные лимиты по поддеревьям: ответ на карточку расходует лимит
+// её папки и всех предков, и карточка попадает в сессию, только если у каждой папки цепочки
+// лимит (из её действующих настроек) ещё не исчерпан. Заметки без расписания — новые (new_per_day),
+// остальные — повторения (reviews_per_day); заметки без папки считаются отдельно (uuid.Nil)
+type dailyLimiter struct {
+	settings func(folderID *uuid.UUID) scheduler.Settings
+	folders  []models.Folder
+	parents  map[uuid.UUID]*uuid.UUID
+	used     map[uuid.UUID]*dailyUsage
+}
+
+type dailyUsage struct{ reviews, new int }
+
+// newDailyLimiter создаёт лимитер без расходов; settings возвращает действующие настройки папки
+// (nil — заметки без папки). Симулятор нагрузки создаёт такой лимитер на каждый день прогноза
+func newDailyLimiter(folders []models.Folder, settings func(folderID *uuid.UUID) scheduler.Settings) *dailyLimiter {
+	l := &dailyLimiter{
+		settings: settings,
+		folders:  folders,
+		parents:  make(map[uuid.UUID]*uuid.UUID, len(folders)),
+		used:     make(map[uuid.UUID]*dailyUsage),
+	}
+	for _, f := range folders {
+		l.parents[f.ID] = f.ParentID
+	}
+	return l
+}
+
+// todayLimiter создаёт лимитер с уже израсходованными сегодня ответами
+func (s *ReviewSessionService) todayLimiter(ctx context.Context, userID uuid.UUID, now time.Time) (*dailyLimiter, error) {
+	folders, err := s.folderRepo.ListByUser(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	counts, err := s.reviewLogRepo.CountByFolderSince(ctx, userID, startOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newDailyLimiter(folders, newFolderSettingsResolver(folders).settings)
+	for _, c := range counts {
+		for _, id := range l.chain(c.FolderID) {
+			u := l.usage(id)
+			u.reviews += c.Total - c.New
+			u.new += c.New
+		}
+	}
+	return l, nil
+}
+
+// chain возвращает папку и её предков снизу вверх; для заметок без папки — uuid.Nil
+func (l *dailyLimiter) chain(folderID *uuid.UUID) []uuid.UUID {
+	if folderID == nil {
+		return []uuid.UUID{uuid.Nil}
+	}
+	chain := []uuid.UUID{*folderID}
+	for parent := l.parents[*folderID]; parent != nil && len(chain) <= len(l.parents); parent = l.parents[*parent] {
+		chain = append(chain, *parent)
+	}
+	return chain
+}
+
+func (l *dailyLimiter) usage(id uuid.UUID) *dailyUsage {
+	if l.used[id] == nil {
+		l.used[id] = &dailyUsage{}
+	}
+	return l.used[id]
+}
+
+// exhausted сообщает, исчерпан ли у папки id лимит новых карточек (isNew) или повторений
+func (l *dailyLimiter) exhausted(id uuid.UUID, isNew bool) bool {
+	var folderID *uuid.UUID
+	if id != uuid.Nil {
+		folderID = &id
+	}
+	settings := l.settings(folderID)
+	u := l.usage(id)
+	if isNew {
+		return settings.NewPerDay > 0 && u.new >= settings.NewPerDay
+	}
+	return settings.ReviewsPerDay > 0 && u.reviews >= settings.ReviewsPerDay
+}
+
+func (l *dailyLimiter) chainExhausted(folderID *uuid.UUID, isNew bool) bool {
+	for _, id := range l.chain(folderID) {
+		if l.exhausted(id, isNew) {
+			return true
+		}
+	}
+	return false
+}
+
+// admit засчитывает заметку в лимиты её цепочки папок; false — лимит уже исчерпан
+func (l *dailyLimiter) admit(note models.Note) bool {
+	return l.take(note.FolderID, note.NextReviewAt == nil)
+}
+
+// take засчитывает новую карточку (isNew) или повторение из папки folderID
+func (l *dailyLimiter) take(folderID *uuid.UUID, isNew bool) bool {
+	if l.chainExhausted(folderID, isNew) {
+		return false
+	}
+	for _, id := range l.chain(folderID) {
+		if isNew {
+			l.usage(id).new++
+		} else {
+			l.usage(id).reviews++
+		}
+	}
+	return true
+}
+
+// blocked возвращает папки, заметки которых уже не помещаются в лимит новых карточек и повторений
+func (l *dailyLimiter) blocked() (newBlocked, reviewBlocked []uuid.UUID) {
+	check := func(id uuid.UUID, folderID *uuid.UUID) {
+		if l.chainExhausted(folderID, true) {
+			newBlocked = append(newBlocked, id)
+		}
+		if l.chainExhausted(folderID, false) {
+			reviewBlocked = append(reviewBlocked, id)
+		}
+	}
+	check(uuid.Nil, nil)
+	for i := range l.folders {
+		check(l.folders[i].ID, &l.folders[i].ID)
+	}
+	return newBlocked, reviewBlocked
+}
+
+// noteTypesByID загружает типы заметок сессии; запрос делается, только если есть заметки не типа Basic
+func (s *ReviewSessionService) noteTypesByID(ctx context.Context, userID uuid.UUID, notes []models.Note) (map[uuid.UUID]*models.NoteType, error) {
+	noteTypes := make(map[uuid.UUID]*models.NoteType)
+	typed := false
+	for _, note := range notes {
+		if note.NoteTypeID != nil {
+			typed = true
+			break
+		}
+	}
+	if !typed {
+		return noteTypes, nil
+	}
+
+	list, err := s.noteTypeRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		noteTypes[list[i].ID] = &list[i]
+	}
+	return noteTypes, nil
+}
+
+// renderSides заполняет тип, поля и стороны карточки; заметки Basic показывают заголовок и текст
+func renderSides(reviewNote *dto.ReviewSessionNote, note *models.Note, noteTypes map[uuid.UUID]*models.NoteType) {
+	noteType := notetype.Basic()
+	values := notetype.BasicValues(note.Title, note.Content)
+	if note.NoteTypeID != nil {
+		if t, ok := noteTypes[*note.NoteTypeID]; ok {
+			noteType = t
+			values = note.Fields
+			reviewNote.NoteTypeID = t.ID.String()
+			reviewNote.Fields = note.Fields
+		}
+	}
+
+	reviewNote.NoteType = noteType.Name
+	reviewNote.Prompt = notetype.Render(noteType.PromptTemplate, values)
+	reviewNote.Answer = notetype.Render(noteType.AnswerTemplate, values)
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/notequery"
+	"valibibe/internal/repository/interfaces"
+)
+
+var (
+	savedSearchSortFields = map[string]bool{"": true, "created_at": true, "next_review_at": true, "relevance": true, "position": true}
+	savedSearchOrders     = map[string]bool{"": true, "asc": true, "desc": true}
+)
+
+type SavedSearchService struct {
+	repo        interfaces.SavedSearchRepository
+	noteService *NoteService
+}
+
+func NewSavedSearchService(repo interfaces.SavedSearchRepository, noteService *NoteService) *SavedSearchService {
+	return &SavedSearchService{
+		repo:        repo,
+		noteService: noteService,
+	}
+}
+
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, userID string, input *dto.SavedSearchInput) (*models.SavedSearch, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &models.SavedSearch{UserID: uid}
+	if err := s.apply(ctx, search, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) ListSavedSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, uid)
+}
+
+func (s *SavedSearchService) GetSavedSearch(ctx context.Context, userID, searchID string) (*models.SavedSearch, error) {
+	return getSavedSearch(ctx, s.repo, userID, searchID)
+}
+
+// UpdateSavedSearch полностью заменяет фильтры сохранённого поиска
+func (s *SavedSearchService) UpdateSavedSearch(ctx context.Context, userID, searchID string, input *dto.SavedSearchInput) (*models.SavedSearch, error) {
+	search, err := getSavedSearch(ctx, s.repo, userID, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, search, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, userID, searchID string) error {
+	search, err := getSavedSearch(ctx, s.repo, userID, searchID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, search.UserID, search.ID)
+}
+
+// RunSavedSearch возвращает страницу заметок, подходящих под сохранённый поиск
+func (s *SavedSearchService) RunSavedSearch(ctx context.Context, userID, searchID string, limit, offset int) (*dto.PaginatedNotes, error) {
+	search, err := getSavedSearch(ctx, s.repo, userID, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := savedSearchFilter(search)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+	filter.Offset = offset
+
+	return s.noteService.GetAllNotesByUserID(ctx, filter)
+}
+
+// apply проверяет ввод и переносит его в модель
+func (s *SavedSearchService) apply(ctx context.Context, search *models.SavedSearch, input *dto.SavedSearchInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", apperrors.ErrInvalidInput)
+	}
+
+	// Синтаксическая ошибка в q возвращается как есть, чтобы контроллер отдал позицию
+	if _, err := notequery.Parse(input.Query); err != nil {
+		return err
+	}
+
+	var folderID *uuid.UUID
+	if input.FolderID != nil && *input.FolderID != "" {
+		fid, err := uuid.Parse(*input.FolderID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid folder_id", apperrors.ErrInvalidInput)
+		}
+		folderID = &fid
+	}
+
+	tagIDs := make([]string, 0, len(input.TagIDs))
+	for _, id := range input.TagIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid tag id %q", apperrors.ErrInvalidInput, id)
+		}
+		tagIDs = append(tagIDs, id)
+	}
+
+	if !savedSearchSortFields[input.SortBy] {
+		return fmt.Errorf("%w: unknown sort_by %q", apperrors.ErrInvalidInput, input.SortBy)
+	}
+	order := strings.ToLower(input.Order)
+	if !savedSearchOrders[order] {
+		return fmt.Errorf("%w: order must be asc or desc", apperrors.ErrInvalidInput)
+	}
+
+	var excludeID *uuid.UUID
+	if search.ID != uuid.Nil {
+		excludeID = &search.ID
+	}
+	exists, err := s.repo.ExistsByName(ctx, search.UserID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperrors.ErrDuplicateName
+	}
+
+	search.Name = name
+	search.Query = input.Query
+	search.Search = input.Search
+	search.FolderID = folderID
+	search.TagIDs = tagIDs
+	search.Archived = input.Archived
+	search.SortBy = input.SortBy
+	search.Order = order
+	return nil
+}
+
+func getSavedSearch(ctx context.Context, repo interfaces.SavedSearchRepository, userID, searchID string) (*models.SavedSearch, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := uuid.Parse(searchID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	search, err := repo.GetByID(ctx, uid, sid)
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return search, nil
+}
+
+// savedSearchFilter собирает NoteFilter из сохранённого поиска (с разобранным q)
+func savedSearchFilter(search *models.SavedSearch) (*dto.NoteFilter, error) {
+	filter := &dto.NoteFilter{
+		UserID:   search.UserID.String(),
+		Search:   search.Search,
+		SortBy:   search.SortBy,
+		Order:    search.Order,
+		Archived: search.Archived,
+		TagIDs:   search.TagIDs,
+		Query:    search.Query,
+	}
+	if search.FolderID != nil {
+		folderID := search.FolderID.String()
+		filter.FolderID = &folderID
+	}
+	if err := parseNoteQuery(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/scheduler"
+)
+
+const (
+	defaultSimulationDays     = 30
+	maxSimulationDays         = 365
+	defaultSecondsPerReview   = 8
+	defaultSecondsPerNewCard  = 20
+	defaultSimulationSeed     = 1
+	recallSmoothingAttempts   = 10
+	simulationHoursPerDay     = 24 * time.Hour
+	simulationRoundingDivisor = 10000
+)
+
+// defaultRecallRates — априорная вероятность вспомнить карточку по диапазонам memory_level.
+// Используется, пока у пользователя мало собственной истории повторений.
+var defaultRecallRates = []float64{0.75, 0.8, 0.85, 0.9, 0.95}
+
+type StatsService struct {
+	noteRepo      interfaces.NoteRepository
+	reviewLogRepo interfaces.ReviewLogRepository
+	folderRepo    interfaces.FolderRepository
+}
+
+func NewStatsService(noteRepo interfaces.NoteRepository, reviewLogRepo interfaces.ReviewLogRepository, folderRepo interfaces.FolderRepository) *StatsService {
+	return &StatsService{
+		noteRepo:      noteRepo,
+		reviewLogRepo: reviewLogRepo,
+		folderRepo:    folderRepo,
+	}
+}
+
+// simulatedCard — состояние карточки в симуляции; Due — номер дня, в который она станет к повторению
+type simulatedCard struct {
+	FolderID  *uuid.UUID
+	Level     int
+	Due       int
+	Scheduled bool
+}
+
+// Simulate прогоняет текущие заметки пользователя через планировщик. Каждая заметка получает
+// действующие настройки своей папки, поверх которых ложатся кандидатные настройки из input
+func (s *StatsService) Simulate(ctx context.Context, userID string, input *dto.SimulationInput) (*dto.SimulationResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Days == 0 {
+		input.Days = defaultSimulationDays
+	}
+	if input.Days < 1 || input.Days > maxSimulationDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", apperrors.ErrInvalidInput, maxSimulationDays)
+	}
+	if input.SecondsPerReview <= 0 {
+		input.SecondsPerReview = defaultSecondsPerReview
+	}
+	if input.SecondsPerNewCard <= 0 {
+		input.SecondsPerNewCard = defaultSecondsPerNewCard
+	}
+
+	folders, err := s.folderRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resolver := newFolderSettingsResolver(folders)
+	settingsFor := func(folderID *uuid.UUID) scheduler.Settings {
+		return mergeSimulationSettings(resolver.settings(folderID), input.Settings)
+	}
+	if err := settingsFor(nil).Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+	// Переопределения могут не сочетаться с настройками отдельной папки
+	for i := range folders {
+		if err := settingsFor(&folders[i].ID).Validate(); err != nil {
+			return nil, fmt.Errorf("%w: folder %q: %s", apperrors.ErrInvalidInput, folders[i].Name, err.Error())
+		}
+	}
+
+	// Заметки пользователя (архивные не повторяются)
+	archived := false
+	notes, err := s.noteRepo.GetAllNotesByUserID(ctx, &dto.NoteFilter{
+		UserID:   userID,
+		SortBy:   "created_at",
+		Order:    "asc",
+		Archived: &archived,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Фактическая доля вспоминания по диапазонам
+	stats, err := s.reviewLogRepo.GetRecallStats(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	recall := recallRates(stats)
+
+	now := time.Now()
+	cards := make([]simulatedCard, 0, len(notes.Notes))
+	for _, n := range notes.Notes {
+		card := simulatedCard{FolderID: n.FolderID, Level: n.MemoryLevel}
+		if n.NextReviewAt != nil {
+			card.Scheduled = true
+			card.Due = int(math.Ceil(n.NextReviewAt.Sub(now).Hours() / 24))
+			if card.Due < 0 {
+				card.Due = 0
+			}
+		}
+		cards = append(cards, card)
+	}
+
+	seed := int64(defaultSimulationSeed)
+	if input.Seed != nil {
+		seed = *input.Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	result := simulate(cards, recall, folders, settingsFor, input, rng, now)
+	result.Notes = len(cards)
+	return result, nil
+}
+
+// simulate — дневной цикл: сначала повторения к сроку, затем новые (или сброшенные) карточки.
+// Дневные лимиты считаются по поддеревьям папок так же, как в сессии повторения (dailyLimiter)
+func simulate(cards []simulatedCard, recall []float64, folders []models.Folder, settingsFor func(folderID *uuid.UUID) scheduler.Settings,
+	input *dto.SimulationInput, rng *rand.Rand, start time.Time) *dto.SimulationResult {
+	result := &dto.SimulationResult{
+		Settings:    settingsFor(nil),
+		RecallRates: recall,
+		Days:        make([]dto.SimulationDay, 0, input.Days),
+	}
+
+	// Очередь незапланированных карточек: сначала в порядке создания, сброшенные — в конец
+	pool := make([]int, 0)
+	for i, c := range cards {
+		if !c.Scheduled {
+			pool = append(pool, i)
+		}
+	}
+
+	var retentionSum float64
+	var answers int
+
+	for day := 0; day < input.Days; day++ {
+		stat := dto.SimulationDay{
+			Day:  day + 1,
+			Date: start.Add(time.Duration(day) * simulationHoursPerDay).Format("2006-01-02"),
+		}
+
+		due := make([]int, 0)
+		for i, c := range cards {
+			if c.Scheduled && c.Due <= day {
+				due = append(due, i)
+			}
+		}
+		sort.SliceStable(due, func(a, b int) bool { return cards[due[a]].Due < cards[due[b]].Due })
+
+		// Повторения, не поместившиеся в лимит своей цепочки папок, откладываются на завтра
+		limiter := newDailyLimiter(folders, settingsFor)
+		admitted := due[:0]
+		for _, i := range due {
+			if limiter.take(cards[i].FolderID, false) {
+				admitted = append(admitted, i)
+			} else {
+				stat.Postponed++
+			}
+		}
+		due = admitted
+
+		introduced := make([]int, 0)
+		waiting := make([]int, 0, len(pool))
+		for _, i := range pool {
+			if limiter.take(cards[i].FolderID, true) {
+				introduced = append(introduced, i)
+			} else {
+				waiting = append(waiting, i)
+			}
+		}
+		pool = waiting
+
+		var dayRetention float64
+		answer := func(i int) {
+			c := &cards[i]
+			p := recall[scheduler.Band(c.Level)]
+			dayRetention += p
+
+			remembered := rng.Float64() < p
+			if !remembered {
+				stat.Forgotten++
+			}
+			// Симуляция идёт по дням: шаг обучения короче дня переносит карточку на следующий день
+			at := start.UTC().Add(time.Duration(day) * simulationHoursPerDay)
+			level, next := settingsFor(c.FolderID).NextReviewAt(c.Level, remembered, at)
+			c.Level = level
+			c.Scheduled = next != nil
+			if c.Scheduled {
+				c.Due = day + int(math.Max(1, math.Ceil(next.Sub(at).Hours()/24)))
+			} else {
+				pool = append(pool, i)
+			}
+		}
+
+		for _, i := range due {
+			answer(i)
+		}
+		for _, i := range introduced {
+			answer(i)
+		}
+
+		stat.Reviews = len(due)
+		stat.NewCards = len(introduced)
+		stat.TimeSpentSeconds = float64(stat.Reviews)*input.SecondsPerReview + float64(stat.NewCards)*input.SecondsPerNewCard
+		if total := stat.Reviews + stat.NewCards; total > 0 {
+			stat.ExpectedRetention = roundRate(dayRetention / float64(total))
+		}
+
+		retentionSum += dayRetention
+		answers += stat.Reviews + stat.NewCards
+
+		result.TotalReviews += stat.Reviews
+		result.TotalNewCards += stat.NewCards
+		result.TotalTimeSeconds += stat.TimeSpentSeconds
+		result.Days = append(result.Days, stat)
+	}
+
+	result.AverageReviewsPerDay = roundRate(float64(result.TotalReviews) / float64(input.Days))
+	if answers > 0 {
+		result.ExpectedRetention = roundRate(retentionSum / float64(answers))
+	}
+	return result
+}
+
+// recallRates сглаживает фактическую долю вспоминания априорной оценкой по каждому диапазону
+func recallRates(stats []interfaces.RecallStat) []float64 {
+	totals := make([]int64, scheduler.Bands())
+	remembered := make([]int64, scheduler.Bands())
+	for _, st := range stats {
+		band := scheduler.Band(st.LevelBefore)
+		totals[band] += st.Total
+		remembered[band] += st.Remembered
+	}
+
+	rates := make([]float64, scheduler.Bands())
+	for band := range rates {
+		prior := defaultRecallRates[band]
+		rates[band] = roundRate((float64(remembered[band]) + prior*recallSmoothingAttempts) /
+			(float64(totals[band]) + recallSmoothingAttempts))
+	}
+	return rates
+}
+
+func mergeSimulationSettings(base scheduler.Settings, override *dto.SimulationSettings) scheduler.Settings {
+	if override == nil {
+		return base
+	}
+	if override.LevelStep != nil {
+		base.LevelStep = *override.LevelStep
+	}
+	if override.IntervalDays != nil {
+		base.IntervalDays = override.IntervalDays
+	}
+	if override.MaxIntervalDays != nil {
+		base.MaxIntervalDays = *override.MaxIntervalDays
+	}
+	if override.NewPerDay != nil {
+		base.NewPerDay = *override.NewPerDay
+	}
+	if override.ReviewsPerDay != nil {
+		base.ReviewsPerDay = *override.ReviewsPerDay
+	}
+	if override.Algorithm != nil {
+		base.Algorithm = *override.Algorithm
+	}
+	if override.TargetRetention != nil {
+		base.TargetRetention = *override.TargetRetention
+	}
+	if override.LearningSteps != nil {
+		base.LearningSteps = override.LearningSteps
+	}
+	return base
+}
+
+func roundRate(v float64) float64 {
+	return math.Round(v*simulationRoundingDivisor) / simulationRoundingDivisor
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+)
+
+// MergeTags сливает теги source_ids в target_id: связи с заметками переходят на target,
+// потомки исходных тегов (bio::cells) — под target (biology::cells), исходные теги удаляются
+func (s *TagService) MergeTags(ctx context.Context, userID string, input dto.TagMergeInput) (*dto.TagMergeResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid userID", apperrors.ErrInvalidInput)
+	}
+	targetID, err := uuid.Parse(input.TargetID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid target_id", apperrors.ErrInvalidInput)
+	}
+
+	tags, err := s.repo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Tag, len(tags))
+	for _, t := range tags {
+		byID[t.ID] = t
+	}
+	target, ok := byID[targetID]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+
+	var sources []models.Tag
+	removed := make(map[uuid.UUID]bool)
+	for _, raw := range input.SourceIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid source id %q", apperrors.ErrInvalidInput, raw)
+		}
+		if id == targetID {
+			return nil, fmt.Errorf("%w: target cannot be a source", apperrors.ErrInvalidInput)
+		}
+		if removed[id] {
+			continue
+		}
+		source, ok := byID[id]
+		if !ok {
+			return nil, apperrors.ErrNotFound
+		}
+		sources = append(sources, source)
+		removed[id] = true
+	}
+
+	// Потомки переезжают под target, поэтому target не может лежать под исходным тегом,
+	// а исходные теги — друг под другом
+	renames := make(map[uuid.UUID]string)
+	for _, source := range sources {
+		if isTagDescendant(target.Name, source.Name) {
+			return nil, fmt.Errorf("%w: target cannot be a child of a merged tag", apperrors.ErrInvalidInput)
+		}
+		for _, other := range sources {
+			if isTagDescendant(other.Name, source.Name) {
+				return nil, fmt.Errorf("%w: merged tags cannot be nested in each other", apperrors.ErrInvalidInput)
+			}
+		}
+		for id, name := range renamedDescendants(tags, source.Name, target.Name) {
+			renames[id] = name
+		}
+	}
+	if len(tagNameConflicts(tags, renames, removed)) > 0 {
+		return nil, apperrors.ErrDuplicateName
+	}
+
+	moved, duplicates, err := s.repo.Merge(ctx, sources, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.TagMergeResult{
+		Target:         target,
+		MergedTagIDs:   make([]uuid.UUID, len(sources)),
+		MovedLinks:     moved,
+		DuplicateLinks: duplicates,
+	}
+	for i, source := range sources {
+		result.MergedTagIDs[i] = source.ID
+	}
+	return result, nil
+}
+
+// BulkRenameTags переименовывает все теги, подходящие под префикс или регулярное выражение,
+// вместе с их потомками. Переименование и создание недостающих родителей идут в одной
+// транзакции; при конфликте имён ничего не меняется; dry_run только возвращает план
+func (s *TagService) BulkRenameTags(ctx context.Context, userID string, input dto.TagBulkRenameInput) (*dto.TagBulkRenameResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid userID", apperrors.ErrInvalidInput)
+	}
+	rename, err := tagRenameFunc(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name)
+	})
+
+	result := &dto.TagBulkRenameResult{
+		DryRun:         input.DryRun,
+		Renamed:        []dto.TagRename{},
+		Conflicts:      []dto.TagRename{},
+		CreatedParents: []string{},
+	}
+	// Как и при переименовании одного тега, потомки едут за родителем: ^bio$ -> biology
+	// переименовывает и bio::cell в biology::cell. Теги отсортированы, поэтому потомок,
+	// сам подошедший под шаблон, обрабатывается позже и получает своё имя
+	renames := make(map[uuid.UUID]string)
+	for _, t := range tags {
+		newName, ok := rename(t.Name)
+		if !ok {
+			continue
+		}
+		if newName, err = normalizeTagName(newName); err != nil {
+			return nil, fmt.Errorf("%w: tag %q would be renamed to an invalid name", apperrors.ErrInvalidInput, t.Name)
+		}
+		if newName == t.Name {
+			continue
+		}
+		renames[t.ID] = newName
+		for id, name := range renamedDescendants(tags, t.Name, newName) {
+			renames[id] = name
+		}
+	}
+	for _, t := range tags {
+		if newName, ok := renames[t.ID]; ok && newName != t.Name {
+			result.Renamed = append(result.Renamed, dto.TagRename{ID: t.ID, From: t.Name, To: newName})
+		} else {
+			delete(renames, t.ID)
+		}
+	}
+
+	conflicts := make(map[uuid.UUID]bool)
+	for _, id := range tagNameConflicts(tags, renames, nil) {
+		conflicts[id] = true
+	}
+	for _, r := range result.Renamed {
+		if conflicts[r.ID] {
+			result.Conflicts = append(result.Conflicts, r)
+		}
+	}
+
+	// Родители новых путей, которых после переименования не будет
+	final := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		name, ok := renames[t.ID]
+		if !ok {
+			name = t.Name
+		}
+		final[strings.ToLower(name)] = true
+	}
+	for _, r := range result.Renamed {
+		for _, ancestor := range tagAncestors(r.To) {
+			if !final[strings.ToLower(ancestor)] {
+				final[strings.ToLower(ancestor)] = true
+				result.CreatedParents = append(result.CreatedParents, ancestor)
+			}
+		}
+	}
+
+	if input.DryRun || len(renames) == 0 {
+		return result, nil
+	}
+	if len(result.Conflicts) > 0 {
+		return nil, apperrors.ErrDuplicateName
+	}
+
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Rename(ctx, uid, renames); err != nil {
+			return err
+		}
+		for _, name := range result.CreatedParents {
+			if err := s.repo.Create(ctx, &models.Tag{UserID: uid, Name: name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// tagRenameFunc возвращает функцию нового имени тега; false — тег не подходит под шаблон
+func tagRenameFunc(input dto.TagBulkRenameInput) (func(name string) (string, bool), error) {
+	switch input.Mode {
+	case dto.TagRenamePrefix:
+		prefix := []rune(input.Pattern)
+		return func(name string) (string, bool) {
+			runes := []rune(name)
+			if len(runes) < len(prefix) || !strings.EqualFold(string(runes[:len(prefix)]), input.Pattern) {
+				return "", false
+			}
+			return input.Replacement + string(runes[len(prefix):]), true
+		}, nil
+	case dto.TagRenameRegex:
+		re, err := regexp.Compile(input.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern: %v", apperrors.ErrInvalidInput, err)
+		}
+		return func(name string) (string, bool) {
+			if !re.MatchString(name) {
+				return "", false
+			}
+			return re.ReplaceAllString(name, input.Replacement), true
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", apperrors.ErrInvalidInput, input.Mode)
+	}
+}
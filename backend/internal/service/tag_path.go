@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+)
+
+// tagSeparator разделяет уровни иерархического тега: "lang::spanish::verbs"
+const tagSeparator = "::"
+
+// GetTagTree возвращает теги пользователя деревом по их путям
+func (s *TagService) GetTagTree(ctx context.Context, userID string) ([]dto.TagNode, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid userID", apperrors.ErrInvalidInput)
+	}
+	tags, err := s.repo.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return buildTagTree(tags), nil
+}
+
+// ensureTagAncestors создаёт недостающих предков тега: для "a::b::c" — "a" и "a::b"
+func (s *TagService) ensureTagAncestors(ctx context.Context, userID uuid.UUID, name string) error {
+	for _, ancestor := range tagAncestors(name) {
+		exists, err := s.repo.ExistsByName(ctx, userID, ancestor)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := s.repo.Create(ctx, &models.Tag{UserID: userID, Name: ancestor}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeTagName убирает пробелы вокруг "::"; пустой сегмент — ошибка
+func normalizeTagName(name string) (string, error) {
+	segments := strings.Split(name, tagSeparator)
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(segment)
+		if segments[i] == "" {
+			return "", fmt.Errorf("%w: tag name has an empty segment", apperrors.ErrInvalidInput)
+		}
+	}
+	return strings.Join(segments, tagSeparator), nil
+}
+
+// tagAncestors возвращает пути предков от корня: "a::b::c" → ["a", "a::b"]
+func tagAncestors(name string) []string {
+	segments := strings.Split(name, tagSeparator)
+	ancestors := make([]string, 0, len(segments)-1)
+	for i := 1; i < len(segments); i++ {
+		ancestors = append(ancestors, strings.Join(segments[:i], tagSeparator))
+	}
+	return ancestors
+}
+
+// isTagDescendant сообщает, лежит ли name под ancestor (без учёта регистра)
+func isTagDescendant(name, ancestor string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(ancestor)+tagSeparator)
+}
+
+// buildTagTree раскладывает теги по путям. Тег, чей родитель в корзине,
+// встаёт под ближайшего живого предка или в корень
+func buildTagTree(tags []models.Tag) []dto.TagNode {
+	sorted := make([]models.Tag, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+
+	byName := make(map[string]*dto.TagNode, len(sorted))
+	for _, t := range sorted {
+		segments := strings.Split(t.Name, tagSeparator)
+		byName[strings.ToLower(t.Name)] = &dto.TagNode{
+			ID:       t.ID.String(),
+			Name:     t.Name,
+			Label:    segments[len(segments)-1],
+			Version:  t.Version,
+			Children: []*dto.TagNode{},
+		}
+	}
+
+	var roots []*dto.TagNode
+	for _, t := range sorted {
+		node := byName[strings.ToLower(t.Name)]
+		var parent *dto.TagNode
+		ancestors := tagAncestors(t.Name)
+		for i := len(ancestors) - 1; i >= 0 && parent == nil; i-- {
+			parent = byName[strings.ToLower(ancestors[i])]
+		}
+		if parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	result := make([]dto.TagNode, 0, len(roots))
+	for _, r := range roots {
+		result = append(result, *r)
+	}
+	return result
+}
+
+// checkDescendantNames проверяет, что после переименования oldName → newName
+// пути потомков не совпадут с уже существующими тегами
+func (s *TagService) checkDescendantNames(ctx context.Context, userID uuid.UUID, oldName, newName string) error {
+	tags, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(tagNameConflicts(tags, renamedDescendants(tags, oldName, newName), nil)) > 0 {
+		return errors.New("tag with this name already exists")
+	}
+	return nil
+}
+
+// renamedDescendants возвращает новые пути потомков oldName, если тот станет newName
+func renamedDescendants(tags []models.Tag, oldName, newName string) map[uuid.UUID]string {
+	renames := make(map[uuid.UUID]string)
+	prefixLen := len([]rune(oldName))
+	for _, t := range tags {
+		if isTagDescendant(t.Name, oldName) {
+			renames[t.ID] = newName + string([]rune(t.Name)[prefixLen:])
+		}
+	}
+	return renames
+}
+
+// tagNameConflicts возвращает ID переименованных тегов, чьё новое имя (без учёта регистра)
+// совпадёт с именем другого тега после переименования; removed — теги, которых уже не будет
+func tagNameConflicts(tags []models.Tag, renames map[uuid.UUID]string, removed map[uuid.UUID]bool) []uuid.UUID {
+	owners := make(map[string]int, len(tags))
+	for _, t := range tags {
+		if removed[t.ID] {
+			continue
+		}
+		name, ok := renames[t.ID]
+		if !ok {
+			name = t.Name
+		}
+		owners[strings.ToLower(name)]++
+	}
+
+	var conflicts []uuid.UUID
+	for _, t := range tags {
+		if name, ok := renames[t.ID]; ok && !removed[t.ID] && owners[strings.ToLower(name)] > 1 {
+			conflicts = append(conflicts, t.ID)
+		}
+	}
+	return conflicts
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	apperrors "valibibe/internal/errors"
 
 	"github.com/google/uuid"
@@ -25,7 +27,12 @@ func (s *TagService) CreateTag(ctx context.Context, userID string, input dto.Tag
         return nil, errors.New("invalid userID")
     }
 
-    exists, err := s.repo.ExistsByName(ctx, uid, input.Name)
+    name, err := normalizeTagName(input.Name)
+    if err != nil {
+        return nil, err
+    }
+
+    exists, err := s.repo.ExistsByName(ctx, uid, name)
     if err != nil {
         return nil, err
     }
@@ -35,10 +42,17 @@ func (s *TagService) CreateTag(ctx context.Context, userID string, input dto.Tag
 
     tag := &models.Tag{
         UserID: uid,
-        Name:   input.Name,
+        Name:   name,
     }
 
-    if err := s.repo.Create(ctx, tag); err != nil {
+    // для "a::b::c" заодно появляются "a" и "a::b" — в той же транзакции, что и сам тег
+    err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.ensureTagAncestors(ctx, uid, name); err != nil {
+            return err
+        }
+        return s.repo.Create(ctx, tag)
+    })
+    if err != nil {
         return nil, err
     }
     return tag, nil
@@ -61,7 +75,14 @@ func (s *TagService) GetTag(ctx context.Context, userID, tagID string) (*models.
     if err != nil {
         return nil, errors.New("invalid tagID")
     }
-    return s.repo.GetByID(ctx, uid, tid)
+    tag, err := s.repo.GetByID(ctx, uid, tid)
+    if err != nil {
+        return nil, err
+    }
+    if tag == nil {
+        return nil, apperrors.ErrNotFound
+    }
+    return tag, nil
 }
 
 func (s *TagService) UpdateTag(ctx context.Context, userID, tagID string, input dto.TagUpdateInput) (*models.Tag, error) {
@@ -81,18 +102,59 @@ func (s *TagService) UpdateTag(ctx context.Context, userID, tagID string, input
     if tag == nil {
         return nil, apperrors.ErrNotFound
     }
+    if err := checkVersion(input.Version, tag.Version); err != nil {
+        if errors.Is(err, apperrors.ErrVersionConflict) {
+            return nil, &VersionConflictError{Current: tag, Version: tag.Version}
+        }
+        return nil, err
+    }
+
+    name, err := normalizeTagName(input.Name)
+    if err != nil {
+        return nil, err
+    }
+    if isTagDescendant(name, tag.Name) {
+        return nil, fmt.Errorf("%w: tag cannot be moved under itself", apperrors.ErrInvalidInput)
+    }
 
     // проверка дубля
-    exists, err := s.repo.ExistsByName(ctx, uid, input.Name)
+    exists, err := s.repo.ExistsByName(ctx, uid, name)
     if err != nil {
         return nil, err
     }
-    if exists && tag.Name != input.Name { // чтобы не ругалось, если имя не изменилось
+    if exists && !strings.EqualFold(tag.Name, name) { // чтобы не ругалось, если имя не изменилось или поменялся только регистр
         return nil, errors.New("tag with this name already exists")
     }
-
-    tag.Name = input.Name
-    if err := s.repo.Update(ctx, tag); err != nil {
+    renamed := !strings.EqualFold(tag.Name, name)
+    if renamed {
+        // потомки переедут вместе с тегом — их новые пути тоже должны быть свободны
+        if err := s.checkDescendantNames(ctx, uid, tag.Name, name); err != nil {
+            return nil, err
+        }
+    }
+
+    tag.Name = name
+    // новые предки создаются в той же транзакции, что и переименование
+    err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+        if renamed {
+            if err := s.ensureTagAncestors(ctx, uid, name); err != nil {
+                return err
+            }
+        }
+        return s.repo.Update(ctx, tag)
+    })
+    if errors.Is(err, apperrors.ErrVersionConflict) {
+        // Тег изменили между чтением и записью
+        current, err := s.repo.GetByID(ctx, uid, tid)
+        if err != nil {
+            return nil, err
+        }
+        if current == nil {
+            return nil, apperrors.ErrNotFound
+        }
+        return nil, &VersionConflictError{Current: current, Version: current.Version}
+    }
+    if err != nil {
         return nil, err
     }
 
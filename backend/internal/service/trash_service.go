@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository/interfaces"
+)
+
+// DefaultTrashRetention — сколько объекты хранятся в корзине до окончательного удаления
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashService struct {
+	trashRepo interfaces.TrashRepository
+	retention time.Duration
+}
+
+func NewTrashService(trashRepo interfaces.TrashRepository, retention time.Duration) *TrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &TrashService{
+		trashRepo: trashRepo,
+		retention: retention,
+	}
+}
+
+// ListTrash возвращает содержимое корзины. Вложенные папки и заметки, удалённые
+// вместе с родительской папкой, отдельно не показываются — они учтены в её счётчиках.
+func (s *TrashService) ListTrash(ctx context.Context, userID string) (*dto.TrashList, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.trashRepo.ListDeletedNotes(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.trashRepo.ListDeletedFolders(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.trashRepo.ListDeletedTags(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedFolders := make(map[uuid.UUID]*models.Folder, len(folders))
+	children := make(map[uuid.UUID][]*models.Folder)
+	for i := range folders {
+		f := &folders[i]
+		deletedFolders[f.ID] = f
+		if f.ParentID != nil {
+			children[*f.ParentID] = append(children[*f.ParentID], f)
+		}
+	}
+
+	// deletedWithParent — удалён ли объект в составе удаления родительской папки
+	deletedWithParent := func(parentID *uuid.UUID, deletedAt time.Time) bool {
+		if parentID == nil {
+			return false
+		}
+		parent, ok := deletedFolders[*parentID]
+		return ok && parent.DeletedAt.Time.Equal(deletedAt)
+	}
+
+	items := make([]dto.TrashItem, 0)
+
+	for i := range folders {
+		f := &folders[i]
+		if deletedWithParent(f.ParentID, f.DeletedAt.Time) {
+			continue
+		}
+
+		// Собираем группу: папка и потомки, удалённые одной операцией
+		group := map[uuid.UUID]struct{}{f.ID: {}}
+		stack := []*models.Folder{f}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, child := range children[current.ID] {
+				if child.DeletedAt.Time.Equal(f.DeletedAt.Time) {
+					group[child.ID] = struct{}{}
+					stack = append(stack, child)
+				}
+			}
+		}
+
+		item := s.newTrashItem(f.ID, dto.TrashItemFolder, f.Name, f.ParentID, f.DeletedAt.Time)
+		item.FoldersCount = len(group) - 1
+		for _, n := range notes {
+			if n.FolderID == nil || !n.DeletedAt.Time.Equal(f.DeletedAt.Time) {
+				continue
+			}
+			if _, ok := group[*n.FolderID]; ok {
+				item.NotesCount++
+			}
+		}
+		items = append(items, item)
+	}
+
+	for _, n := range notes {
+		if deletedWithParent(n.FolderID, n.DeletedAt.Time) {
+			continue
+		}
+		items = append(items, s.newTrashItem(n.ID, dto.TrashItemNote, n.Title, n.FolderID, n.DeletedAt.Time))
+	}
+
+	for _, t := range tags {
+		items = append(items, s.newTrashItem(t.ID, dto.TrashItemTag, t.Name, nil, t.DeletedAt.Time))
+	}
+
+	// Свежие удаления — первыми
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+
+	return &dto.TrashList{
+		Items:         items,
+		RetentionDays: int(s.retention / (24 * time.Hour)),
+	}, nil
+}
+
+// Restore восстанавливает объект корзины по id (заметку, папку или тег)
+func (s *TrashService) Restore(ctx context.Context, userID, itemID string) (*dto.TrashItem, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(itemID)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	note, err := s.trashRepo.GetDeletedNote(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if note != nil {
+		if err := s.trashRepo.RestoreNote(ctx, note); err != nil {
+			return nil, err
+		}
+		item := s.newTrashItem(note.ID, dto.TrashItemNote, note.Title, note.FolderID, note.DeletedAt.Time)
+		return &item, nil
+	}
+
+	folder, err := s.trashRepo.GetDeletedFolder(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if folder != nil {
+		if err := s.trashRepo.RestoreFolder(ctx, folder); err != nil {
+			return nil, err
+		}
+		item := s.newTrashItem(folder.ID, dto.TrashItemFolder, folder.Name, folder.ParentID, folder.DeletedAt.Time)
+		return &item, nil
+	}
+
+	tag, err := s.trashRepo.GetDeletedTag(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag != nil {
+		if err := s.trashRepo.RestoreTag(ctx, tag); err != nil {
+			return nil, err
+		}
+		item := s.newTrashItem(tag.ID, dto.TrashItemTag, tag.Name, nil, tag.DeletedAt.Time)
+		return &item, nil
+	}
+
+	return nil, apperrors.ErrNotFound
+}
+
+// PurgeExpired окончательно удаляет объекты, пролежавшие в корзине дольше срока хранения
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.trashRepo.Purge(ctx, time.Now().Add(-s.retention))
+}
+
+// StartPurger запускает фоновую очистку корзины с заданным интервалом.
+// Останавливается при отмене ctx.
+func (s *TrashService) StartPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := s.PurgeExpired(ctx)
+			if err != nil {
+				log.Printf("❌ Ошибка очистки корзины: %v", err)
+			} else if purged > 0 {
+				log.Printf("🗑 Из корзины удалено объектов: %d", purged)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *TrashService) newTrashItem(id uuid.UUID, itemType, title string, parentID *uuid.UUID, deletedAt time.Time) dto.TrashItem {
+	item := dto.TrashItem{
+		ID:        id.String(),
+		Type:      itemType,
+		Title:     title,
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(s.retention),
+	}
+	if parentID != nil {
+		pid := parentID.String()
+		item.ParentID = &pid
+	}
+	return item
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	apperrors "valibibe/internal/errors"
+)
+
+// VersionConflictError — объект изменён после того, как клиент его прочитал.
+// Current — актуальная копия на сервере, Version — её версия.
+type VersionConflictError struct {
+	Current interface{}
+	Version int
+}
+
+func (e *VersionConflictError) Error() string {
+	return apperrors.ErrVersionConflict.Error()
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return apperrors.ErrVersionConflict
+}
+
+// checkVersion сверяет версию, с которой работал клиент, с текущей
+func checkVersion(expected *int, current int) error {
+	if expected == nil {
+		return apperrors.ErrVersionRequired
+	}
+	if *expected != current {
+		return apperrors.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "unicode/utf8"
+
+// TruncateRunes обрезает строку до n символов (рун), не разрезая многобайтовые символы
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
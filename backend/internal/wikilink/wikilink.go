@@ -0,0 +1,49 @@
+// Package wikilink разбирает ссылки вида [[Заголовок]] и [[id-заметки]] в тексте заметок.
+// После вертикальной черты можно указать подпись: [[Заголовок|подпись]].
+package wikilink
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+?)\]\]`)
+
+// Targets возвращает цели ссылок в порядке появления; повторы (без учёта регистра) отбрасываются
+func Targets(content string) []string {
+	seen := make(map[string]bool)
+	targets := make([]string, 0)
+	for _, m := range linkPattern.FindAllStringSubmatch(content, -1) {
+		target, _ := split(m[1])
+		key := strings.ToLower(target)
+		if target == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// Rename заменяет цель old на new во всех ссылках (без учёта регистра), сохраняя подписи.
+// Второе значение — были ли замены.
+func Rename(content, old, new string) (string, bool) {
+	changed := false
+	result := linkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		target, label := split(link[2 : len(link)-2])
+		if !strings.EqualFold(target, old) {
+			return link
+		}
+		changed = true
+		if label != "" {
+			return "[[" + new + "|" + label + "]]"
+		}
+		return "[[" + new + "]]"
+	})
+	return result, changed
+}
+
+func split(inner string) (target, label string) {
+	target, label, _ = strings.Cut(inner, "|")
+	return strings.TrimSpace(target), strings.TrimSpace(label)
+}
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS exams (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    folder_id UUID NULL REFERENCES folders(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    apply_to_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    duration_seconds INT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    deadline TIMESTAMPTZ NOT NULL,
+    submitted_at TIMESTAMPTZ NULL,
+    total_questions INT NOT NULL DEFAULT 0,
+    correct_answers INT NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    time_spent_seconds INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- список результатов пользователя (новые сверху)
+CREATE INDEX IF NOT EXISTS idx_exams_user_created ON exams (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS exam_questions (
+    id UUID PRIMARY KEY,
+    exam_id UUID NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+    -- заметка может быть удалена позже, результат экзамена при этом сохраняется
+    note_id UUID NOT NULL,
+    position INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    correct BOOLEAN NULL,
+    answered_at TIMESTAMPTZ NULL,
+    time_spent_seconds INT NOT NULL DEFAULT 0,
+    UNIQUE (exam_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exam_questions_exam_id ON exam_questions (exam_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_note_prerequisites_user_id;
+DROP INDEX IF EXISTS idx_note_prerequisites_prerequisite_id;
+
+DROP TABLE IF EXISTS note_prerequisites;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS note_prerequisites (
+    note_id UUID NOT NULL,
+    prerequisite_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    required_level INT NOT NULL DEFAULT 60 CHECK (required_level >= 0 AND required_level <= 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (note_id, prerequisite_id),
+    CONSTRAINT fk_note_prerequisites_note FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE,
+    CONSTRAINT fk_note_prerequisites_prerequisite FOREIGN KEY (prerequisite_id) REFERENCES notes(id) ON DELETE CASCADE,
+    CONSTRAINT chk_note_prerequisites_not_self CHECK (note_id <> prerequisite_id)
+);
+
+-- обратный обход графа (от пререквизита к зависимым)
+CREATE INDEX IF NOT EXISTS idx_note_prerequisites_prerequisite_id ON note_prerequisites (prerequisite_id);
+CREATE INDEX IF NOT EXISTS idx_note_prerequisites_user_id ON note_prerequisites (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_review_logs_note_id;
+DROP INDEX IF EXISTS idx_review_logs_user_reviewed;
+
+DROP TABLE IF EXISTS review_logs;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS review_logs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note_id UUID NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    remembered BOOLEAN NOT NULL,
+    level_before INT NOT NULL,
+    level_after INT NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'review',
+    reviewed_at TIMESTAMPTZ NOT NULL
+);
+
+-- статистика вспоминания по пользователю
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_reviewed ON review_logs (user_id, reviewed_at);
+CREATE INDEX IF NOT EXISTS idx_review_logs_note_id ON review_logs (note_id);
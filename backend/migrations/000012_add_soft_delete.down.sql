@@ -0,0 +1,22 @@
+-- Записи из корзины удаляются окончательно, иначе уникальные индексы не восстановить
+DELETE FROM notes WHERE deleted_at IS NOT NULL;
+DELETE FROM folders WHERE deleted_at IS NOT NULL;
+DELETE FROM tags WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tags_userid_name_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_userid_name_lower
+    ON tags (user_id, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tags_user_name
+    ON tags (user_id, name);
+
+DROP INDEX IF EXISTS ux_folders_user_parent_name;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_folders_user_parent_name
+    ON folders (user_id, parent_id, name);
+
+DROP INDEX IF EXISTS idx_tags_deleted_at;
+DROP INDEX IF EXISTS idx_folders_deleted_at;
+DROP INDEX IF EXISTS idx_notes_deleted_at;
+
+ALTER TABLE tags DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE folders DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE notes DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,22 @@
+-- Мягкое удаление: заметки, папки и теги сначала попадают в корзину
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notes_deleted_at ON notes (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_folders_deleted_at ON folders (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags (deleted_at);
+
+-- Уникальность имён проверяется только среди неудалённых записей,
+-- иначе объект в корзине не даёт создать новый с тем же именем
+DROP INDEX IF EXISTS ux_folders_user_parent_name;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_folders_user_parent_name
+    ON folders (user_id, parent_id, name)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_user_id_name_key;
+DROP INDEX IF EXISTS ux_tags_user_name;
+DROP INDEX IF EXISTS idx_tags_userid_name_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_userid_name_lower
+    ON tags (user_id, LOWER(name))
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_note_revisions_user_id;
+DROP INDEX IF EXISTS ux_note_revisions_note_revision;
+
+DROP TABLE IF EXISTS note_revisions;
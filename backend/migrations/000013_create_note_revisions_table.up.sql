@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id UUID PRIMARY KEY,
+    note_id UUID NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    restored_from INT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- номер ревизии уникален в пределах заметки
+CREATE UNIQUE INDEX IF NOT EXISTS ux_note_revisions_note_revision ON note_revisions (note_id, revision);
+CREATE INDEX IF NOT EXISTS idx_note_revisions_user_id ON note_revisions (user_id);
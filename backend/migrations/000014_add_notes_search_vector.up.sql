@@ -0,0 +1,11 @@
+-- Полнотекстовый поиск по заголовку и тексту заметки.
+-- Конфигурация russian стеммит кириллицу русским стеммером, а латиницу — английским,
+-- заголовок весит больше текста.
+ALTER TABLE notes
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+            setweight(to_tsvector('russian', COALESCE(content, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector);
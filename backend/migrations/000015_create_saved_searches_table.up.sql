@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    search TEXT NOT NULL DEFAULT '',
+    -- папка может быть удалена, поиск при этом остаётся без фильтра по папке
+    folder_id UUID NULL REFERENCES folders(id) ON DELETE SET NULL,
+    -- JSON-массив ID тегов
+    tag_ids TEXT NOT NULL DEFAULT '[]',
+    archived BOOLEAN NULL,
+    sort_by VARCHAR(20) NOT NULL DEFAULT '',
+    "order" VARCHAR(4) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- имена сохранённых поисков уникальны в пределах пользователя
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_user_name ON saved_searches (user_id, LOWER(name));
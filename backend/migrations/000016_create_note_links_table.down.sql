@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_note_links_user_dangling;
+DROP INDEX IF EXISTS idx_note_links_target_id;
+DROP INDEX IF EXISTS ux_note_links_source_text;
+
+DROP TABLE IF EXISTS note_links;
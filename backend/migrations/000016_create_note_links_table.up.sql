@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS note_links (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    -- NULL — висячая ссылка: заметки с таким заголовком нет
+    target_id UUID NULL REFERENCES notes(id) ON DELETE SET NULL,
+    text VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_note_links_source_text ON note_links (source_id, LOWER(text));
+CREATE INDEX IF NOT EXISTS idx_note_links_target_id ON note_links (target_id);
+-- поиск висячих ссылок по тексту при создании и переименовании заметок
+CREATE INDEX IF NOT EXISTS idx_note_links_user_dangling ON note_links (user_id, LOWER(text)) WHERE target_id IS NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_notes_note_type_id;
+
+ALTER TABLE notes DROP COLUMN IF EXISTS fields;
+ALTER TABLE notes DROP COLUMN IF EXISTS note_type_id;
+
+DROP INDEX IF EXISTS idx_note_types_user_name;
+
+DROP TABLE IF EXISTS note_types;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS note_types (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    -- JSON-массив описаний полей
+    fields TEXT NOT NULL DEFAULT '[]',
+    prompt_template TEXT NOT NULL,
+    answer_template TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- имена типов уникальны в пределах пользователя
+CREATE UNIQUE INDEX IF NOT EXISTS idx_note_types_user_name ON note_types (user_id, LOWER(name));
+
+-- NULL — встроенный тип Basic; тип нельзя удалить, пока на него ссылаются заметки
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS note_type_id UUID NULL REFERENCES note_types(id) ON DELETE RESTRICT;
+-- JSON-объект значений полей
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS fields TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notes_note_type_id ON notes (note_type_id);
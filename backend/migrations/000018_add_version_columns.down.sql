@@ -0,0 +1,3 @@
+ALTER TABLE tags DROP COLUMN IF EXISTS version;
+ALTER TABLE folders DROP COLUMN IF EXISTS version;
+ALTER TABLE notes DROP COLUMN IF EXISTS version;
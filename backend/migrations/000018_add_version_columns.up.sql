@@ -0,0 +1,4 @@
+-- версия для оптимистичной блокировки: растёт на 1 при каждом изменении, отдаётся клиенту как ETag
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
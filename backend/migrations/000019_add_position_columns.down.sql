@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notes_folder_position;
+DROP INDEX IF EXISTS idx_folders_parent_position;
+ALTER TABLE notes DROP COLUMN IF EXISTS position;
+ALTER TABLE folders DROP COLUMN IF EXISTS position;
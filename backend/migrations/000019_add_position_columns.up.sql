@@ -0,0 +1,30 @@
+-- Ручной порядок папок и заметок внутри родителя: ключи дробной индексации (internal/fractional).
+-- Ключи сравниваются побайтно, поэтому колонка использует правило сортировки "C".
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS position VARCHAR(255) COLLATE "C" NOT NULL DEFAULT '';
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS position VARCHAR(255) COLLATE "C" NOT NULL DEFAULT '';
+
+-- Начальные ключи: "c" и три цифры base62 по порядку внутри родителя
+-- (папки — по имени, заметки — по дате создания)
+CREATE OR REPLACE FUNCTION pg_temp.position_key(n BIGINT) RETURNS TEXT AS $$
+    SELECT 'c' ||
+        substr('0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz', (n / 3844 % 62)::INT + 1, 1) ||
+        substr('0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz', (n / 62 % 62)::INT + 1, 1) ||
+        substr('0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz', (n % 62)::INT + 1, 1)
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE folders SET position = pg_temp.position_key(ordered.n)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, parent_id ORDER BY name, id) - 1 AS n
+    FROM folders
+) ordered
+WHERE folders.id = ordered.id;
+
+UPDATE notes SET position = pg_temp.position_key(ordered.n)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, folder_id ORDER BY created_at, id) - 1 AS n
+    FROM notes
+) ordered
+WHERE notes.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS idx_folders_parent_position ON folders (user_id, parent_id, position);
+CREATE INDEX IF NOT EXISTS idx_notes_folder_position ON notes (user_id, folder_id, position);
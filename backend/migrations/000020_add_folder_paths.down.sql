@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_folders_user_path;
+ALTER TABLE folders DROP COLUMN IF EXISTS path;
@@ -0,0 +1,18 @@
+-- Материализованный путь папки: "/<id корня>/…/<id папки>/". Поддерево папки — строки,
+-- путь которых начинается с её пути; путь поддерживает FolderService при создании и переносе.
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS path TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+WITH RECURSIVE tree AS (
+    SELECT id, '/' || id::TEXT || '/' AS path
+    FROM folders
+    WHERE parent_id IS NULL
+    UNION ALL
+    SELECT f.id, tree.path || f.id::TEXT || '/'
+    FROM folders f
+    INNER JOIN tree ON f.parent_id = tree.id
+)
+UPDATE folders SET path = tree.path
+FROM tree
+WHERE folders.id = tree.id;
+
+CREATE INDEX IF NOT EXISTS idx_folders_user_path ON folders (user_id, path);
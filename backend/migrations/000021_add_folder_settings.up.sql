@@ -0,0 +1,3 @@
+-- Настройки обучения папки (deck options): JSON-объект models.FolderSettings.
+-- NULL и отсутствующие поля наследуются от родительской папки.
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS settings TEXT NULL;
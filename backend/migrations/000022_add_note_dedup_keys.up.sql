@@ -0,0 +1,11 @@
+-- LSH-индекс дубликатов: ключи заголовка и полос MinHash-сигнатуры каждой заметки.
+-- Проверка при создании заметки ищет кандидатов по ключам, а не читает всю коллекцию
+CREATE TABLE IF NOT EXISTS note_dedup_keys (
+    note_id UUID NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    key BIGINT NOT NULL,
+    user_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    PRIMARY KEY (note_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_dedup_keys_user_key ON note_dedup_keys (user_id, key);
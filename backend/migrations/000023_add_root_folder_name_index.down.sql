@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ux_folders_user_root_name;
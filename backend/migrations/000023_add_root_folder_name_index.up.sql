@@ -0,0 +1,19 @@
+-- ux_folders_user_parent_name не срабатывает для корневых папок: NULL в parent_id
+-- не равны друг другу, и два корня с одним именем проходят. Корни получают свой индекс.
+-- Уже существующие совпадения переименовываются: старейшая папка сохраняет имя
+UPDATE folders f
+SET name = f.name || ' (' || LEFT(f.id::text, 8) || ')'
+WHERE f.parent_id IS NULL
+  AND f.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM folders o
+      WHERE o.user_id = f.user_id
+        AND o.parent_id IS NULL
+        AND o.deleted_at IS NULL
+        AND o.name = f.name
+        AND (o.created_at, o.id) < (f.created_at, f.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_folders_user_root_name
+    ON folders (user_id, name)
+    WHERE parent_id IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE notes DROP CONSTRAINT IF EXISTS fk_notes_folder;
+ALTER TABLE notes
+    ADD CONSTRAINT fk_notes_folder
+        FOREIGN KEY (folder_id) REFERENCES folders(id)
+            ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+-- Окончательное удаление папки из корзины не должно уносить заметки: живая заметка,
+-- которая всё ещё ссылается на папку, остаётся без папки, а не удаляется каскадом
+ALTER TABLE notes DROP CONSTRAINT IF EXISTS fk_notes_folder;
+ALTER TABLE notes
+    ADD CONSTRAINT fk_notes_folder
+        FOREIGN KEY (folder_id) REFERENCES folders(id)
+            ON DELETE SET NULL;
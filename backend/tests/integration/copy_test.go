@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupCopyTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	tagRepo := repository.NewTagRepository(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	bulkController := controller.NewNoteBulkController(service.NewNoteBulkService(repository.NewNoteBulkRepository(db), noteService, folderRepo, tagRepo))
+	linkService := service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo)
+	copyService := service.NewCopyService(repository.NewCopyRepository(db), noteRepo, folderRepo, linkService)
+	copyController := controller.NewCopyController(copyService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteBulk: bulkController, Copy: copyController})
+
+	return r
+}
+
+func createSubfolder(t *testing.T, r *gin.Engine, token, name string, parentID string) models.Folder {
+	w := doAuthRequest(t, r, token, "POST", "/folders", map[string]interface{}{"name": name, "parent_id": parentID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var folder models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &folder))
+	return folder
+}
+
+func copyFolder(t *testing.T, r *gin.Engine, token, path string) dto.FolderCopyResult {
+	w := doAuthRequest(t, r, token, "POST", path, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var result dto.FolderCopyResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func folderNotes(t *testing.T, r *gin.Engine, token, folderID string) []models.Note {
+	w := doAuthRequest(t, r, token, "GET", "/notes?limit=100&folder_id="+folderID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page.Notes
+}
+
+func TestCopy_DuplicateNote(t *testing.T) {
+	r := setupCopyTestRouter(t)
+	token := registerAndLogin(t, r, "duplicate@example.com", "copypass", "Duplicate")
+	otherToken := registerAndLogin(t, r, "duplicate2@example.com", "copypass", "Duplicate2")
+
+	noteID := createNoteWithContent(t, r, token, "Cell", "membrane")
+	folder := createFolder(t, r, token, "Biology")
+	tag := createTag(t, r, token, "biology")
+	w := doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{
+		"folder_id":      folder.ID.String(),
+		"tag_ids":        []string{tag.ID.String()},
+		"memory_level":   70,
+		"next_review_at": "2030-01-01T00:00:00Z",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteID+"/duplicate", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var clone models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clone))
+	assert.NotEqual(t, noteID, clone.ID.String())
+	assert.Equal(t, "Cell (copy)", clone.Title)
+	assert.Equal(t, "membrane", clone.Content)
+	require.NotNil(t, clone.FolderID)
+	assert.Equal(t, folder.ID, *clone.FolderID)
+	assert.Equal(t, []string{tag.ID.String()}, tagIDsOf(clone))
+	assert.Equal(t, 70, clone.MemoryLevel)
+	require.NotNil(t, clone.NextReviewAt)
+	assert.Equal(t, 1, clone.Version)
+
+	// Сброс расписания
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteID+"/duplicate?reset_scheduling=true", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clone))
+	assert.Equal(t, 0, clone.MemoryLevel)
+	assert.Nil(t, clone.NextReviewAt)
+	assert.Equal(t, []string{tag.ID.String()}, noteTagIDs(t, r, token, clone.ID.String()))
+
+	// Оригинал не изменился
+	assert.Equal(t, 70, getNote(t, r, token, noteID).MemoryLevel)
+	assert.Len(t, folderNotes(t, r, token, folder.ID.String()), 3)
+
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteID+"/duplicate?reset_scheduling=maybe", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, otherToken, "POST", "/notes/"+noteID+"/duplicate", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCopy_CopyFolderSubtree(t *testing.T) {
+	r := setupCopyTestRouter(t)
+	token := registerAndLogin(t, r, "copyfolder@example.com", "copypass", "CopyFolder")
+	otherToken := registerAndLogin(t, r, "copyfolder2@example.com", "copypass", "CopyFolder2")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	organelles := createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	archive := createFolder(t, r, token, "Archive")
+	tag := createTag(t, r, token, "biology")
+
+	cell := createNoteWithContent(t, r, token, "Cell", "see [[Mitochondria]]")
+	mito := createNoteWithContent(t, r, token, "Mitochondria", "powerhouse")
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{cell}, "action": dto.BulkMove, "folder_id": cells.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{mito}, "action": dto.BulkMove, "folder_id": organelles.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{cell, mito}, "action": dto.BulkAddTags, "tag_ids": []string{tag.ID.String()}})
+
+	// Копия в корень получает суффикс, поддерево и заметки копируются
+	result := copyFolder(t, r, token, "/folders/"+biology.ID.String()+"/copy")
+	assert.Equal(t, "Biology (copy)", result.Folder.Name)
+	assert.Nil(t, result.Folder.ParentID)
+	assert.NotEqual(t, biology.ID, result.Folder.ID)
+	assert.Equal(t, 3, result.Folders)
+	assert.Equal(t, 2, result.Notes)
+
+	w := doAuthRequest(t, r, token, "GET", "/folders/tree", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tree []*dto.FolderNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	var copied *dto.FolderNode
+	for _, node := range tree {
+		if node.ID == result.Folder.ID.String() {
+			copied = node
+		}
+	}
+	require.NotNil(t, copied)
+	require.Len(t, copied.Children, 1)
+	assert.Equal(t, "Cells", copied.Children[0].Name)
+	require.Len(t, copied.Children[0].Children, 1)
+	assert.Equal(t, "Organelles", copied.Children[0].Children[0].Name)
+
+	notes := folderNotes(t, r, token, copied.Children[0].ID)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "Cell", notes[0].Title)
+	assert.NotEqual(t, cell, notes[0].ID.String())
+	assert.Equal(t, []string{tag.ID.String()}, tagIDsOf(notes[0]))
+	notes = folderNotes(t, r, token, copied.Children[0].Children[0].ID)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "Mitochondria", notes[0].Title)
+
+	// Повторная копия — "(copy 2)"; в другую папку — без суффикса
+	result = copyFolder(t, r, token, "/folders/"+biology.ID.String()+"/copy")
+	assert.Equal(t, "Biology (copy 2)", result.Folder.Name)
+	result = copyFolder(t, r, token, "/folders/"+cells.ID.String()+"/copy?target="+archive.ID.String()+"&reset_scheduling=true")
+	assert.Equal(t, "Cells", result.Folder.Name)
+	require.NotNil(t, result.Folder.ParentID)
+	assert.Equal(t, archive.ID, *result.Folder.ParentID)
+	assert.Equal(t, 2, result.Folders)
+	assert.Equal(t, 2, result.Notes)
+
+	// Нельзя копировать в себя и в потомка; чужие папки не видны
+	w = doAuthRequest(t, r, token, "POST", "/folders/"+biology.ID.String()+"/copy?target="+biology.ID.String(), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/folders/"+biology.ID.String()+"/copy?target="+organelles.ID.String(), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, otherToken, "POST", "/folders/"+biology.ID.String()+"/copy", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	foreign := createFolder(t, r, otherToken, "Foreign")
+	w = doAuthRequest(t, r, token, "POST", "/folders/"+biology.ID.String()+"/copy?target="+foreign.ID.String(), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
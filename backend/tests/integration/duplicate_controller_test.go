@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/dedup"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupDuplicateTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	tagRepo := repository.NewTagRepository(db)
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	noteTagController := controller.NewNoteTagController(service.NewNoteTagService(noteRepo, tagRepo))
+
+	duplicateService := service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold)
+	duplicateController := controller.NewDuplicateController(duplicateService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Tag: tagController, NoteTag: noteTagController, Duplicate: duplicateController})
+
+	return r, db
+}
+
+const photosynthesisText = "Photosynthesis converts light energy into chemical energy. " +
+	"Chlorophyll in the chloroplasts absorbs light, water is split and oxygen is released, " +
+	"while carbon dioxide is fixed into glucose in the Calvin cycle."
+
+func TestDuplicates_CreateReportAndMerge(t *testing.T) {
+	r, db := setupDuplicateTestRouter(t)
+	token := registerAndLogin(t, r, "duplicates@example.com", "duppass", "Dup")
+
+	original := createNoteWithContent(t, r, token, "Photosynthesis", photosynthesisText)
+
+	// Тот же заголовок после нормализации — предупреждение, заметка создаётся
+	w := doAuthRequest(t, r, token, "POST", "/notes", map[string]string{"title": "  photosynthesis!", "content": "Light to sugar"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var sameTitle models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sameTitle))
+	assert.Equal(t, []string{original}, sameTitle.DuplicateIDs)
+
+	// Почти тот же текст под другим заголовком: reject — 409 со списком похожих
+	nearText := "Photosynthesis converts light energy into chemical energy. " +
+		"Chlorophyll in the chloroplasts absorbs sunlight, water is split and oxygen is released, " +
+		"while carbon dioxide is fixed into glucose in the Calvin cycle."
+	body := map[string]string{"title": "Light reactions", "content": nearText}
+	w = doAuthRequest(t, r, token, "POST", "/notes?on_duplicate=reject", body)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var conflict struct {
+		DuplicateIDs []string `json:"duplicate_ids"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, []string{original}, conflict.DuplicateIDs)
+
+	// allow — без проверки
+	w = doAuthRequest(t, r, token, "POST", "/notes?on_duplicate=allow", body)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var nearCopy models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &nearCopy))
+	assert.Empty(t, nearCopy.DuplicateIDs)
+
+	w = doAuthRequest(t, r, token, "POST", "/notes?on_duplicate=skip", map[string]string{"title": "x", "content": "y"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Другая заметка со ссылкой на почти-копию
+	linking := createNoteWithContent(t, r, token, "Cell wall", "Plant cells have a rigid wall, see [[Light reactions]]")
+
+	// Отчёт: три заметки в одной группе (по заголовку и по тексту), Cell wall — отдельно
+	w = doAuthRequest(t, r, token, "GET", "/notes/duplicates", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report dto.DuplicateReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, 1, report.Total)
+	group := report.Groups[0]
+	require.Len(t, group.Notes, 3)
+	assert.Equal(t, original, group.Notes[0].ID)
+	assert.True(t, group.TitleMatch)
+	assert.GreaterOrEqual(t, group.Similarity, dedup.DefaultThreshold)
+
+	// Подготовка к слиянию: теги, расписание и журнал повторений у дубликатов
+	bio := createTag(t, r, token, "bio")
+	exam := createTag(t, r, token, "exam")
+	for noteID, tagID := range map[string]string{original: bio.ID.String(), sameTitle.ID.String(): bio.ID.String(), nearCopy.ID.String(): exam.ID.String()} {
+		w = doAuthRequest(t, r, token, "POST", "/notes/"+noteID+"/tags/"+tagID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	nextReview := time.Now().Add(72 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", original).Update("memory_level", 10).Error)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", nearCopy.ID).
+		Updates(map[string]interface{}{"memory_level": 40, "next_review_at": nextReview}).Error)
+	require.NoError(t, db.Create(&models.ReviewLog{UserID: nearCopy.UserID, NoteID: nearCopy.ID, Remembered: true,
+		LevelBefore: 20, LevelAfter: 40, Source: models.ReviewSourceReview, ReviewedAt: time.Now()}).Error)
+
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+original+"/merge", dto.MergeNotesInput{SourceIDs: []string{original}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+original+"/merge", dto.MergeNotesInput{SourceIDs: []string{"00000000-0000-0000-0000-000000000001"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+original+"/merge", map[string]interface{}{"source_ids": []string{}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+original+"/merge",
+		dto.MergeNotesInput{SourceIDs: []string{sameTitle.ID.String(), nearCopy.ID.String()}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var merged models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &merged))
+	assert.Equal(t, "Photosynthesis", merged.Title)
+	assert.Equal(t, photosynthesisText, merged.Content)
+	assert.Equal(t, 40, merged.MemoryLevel)
+	// Слияние меняет заметку — версия растёт
+	assert.Equal(t, 2, merged.Version)
+	require.NotNil(t, merged.NextReviewAt)
+	assert.True(t, nextReview.Equal(*merged.NextReviewAt))
+	tagNames := make([]string, 0)
+	for _, tag := range merged.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	assert.ElementsMatch(t, []string{"bio", "exam"}, tagNames)
+
+	// Дубликаты в корзине, журнал и входящие ссылки перешли к оставшейся заметке
+	for _, id := range []string{sameTitle.ID.String(), nearCopy.ID.String()} {
+		w = doAuthRequest(t, r, token, "GET", "/notes/"+id, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+	var logs int64
+	require.NoError(t, db.Model(&models.ReviewLog{}).Where("note_id = ?", original).Count(&logs).Error)
+	assert.Equal(t, int64(1), logs)
+	var link models.NoteLink
+	require.NoError(t, db.Where("source_id = ?", linking).First(&link).Error)
+	require.NotNil(t, link.TargetID)
+	assert.Equal(t, original, link.TargetID.String())
+
+	w = doAuthRequest(t, r, token, "GET", "/notes/duplicates", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 0, report.Total)
+	assert.Empty(t, report.Groups)
+
+	// Ключи индекса пересчитываются после правки: старый заголовок больше не совпадает
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", original).
+		Updates(map[string]interface{}{"title": "Chlorophyll", "version": gorm.Expr("version + 1")}).Error)
+	w = doAuthRequest(t, r, token, "POST", "/notes", map[string]string{"title": "Photosynthesis", "content": "Light to sugar"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var retitled models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &retitled))
+	assert.Empty(t, retitled.DuplicateIDs)
+	w = doAuthRequest(t, r, token, "POST", "/notes", map[string]string{"title": "chlorophyll", "content": "Green pigment"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var renamed models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &renamed))
+	assert.Equal(t, []string{original}, renamed.DuplicateIDs)
+}
@@ -0,0 +1,300 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupExamTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+
+	folderService := service.NewFolderService(folderRepo)
+	folderController := controller.NewFolderController(folderService)
+
+	tagRepo := repository.NewTagRepository(db)
+	tagService := service.NewTagService(tagRepo)
+	tagController := controller.NewTagController(tagService)
+
+	noteTagService := service.NewNoteTagService(noteRepo, tagRepo)
+	noteTagController := controller.NewNoteTagController(noteTagService)
+
+	examRepo := repository.NewExamRepository(db)
+	examService := service.NewExamService(examRepo, noteRepo, repository.NewReviewLogRepository(db), repository.NewFolderRepo(db))
+	examController := controller.NewExamController(examService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, Exam: examController})
+
+	return r, db
+}
+
+func TestExam_FullFlow(t *testing.T) {
+	r, db := setupExamTestRouter(t)
+	token := registerAndLogin(t, r, "examuser@example.com", "exampass", "ExamUser")
+
+	folder := createFolder(t, r, token, "Exam Folder")
+	noteA := createNoteWithFolderAndTags(t, r, token, "Exam A", folder.ID.String(), nil)
+	noteB := createNoteWithFolderAndTags(t, r, token, "Exam B", folder.ID.String(), nil)
+	_ = createNoteWithFolderAndTags(t, r, token, "Outside", "", nil)
+
+	// Без папки и тегов — 400
+	w := doAuthRequest(t, r, token, "POST", "/exams", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Старт экзамена по папке
+	w = doAuthRequest(t, r, token, "POST", "/exams", map[string]interface{}{
+		"folder_id":        folder.ID.String(),
+		"duration_seconds": 60,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var exam models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exam))
+	assert.Equal(t, models.ExamStatusInProgress, exam.Status)
+	require.Len(t, exam.Questions, 2)
+	assert.WithinDuration(t, exam.StartedAt.Add(60*time.Second), exam.Deadline, time.Second)
+
+	questionNotes := []string{exam.Questions[0].NoteID.String(), exam.Questions[1].NoteID.String()}
+	assert.ElementsMatch(t, []string{noteA.ID.String(), noteB.ID.String()}, questionNotes)
+
+	// Порядок вопросов фиксирован
+	w = doAuthRequest(t, r, token, "GET", "/exams/"+exam.ID.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var fetched models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+	assert.Equal(t, exam.Questions[0].ID, fetched.Questions[0].ID)
+	assert.Equal(t, exam.Questions[1].ID, fetched.Questions[1].ID)
+
+	// Ответ на первый вопрос
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/answers", map[string]interface{}{
+		"question_id": exam.Questions[0].ID.String(),
+		"correct":     true,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Повторный ответ — 409
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/answers", map[string]interface{}{
+		"question_id": exam.Questions[0].ID.String(),
+		"correct":     false,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Сабмит: 1 из 2
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/submit", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var submitted models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+	assert.Equal(t, models.ExamStatusSubmitted, submitted.Status)
+	assert.Equal(t, 1, submitted.CorrectAnswers)
+	assert.Equal(t, 2, submitted.TotalQuestions)
+	assert.Equal(t, 50.0, submitted.Score)
+
+	// Повторный сабмит — 409
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/submit", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Без apply_to_schedule расписание не меняется
+	var stored models.Note
+	require.NoError(t, db.First(&stored, "id = ?", exam.Questions[0].NoteID).Error)
+	assert.Equal(t, 0, stored.MemoryLevel)
+	assert.Nil(t, stored.NextReviewAt)
+
+	// Результат есть в списке
+	w = doAuthRequest(t, r, token, "GET", "/exams", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var exams []models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exams))
+	require.Len(t, exams, 1)
+	assert.Equal(t, 50.0, exams[0].Score)
+	require.NotNil(t, exams[0].Questions[0].Correct)
+	assert.True(t, *exams[0].Questions[0].Correct)
+	assert.Nil(t, exams[0].Questions[1].Correct)
+}
+
+func TestExam_DeadlineAndSchedule(t *testing.T) {
+	r, db := setupExamTestRouter(t)
+	token := registerAndLogin(t, r, "examdeadline@example.com", "exampass", "ExamDeadline")
+
+	tag := createTag(t, r, token, "Exam Tag")
+	note := createNoteWithFolderAndTags(t, r, token, "Tagged", "", []string{tag.ID.String()})
+
+	w := doAuthRequest(t, r, token, "POST", "/exams", map[string]interface{}{
+		"tag_ids":           []string{tag.ID.String()},
+		"apply_to_schedule": true,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var exam models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exam))
+	require.Len(t, exam.Questions, 1)
+
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/answers", map[string]interface{}{
+		"question_id": exam.Questions[0].ID.String(),
+		"correct":     true,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Сдвигаем дедлайн в прошлое — новые ответы не принимаются
+	require.NoError(t, db.Model(&models.Exam{}).Where("id = ?", exam.ID).
+		Update("deadline", time.Now().Add(-time.Second)).Error)
+
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/submit", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var submitted models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+	assert.Equal(t, 100.0, submitted.Score)
+
+	// apply_to_schedule: правильный ответ поднял уровень памяти
+	var stored models.Note
+	require.NoError(t, db.First(&stored, "id = ?", note.ID).Error)
+	assert.Equal(t, 20, stored.MemoryLevel)
+	assert.NotNil(t, stored.NextReviewAt)
+
+	// Чужой экзамен не виден
+	otherToken := registerAndLogin(t, r, "examother@example.com", "exampass", "ExamOther")
+	w = doAuthRequest(t, r, otherToken, "GET", "/exams/"+exam.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// staleExamRepo отдаёт экзамен, прочитанный до того, как его завершил параллельный запрос:
+// перед возвратом снимка вызывается race — второй «запрос», который успевает завершить экзамен
+type staleExamRepo struct {
+	interfaces.ExamRepository
+	race func()
+}
+
+func (r *staleExamRepo) GetByID(ctx context.Context, userID, examID uuid.UUID) (*models.Exam, error) {
+	exam, err := r.ExamRepository.GetByID(ctx, userID, examID)
+	if r.race != nil && exam != nil && exam.Status == models.ExamStatusInProgress {
+		race := r.race
+		r.race = nil
+		race()
+	}
+	return exam, err
+}
+
+func TestExam_DoubleFinalize(t *testing.T) {
+	r, db := setupExamTestRouter(t)
+	token := registerAndLogin(t, r, "examrace@example.com", "exampass", "ExamRace")
+
+	tag := createTag(t, r, token, "Race Tag")
+	note := createNoteWithFolderAndTags(t, r, token, "Raced", "", []string{tag.ID.String()})
+	w := doAuthRequest(t, r, token, "POST", "/exams", map[string]interface{}{
+		"tag_ids":           []string{tag.ID.String()},
+		"apply_to_schedule": true,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var exam models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exam))
+	w = doAuthRequest(t, r, token, "POST", "/exams/"+exam.ID.String()+"/answers", map[string]interface{}{
+		"question_id": exam.Questions[0].ID.String(),
+		"correct":     true,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, db.Model(&models.Exam{}).Where("id = ?", exam.ID).
+		Update("deadline", time.Now().Add(-time.Second)).Error)
+
+	noteRepo := repository.NewNoteRepository(db)
+	newExamService := func(examRepo interfaces.ExamRepository) *service.ExamService {
+		return service.NewExamService(examRepo, noteRepo, repository.NewReviewLogRepository(db), repository.NewFolderRepo(db))
+	}
+	var owner models.Exam
+	require.NoError(t, db.First(&owner, "id = ?", exam.ID).Error)
+	userID := owner.UserID.String()
+	examID := exam.ID.String()
+
+	// Оба чтения видят экзамен незавершённым; второе успевает завершить его первым
+	stale := &staleExamRepo{ExamRepository: repository.NewExamRepository(db)}
+	stale.race = func() {
+		_, err := newExamService(repository.NewExamRepository(db)).GetExam(context.Background(), userID, examID)
+		require.NoError(t, err)
+	}
+	read, err := newExamService(stale).GetExam(context.Background(), userID, examID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExamStatusSubmitted, read.Status)
+	assert.Equal(t, 100.0, read.Score)
+
+	// Сабмит по устаревшему снимку тоже не применяет ответы повторно
+	require.NoError(t, db.Model(&models.Exam{}).Where("id = ?", exam.ID).Update("status", models.ExamStatusInProgress).Error)
+	stale.race = func() {
+		_, err := newExamService(repository.NewExamRepository(db)).SubmitExam(context.Background(), userID, examID)
+		require.NoError(t, err)
+	}
+	_, err = newExamService(stale).SubmitExam(context.Background(), userID, examID)
+	assert.ErrorIs(t, err, apperrors.ErrExamSubmitted)
+
+	// Первая пара чтений применила ответ один раз, сабмит после ручного сброса статуса — ещё раз
+	var logs int64
+	require.NoError(t, db.Model(&models.ReviewLog{}).Where("note_id = ?", note.ID).Count(&logs).Error)
+	assert.Equal(t, int64(2), logs)
+}
+
+func TestExam_AnswerAfterConcurrentSubmit(t *testing.T) {
+	r, db := setupExamTestRouter(t)
+	token := registerAndLogin(t, r, "examlate@example.com", "exampass", "ExamLate")
+
+	tag := createTag(t, r, token, "Late Tag")
+	createNoteWithFolderAndTags(t, r, token, "Late", "", []string{tag.ID.String()})
+	w := doAuthRequest(t, r, token, "POST", "/exams", map[string]interface{}{
+		"tag_ids": []string{tag.ID.String()},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var exam models.Exam
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exam))
+
+	newExamService := func(examRepo interfaces.ExamRepository) *service.ExamService {
+		return service.NewExamService(examRepo, repository.NewNoteRepository(db), repository.NewReviewLogRepository(db), repository.NewFolderRepo(db))
+	}
+	var owner models.Exam
+	require.NoError(t, db.First(&owner, "id = ?", exam.ID).Error)
+	userID := owner.UserID.String()
+	examID := exam.ID.String()
+
+	// Ответ прочитал экзамен незавершённым, а сабмит успел завершить его раньше записи ответа
+	stale := &staleExamRepo{ExamRepository: repository.NewExamRepository(db)}
+	stale.race = func() {
+		_, err := newExamService(repository.NewExamRepository(db)).SubmitExam(context.Background(), userID, examID)
+		require.NoError(t, err)
+	}
+	_, err := newExamService(stale).AnswerQuestion(context.Background(), userID, examID, &dto.ExamAnswerInput{
+		QuestionID: exam.Questions[0].ID.String(),
+		Correct:    true,
+	})
+	assert.ErrorIs(t, err, apperrors.ErrExamSubmitted)
+
+	var question models.ExamQuestion
+	require.NoError(t, db.First(&question, "id = ?", exam.Questions[0].ID).Error)
+	assert.Nil(t, question.AnsweredAt)
+}
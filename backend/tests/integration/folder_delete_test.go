@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+)
+
+func deleteFolder(t *testing.T, r *gin.Engine, token, method, path string) dto.FolderDeleteResult {
+	w := doAuthRequest(t, r, token, method, path, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result dto.FolderDeleteResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestFolderDelete_Modes(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "folderdelete@example.com", "deletepass", "FolderDelete")
+	otherToken := registerAndLogin(t, r, "folderdelete2@example.com", "deletepass", "FolderDelete2")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	organelles := createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	createFolder(t, r, token, "Organelles")
+
+	life := createNoteWithContent(t, r, token, "Life", "1")
+	membrane := createNoteWithContent(t, r, token, "Membrane", "2")
+	ribosome := createNoteWithContent(t, r, token, "Ribosome", "3")
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{life}, "action": dto.BulkMove, "folder_id": biology.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{membrane}, "action": dto.BulkMove, "folder_id": cells.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{ribosome}, "action": dto.BulkMove, "folder_id": organelles.ID.String()})
+
+	// Предпросмотр ничего не меняет; по умолчанию — cascade
+	preview := deleteFolder(t, r, token, "GET", "/folders/"+biology.ID.String()+"/delete-preview")
+	assert.Equal(t, dto.FolderDeleteCascade, preview.Mode)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, 2, preview.Subfolders)
+	assert.Equal(t, 3, preview.Notes)
+	assert.ElementsMatch(t, []uuid.UUID{biology.ID, cells.ID, organelles.ID}, preview.TrashedFolders)
+	assert.Len(t, preview.TrashedNotes, 3)
+	assert.Empty(t, preview.MovedNotes)
+
+	preview = deleteFolder(t, r, token, "GET", "/folders/"+cells.ID.String()+"/delete-preview?mode=move_to_parent")
+	assert.Equal(t, []uuid.UUID{cells.ID}, preview.TrashedFolders)
+	assert.Equal(t, []uuid.UUID{organelles.ID}, preview.MovedFolders)
+	assert.Equal(t, membrane, preview.MovedNotes[0].String())
+	require.NotNil(t, preview.TargetFolderID)
+	assert.Equal(t, biology.ID, *preview.TargetFolderID)
+	assert.Len(t, folderTree(t, r, token), 2)
+
+	w := doAuthRequest(t, r, token, "GET", "/folders/"+biology.ID.String()+"/delete-preview?mode=purge", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, otherToken, "DELETE", "/folders/"+biology.ID.String()+"?mode=unfile", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// move_to_parent: подпапка и заметка встают в конец родителя, поддерево не теряется
+	result := deleteFolder(t, r, token, "DELETE", "/folders/"+cells.ID.String()+"?mode=move_to_parent")
+	assert.False(t, result.DryRun)
+	assert.Equal(t, []uuid.UUID{organelles.ID}, result.MovedFolders)
+	tree := folderTree(t, r, token)
+	require.Equal(t, []string{"Biology", "Organelles"}, nodeNames(tree))
+	assert.Equal(t, []string{"Organelles"}, nodeNames(tree[0].Children))
+	assert.Equal(t, []string{"Life", "Membrane"}, notesInOrder(t, r, token, biology.ID.String()))
+	assert.Equal(t, []string{"Life", "Membrane", "Ribosome"}, subtreeTitles(t, r, token, biology.ID.String()))
+	assert.Equal(t, 3, getNote(t, r, token, membrane).Version)
+
+	// В корне уже есть Organelles — поднять подпапку Biology нельзя
+	w = doAuthRequest(t, r, token, "DELETE", "/folders/"+biology.ID.String()+"?mode=move_to_parent", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Len(t, folderTree(t, r, token), 2)
+
+	// unfile: папки уходят в корзину, заметки остаются без папки
+	result = deleteFolder(t, r, token, "DELETE", "/folders/"+biology.ID.String()+"?mode=unfile")
+	assert.ElementsMatch(t, []uuid.UUID{biology.ID, organelles.ID}, result.TrashedFolders)
+	assert.Empty(t, result.TrashedNotes)
+	assert.Len(t, result.MovedNotes, 3)
+	assert.Nil(t, result.TargetFolderID)
+	assert.Equal(t, []string{"Organelles"}, nodeNames(folderTree(t, r, token)))
+	assert.Equal(t, []string{"Life", "Membrane", "Ribosome"}, notesInOrder(t, r, token, ""))
+	for _, id := range []string{life, membrane, ribosome} {
+		assert.Nil(t, getNote(t, r, token, id).FolderID)
+	}
+}
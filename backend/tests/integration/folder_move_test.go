@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/models"
+)
+
+func TestFolderMove_ParentChange(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "foldermove@example.com", "movepass", "FolderMove")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	organelles := createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	chemistry := createFolder(t, r, token, "Chemistry")
+	archive := createFolder(t, r, token, "Archive")
+
+	// Поддерево переезжает вместе с папкой
+	w := moveFolder(t, r, token, cells.ID.String(), map[string]interface{}{"parent_id": chemistry.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var moved models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	require.NotNil(t, moved.ParentID)
+	assert.Equal(t, chemistry.ID, *moved.ParentID)
+	assert.Equal(t, 2, moved.Version)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	tree := folderTree(t, r, token)
+	require.Equal(t, []string{"Biology", "Chemistry", "Archive"}, nodeNames(tree))
+	assert.Empty(t, tree[0].Children)
+	require.Equal(t, []string{"Cells"}, nodeNames(tree[1].Children))
+	assert.Equal(t, []string{"Organelles"}, nodeNames(tree[1].Children[0].Children))
+
+	// В корень перед другой папкой
+	w = moveFolder(t, r, token, organelles.ID.String(), map[string]interface{}{
+		"parent_id": "", "before": biology.ID.String(),
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Organelles", "Biology", "Chemistry", "Archive"}, nodeNames(folderTree(t, r, token)))
+
+	// К новому родителю после его папки
+	w = moveFolder(t, r, token, archive.ID.String(), map[string]interface{}{
+		"parent_id": chemistry.ID.String(), "after": cells.ID.String(),
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tree = folderTree(t, r, token)
+	assert.Equal(t, []string{"Cells", "Archive"}, nodeNames(tree[2].Children))
+
+	// Сосед должен быть у нового родителя
+	w = moveFolder(t, r, token, organelles.ID.String(), map[string]interface{}{
+		"parent_id": biology.ID.String(), "before": chemistry.ID.String(),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = moveFolder(t, r, token, cells.ID.String(), map[string]interface{}{"parent_id": "not-a-uuid"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = moveFolder(t, r, token, cells.ID.String(), map[string]interface{}{"parent_id": "550e8400-e29b-41d4-a716-446655440000"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFolderMove_RejectsCyclesAndDuplicates(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "foldercycle@example.com", "movepass", "FolderCycle")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	organelles := createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	chemistry := createFolder(t, r, token, "Chemistry")
+	createSubfolder(t, r, token, "Cells", chemistry.ID.String())
+
+	// В себя и в подпапку — 409, и через move, и через PUT
+	for _, target := range []string{biology.ID.String(), cells.ID.String(), organelles.ID.String()} {
+		w := moveFolder(t, r, token, biology.ID.String(), map[string]interface{}{"parent_id": target})
+		assert.Equal(t, http.StatusConflict, w.Code, target)
+	}
+	w := doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String(), map[string]interface{}{"parent_id": organelles.ID.String(), "version": 1})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// У Chemistry уже есть Cells
+	w = moveFolder(t, r, token, cells.ID.String(), map[string]interface{}{"parent_id": chemistry.ID.String()})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String(), map[string]interface{}{"parent_id": chemistry.ID.String(), "version": 1})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	// Под другим именем можно
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String(), map[string]interface{}{
+		"name": "Cell biology", "parent_id": chemistry.ID.String(), "version": 1,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Дерево осталось целым: все папки на месте
+	tree := folderTree(t, r, token)
+	require.Equal(t, []string{"Biology", "Chemistry"}, nodeNames(tree))
+	assert.Empty(t, tree[0].Children)
+	require.Equal(t, []string{"Cells", "Cell biology"}, nodeNames(tree[1].Children))
+	assert.Equal(t, []string{"Organelles"}, nodeNames(tree[1].Children[1].Children))
+
+	// Версия обязательна, как и у PUT: без неё 428, устаревшая отклоняется
+	w = doAuthRequest(t, r, token, "POST", "/folders/"+organelles.ID.String()+"/move", map[string]interface{}{"parent_id": ""})
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	w = moveFolder(t, r, token, organelles.ID.String(), map[string]interface{}{"parent_id": "", "version": 7})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = moveFolder(t, r, token, organelles.ID.String(), map[string]interface{}{"parent_id": "", "version": 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func ensureFolderPath(t *testing.T, r *gin.Engine, token, path string, status int) dto.FolderEnsurePathResult {
+	w := doAuthRequest(t, r, token, "POST", "/folders/ensure-path", map[string]interface{}{"path": path})
+	require.Equal(t, status, w.Code, w.Body.String())
+	var result dto.FolderEnsurePathResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func folderNames(folders []models.Folder) []string {
+	names := make([]string, len(folders))
+	for i, f := range folders {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func TestFolderPath_ResolveAndEnsure(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "folderpath@example.com", "pathpass", "FolderPath")
+	otherToken := registerAndLogin(t, r, "folderpath2@example.com", "pathpass", "FolderPath2")
+
+	languages := createFolder(t, r, token, "Languages")
+
+	// Создаются только недостающие папки
+	result := ensureFolderPath(t, r, token, "Languages/Spanish/Verbs", http.StatusCreated)
+	assert.Equal(t, []string{"Spanish", "Verbs"}, folderNames(result.Created))
+	assert.Equal(t, "Verbs", result.Folder.Name)
+	require.NotNil(t, result.Created[0].ParentID)
+	assert.Equal(t, languages.ID, *result.Created[0].ParentID)
+	verbs := result.Folder
+
+	again := ensureFolderPath(t, r, token, " /Languages//Spanish / Verbs/", http.StatusOK)
+	assert.Empty(t, again.Created)
+	assert.Equal(t, verbs.ID, again.Folder.ID)
+	tree := folderTree(t, r, token)
+	require.Len(t, tree, 1)
+	require.Len(t, tree[0].Children, 1)
+	assert.Len(t, tree[0].Children[0].Children, 1)
+
+	// Хлебные крошки — от корня до самой папки
+	w := doAuthRequest(t, r, token, "GET", "/folders/"+verbs.ID.String()+"/path", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var chain []models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, []string{"Languages", "Spanish", "Verbs"}, folderNames(chain))
+	assert.Equal(t, verbs.ID, chain[2].ID)
+
+	// После переноса путь меняется
+	french := createFolder(t, r, token, "French")
+	w = moveFolder(t, r, token, result.Created[0].ID.String(), map[string]interface{}{"parent_id": french.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "GET", "/folders/"+verbs.ID.String()+"/path", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, []string{"French", "Spanish", "Verbs"}, folderNames(chain))
+
+	byPath := func(token, path string) *httptest.ResponseRecorder {
+		return doAuthRequest(t, r, token, "GET", "/folders/by-path?path="+url.QueryEscape(path), nil)
+	}
+	w = byPath(token, "French/Spanish/Verbs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var found models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	assert.Equal(t, verbs.ID, found.ID)
+
+	assert.Equal(t, http.StatusNotFound, byPath(token, "Languages/Spanish/Verbs").Code)
+	assert.Equal(t, http.StatusNotFound, byPath(token, "French/spanish").Code)
+	assert.Equal(t, http.StatusNotFound, byPath(otherToken, "French").Code)
+	assert.Equal(t, http.StatusBadRequest, byPath(token, " / ").Code)
+
+	w = doAuthRequest(t, r, otherToken, "GET", "/folders/"+verbs.ID.String()+"/path", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/folders/ensure-path", map[string]interface{}{"path": "//"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Имя с "/" по пути не найти — такие папки не создаются и не переименовываются
+	w = doAuthRequest(t, r, token, "POST", "/folders", map[string]interface{}{"name": "Spanish/Verbs"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+verbs.ID.String(), map[string]interface{}{"name": "a/b", "version": verbs.Version})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
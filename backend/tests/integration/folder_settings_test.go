@@ -0,0 +1,213 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func TestFolderSettings_Inheritance(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "foldersettings@example.com", "settingspass", "FolderSettings")
+	otherToken := registerAndLogin(t, r, "foldersettings2@example.com", "settingspass", "FolderSettings2")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+
+	// Без настроек все поля null
+	w := doAuthRequest(t, r, token, "GET", "/folders/"+cells.ID.String()+"/settings", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"new_per_day":null,"reviews_per_day":null,"learning_steps":null,"level_step":null,
+		"interval_days":null,"target_retention":null,"algorithm":null,"max_interval_days":null}`, w.Body.String())
+
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+biology.ID.String()+"/settings", map[string]interface{}{
+		"new_per_day": 2, "learning_steps": []int{10}, "target_retention": 0.95,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String()+"/settings", map[string]interface{}{
+		"algorithm": "exponential", "max_interval_days": 2,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doAuthRequest(t, r, token, "GET", "/folders/"+cells.ID.String()+"/settings", nil)
+	var own models.FolderSettings
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &own))
+	assert.Nil(t, own.NewPerDay)
+	require.NotNil(t, own.MaxIntervalDays)
+	assert.Equal(t, 2, *own.MaxIntervalDays)
+
+	// Действующие настройки: своё, затем предки, затем по умолчанию
+	w = doAuthRequest(t, r, token, "GET", "/folders/"+cells.ID.String()+"/settings/effective", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var effective dto.FolderEffectiveSettings
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &effective))
+	assert.Equal(t, cells.ID, effective.FolderID)
+	assert.Equal(t, 2, effective.Settings.NewPerDay)
+	assert.Equal(t, 200, effective.Settings.ReviewsPerDay)
+	assert.Equal(t, []int{10}, effective.Settings.LearningSteps)
+	assert.Equal(t, 0.95, effective.Settings.TargetRetention)
+	assert.Equal(t, "exponential", effective.Settings.Algorithm)
+	assert.Equal(t, 2, effective.Settings.MaxIntervalDays)
+	assert.Equal(t, biology.ID, effective.Sources["new_per_day"])
+	assert.Equal(t, cells.ID, effective.Sources["algorithm"])
+	assert.NotContains(t, effective.Sources, "reviews_per_day")
+
+	// Неверные значения и устаревшая версия
+	for _, body := range []map[string]interface{}{
+		{"target_retention": 1.5},
+		{"interval_days": []int{1, 2}},
+		{"algorithm": "fsrs"},
+		{"learning_steps": []int{0}},
+		{"new_per_day": -1},
+	} {
+		w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String()+"/settings", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	w = doIfMatchRequest(t, r, token, "/folders/"+cells.ID.String()+"/settings", `"1"`, map[string]interface{}{})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = doAuthRequest(t, r, otherToken, "GET", "/folders/"+cells.ID.String()+"/settings/effective", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Копия в корень забирает собственные настройки папки, но уже не наследует от Biology
+	copied := copyFolder(t, r, token, "/folders/"+cells.ID.String()+"/copy")
+	w = doAuthRequest(t, r, token, "GET", "/folders/"+copied.Folder.ID.String()+"/settings/effective", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &effective))
+	assert.Equal(t, "exponential", effective.Settings.Algorithm)
+	assert.Equal(t, 20, effective.Settings.NewPerDay)
+}
+
+func TestFolderSettings_Scheduling(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "folderschedule@example.com", "settingspass", "FolderSchedule")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	w := doAuthRequest(t, r, token, "PUT", "/folders/"+biology.ID.String()+"/settings", map[string]interface{}{
+		"new_per_day": 2, "learning_steps": []int{10}, "interval_days": []int{4, 6, 8, 10, 30},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String()+"/settings", map[string]interface{}{"max_interval_days": 5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	ids := make([]string, 3)
+	for i, title := range []string{"Cell", "Membrane", "Nucleus"} {
+		ids[i] = createNoteWithContent(t, r, token, title, title)
+	}
+	runBulk(t, r, token, map[string]interface{}{"ids": ids, "action": dto.BulkMove, "folder_id": biology.ID.String()})
+	inCells := createNoteWithContent(t, r, token, "Ribosome", "r")
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{inCells}, "action": dto.BulkMove, "folder_id": cells.ID.String()})
+	unfiled := createNoteWithContent(t, r, token, "Loose", "l")
+
+	// Лимит новых карточек папки
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{"folder_id": biology.ID.String(), "limit": 100})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var session dto.ReviewSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(t, 2, session.Total)
+
+	review := func(id string, remembered bool) models.Note {
+		w := doAuthRequest(t, r, token, "POST", "/notes/"+id+"/review", map[string]interface{}{"remembered": remembered})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return getNote(t, r, token, id)
+	}
+
+	// Интервалы наследуются от Biology, предел — от Cells: 6 дней урезаются до 5
+	note := review(inCells, true)
+	assert.Equal(t, 20, note.MemoryLevel)
+	require.NotNil(t, note.NextReviewAt)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 5), *note.NextReviewAt, time.Minute)
+
+	// Забытая карточка возвращается через шаг обучения, а не снимается с расписания
+	note = review(ids[0], false)
+	assert.Equal(t, 0, note.MemoryLevel)
+	require.NotNil(t, note.NextReviewAt)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *note.NextReviewAt, time.Minute)
+	note = review(ids[0], true)
+	assert.Equal(t, 20, note.MemoryLevel)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 6), *note.NextReviewAt, time.Minute)
+
+	// Без папки — настройки по умолчанию
+	note = review(unfiled, false)
+	assert.Nil(t, note.NextReviewAt)
+	note = review(unfiled, true)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 3), *note.NextReviewAt, time.Minute)
+
+	// Первые ответы на Cell и на Ribosome (в подпапке Cells) уже израсходовали оба новых Biology
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{"folder_id": biology.ID.String(), "limit": 100})
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	newCards := 0
+	for _, n := range session.Notes {
+		if n.NextReviewAt == "" {
+			newCards++
+		}
+	}
+	assert.Equal(t, 0, newCards)
+}
+
+func TestFolderSettings_DailyLimitsRefill(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "folderlimits@example.com", "settingspass", "FolderLimits")
+
+	deck := createFolder(t, r, token, "Deck")
+	sub := createSubfolder(t, r, token, "Sub", deck.ID.String())
+	w := doAuthRequest(t, r, token, "PUT", "/folders/"+deck.ID.String()+"/settings", map[string]interface{}{
+		"new_per_day": 3, "reviews_per_day": 2,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	file := func(folderID string, count int, due bool) {
+		ids := make([]string, count)
+		for i := range ids {
+			ids[i] = createNoteWithContent(t, r, token, "Card", "c")
+		}
+		runBulk(t, r, token, map[string]interface{}{"ids": ids, "action": dto.BulkMove, "folder_id": folderID})
+		if !due {
+			return
+		}
+		for _, id := range ids {
+			note := getNote(t, r, token, id)
+			w := doPatchRequest(t, r, token, id, "application/merge-patch+json", fmt.Sprintf(`"%d"`, note.Version),
+				map[string]interface{}{"next_review_at": time.Now().Add(-time.Hour).Format(time.RFC3339)})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+	file(deck.ID.String(), 4, true)
+	file(deck.ID.String(), 2, false)
+	file(sub.ID.String(), 2, false)
+
+	session := func(limit int) (due, fresh int) {
+		w := doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{
+			"folder_id": deck.ID.String(), "include_subfolders": true, "limit": limit,
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result dto.ReviewSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		for _, n := range result.Notes {
+			if n.NextReviewAt == "" {
+				fresh++
+			} else {
+				due++
+			}
+		}
+		return due, fresh
+	}
+
+	// Просроченных больше лимита повторений — освободившиеся места занимают новые карточки
+	due, fresh := session(4)
+	assert.Equal(t, 2, due)
+	assert.Equal(t, 2, fresh)
+
+	// Лимит новых считается по всему поддереву Deck: из четырёх новых (две в Sub) — только три
+	due, fresh = session(100)
+	assert.Equal(t, 2, due)
+	assert.Equal(t, 3, fresh)
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupFolderSubtreeTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	tagRepo := repository.NewTagRepository(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	reviewSessionController := controller.NewReviewSessionController(service.NewReviewSessionService(noteRepo, repository.NewSavedSearchRepository(db), repository.NewNoteTypeRepository(db), folderRepo, repository.NewReviewLogRepository(db)))
+	bulkController := controller.NewNoteBulkController(service.NewNoteBulkService(repository.NewNoteBulkRepository(db), noteService, folderRepo, tagRepo))
+	linkService := service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo)
+	copyController := controller.NewCopyController(service.NewCopyService(repository.NewCopyRepository(db), noteRepo, folderRepo, linkService))
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, ReviewSession: reviewSessionController, NoteBulk: bulkController, Copy: copyController})
+
+	return r
+}
+
+// subtreeTitles возвращает отсортированные заголовки заметок папки вместе с подпапками
+func subtreeTitles(t *testing.T, r *gin.Engine, token, folderID string) []string {
+	w := doAuthRequest(t, r, token, "GET", "/notes?limit=100&include_subfolders=true&folder_id="+folderID, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	titles := make([]string, len(page.Notes))
+	for i, note := range page.Notes {
+		titles[i] = note.Title
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+func TestFolderSubtree_IncludeSubfolders(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "subtree@example.com", "subtreepass", "Subtree")
+	otherToken := registerAndLogin(t, r, "subtree2@example.com", "subtreepass", "Subtree2")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	organelles := createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	chemistry := createFolder(t, r, token, "Chemistry")
+
+	file := func(folderID string, titles ...string) {
+		ids := make([]string, len(titles))
+		for i, title := range titles {
+			ids[i] = createNoteWithContent(t, r, token, title, title)
+		}
+		runBulk(t, r, token, map[string]interface{}{"ids": ids, "action": dto.BulkMove, "folder_id": folderID})
+	}
+	file(biology.ID.String(), "Life")
+	file(cells.ID.String(), "Membrane")
+	file(organelles.ID.String(), "Mitochondria", "Ribosome")
+	file(chemistry.ID.String(), "Atom")
+	createNoteWithContent(t, r, token, "Unfiled", "x")
+
+	assert.Equal(t, []string{"Life", "Membrane", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, biology.ID.String()))
+	assert.Equal(t, []string{"Membrane", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, cells.ID.String()))
+	// Без флага — только сама папка
+	assert.Len(t, folderNotes(t, r, token, biology.ID.String()), 1)
+	// Чужая папка ничего не отдаёт
+	assert.Empty(t, subtreeTitles(t, r, otherToken, biology.ID.String()))
+
+	review := func(body map[string]interface{}) []string {
+		w := doAuthRequest(t, r, token, "POST", "/review/sessions", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var session dto.ReviewSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+		titles := make([]string, len(session.Notes))
+		for i, note := range session.Notes {
+			titles[i] = note.Title
+		}
+		sort.Strings(titles)
+		return titles
+	}
+	assert.Equal(t, []string{"Membrane", "Mitochondria", "Ribosome"},
+		review(map[string]interface{}{"folder_id": cells.ID.String(), "include_subfolders": true, "limit": 100}))
+	assert.Equal(t, []string{"Membrane"}, review(map[string]interface{}{"folder_id": cells.ID.String(), "limit": 100}))
+
+	// После переноса пути поддерева пересчитываются
+	w := moveFolder(t, r, token, cells.ID.String(), map[string]interface{}{"parent_id": chemistry.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Life"}, subtreeTitles(t, r, token, biology.ID.String()))
+	assert.Equal(t, []string{"Atom", "Membrane", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, chemistry.ID.String()))
+
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+organelles.ID.String(), map[string]interface{}{"parent_id": biology.ID.String(), "version": 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"Life", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, biology.ID.String()))
+	assert.Equal(t, []string{"Atom", "Membrane"}, subtreeTitles(t, r, token, chemistry.ID.String()))
+
+	// Копия поддерева получает собственные пути
+	result := copyFolder(t, r, token, "/folders/"+biology.ID.String()+"/copy?target="+chemistry.ID.String())
+	assert.Equal(t, []string{"Life", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, result.Folder.ID.String()))
+	assert.Equal(t, []string{"Atom", "Life", "Membrane", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, chemistry.ID.String()))
+	assert.Equal(t, []string{"Life", "Mitochondria", "Ribosome"}, subtreeTitles(t, r, token, biology.ID.String()))
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+)
+
+func TestFolderTree_Counts(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "treecounts@example.com", "countpass", "TreeCounts")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	createSubfolder(t, r, token, "Organelles", cells.ID.String())
+	createFolder(t, r, token, "Empty")
+
+	schedule := func(noteID string, level int, next time.Time) {
+		w := doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{
+			"memory_level": level, "next_review_at": next.UTC().Format(time.RFC3339),
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	due := createNoteWithContent(t, r, token, "Due", "1")
+	schedule(due, 40, time.Now().Add(-time.Hour))
+	fresh := createNoteWithContent(t, r, token, "New", "2")
+	later := createNoteWithContent(t, r, token, "Later", "3")
+	schedule(later, 80, time.Now().Add(48*time.Hour))
+	archived := createNoteWithContent(t, r, token, "Archived", "4")
+	createNoteWithContent(t, r, token, "Unfiled", "5")
+
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{due, fresh}, "action": dto.BulkMove, "folder_id": biology.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{later, archived}, "action": dto.BulkMove, "folder_id": cells.ID.String()})
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{archived}, "action": dto.BulkArchive})
+
+	tree := folderTree(t, r, token)
+	require.Equal(t, []string{"Biology", "Empty"}, nodeNames(tree))
+
+	assert.Equal(t, dto.FolderCounts{Total: 2, Due: 1, New: 1, AverageMemoryLevel: 20}, tree[0].Counts)
+	assert.Equal(t, dto.FolderCounts{Total: 3, Due: 1, New: 1, AverageMemoryLevel: 40}, tree[0].SubtreeCounts)
+
+	cellsNode := tree[0].Children[0]
+	assert.Equal(t, dto.FolderCounts{Total: 1, AverageMemoryLevel: 80}, cellsNode.Counts)
+	assert.Equal(t, cellsNode.Counts, cellsNode.SubtreeCounts)
+	assert.Equal(t, dto.FolderCounts{}, cellsNode.Children[0].SubtreeCounts)
+	assert.Equal(t, dto.FolderCounts{}, tree[1].Counts)
+}
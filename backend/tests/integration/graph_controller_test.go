@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupGraphTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+	tagRepo := repository.NewTagRepository(db)
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	noteTagController := controller.NewNoteTagController(service.NewNoteTagService(noteRepo, tagRepo))
+
+	prerequisiteRepo := repository.NewPrerequisiteRepository(db)
+	prerequisiteController := controller.NewPrerequisiteController(service.NewPrerequisiteService(prerequisiteRepo, noteRepo, folderRepo))
+
+	graphService := service.NewGraphService(noteRepo, folderRepo, tagRepo, prerequisiteRepo, repository.NewNoteLinkRepository(db))
+	graphController := controller.NewGraphController(graphService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, Prerequisite: prerequisiteController, Graph: graphController})
+
+	return r
+}
+
+func getGraph(t *testing.T, r *gin.Engine, token, query string) dto.Graph {
+	w := doAuthRequest(t, r, token, "GET", "/graph"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var graph dto.Graph
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &graph))
+	return graph
+}
+
+func countEdges(graph dto.Graph, edgeType string) int {
+	count := 0
+	for _, e := range graph.Edges {
+		if e.Type == edgeType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGraph_ExportAndFilters(t *testing.T) {
+	r := setupGraphTestRouter(t)
+	token := registerAndLogin(t, r, "graph@example.com", "graphpass", "Graph")
+
+	chem := createFolder(t, r, token, "Chem")
+	w := doAuthRequest(t, r, token, "POST", "/folders", map[string]string{"name": "Organic", "parent_id": chem.ID.String()})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var organic models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &organic))
+	bio := createFolder(t, r, token, "Bio")
+
+	chemistry := createTag(t, r, token, "chemistry")
+	exam := createTag(t, r, token, "exam")
+
+	alkanes := createNoteWithFolderAndTags(t, r, token, "Alkanes", organic.ID.String(), []string{chemistry.ID.String()})
+	acidsID := createNoteWithContent(t, r, token, `Acids "strong"`, "See [[Alkanes]]")
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+acidsID+"/folders", map[string]string{"folder_id": chem.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code)
+	for _, tagID := range []string{chemistry.ID.String(), exam.ID.String()} {
+		w = doAuthRequest(t, r, token, "POST", "/notes/"+acidsID+"/tags/"+tagID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	createNoteWithFolderAndTags(t, r, token, "Cells", bio.ID.String(), []string{exam.ID.String()})
+
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+acidsID+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": alkanes.ID.String()})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Весь граф
+	graph := getGraph(t, r, token, "")
+	assert.Len(t, graph.Nodes, 8)
+	assert.Equal(t, 1, countEdges(graph, dto.GraphEdgeChildOf))
+	assert.Equal(t, 3, countEdges(graph, dto.GraphEdgeInFolder))
+	assert.Equal(t, 4, countEdges(graph, dto.GraphEdgeTagged))
+	assert.Contains(t, graph.Edges, dto.GraphEdge{Source: acidsID, Target: alkanes.ID.String(), Type: dto.GraphEdgeLinksTo})
+	assert.Contains(t, graph.Edges, dto.GraphEdge{Source: alkanes.ID.String(), Target: acidsID, Type: dto.GraphEdgePrerequisite})
+
+	// Поддерево папки Chem: Organic входит, Bio и Cells — нет
+	graph = getGraph(t, r, token, "?folder_id="+chem.ID.String())
+	assert.Len(t, graph.Nodes, 6)
+	assert.Len(t, graph.Edges, 8)
+
+	// Тег exam: Acids и Cells, их папки и теги; ссылка на Alkanes отпадает
+	graph = getGraph(t, r, token, "?tag_ids[]="+exam.ID.String())
+	assert.Len(t, graph.Nodes, 6)
+	assert.Equal(t, 2, countEdges(graph, dto.GraphEdgeInFolder))
+	assert.Equal(t, 3, countEdges(graph, dto.GraphEdgeTagged))
+	assert.Equal(t, 0, countEdges(graph, dto.GraphEdgeLinksTo))
+
+	// GraphML
+	w = doAuthRequest(t, r, token, "GET", "/graph?format=graphml", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/graphml+xml")
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Len(t, doc.Graph.Nodes, 8)
+	assert.Len(t, doc.Graph.Edges, 10)
+
+	// DOT: кавычки в заголовках экранируются
+	w = doAuthRequest(t, r, token, "GET", "/graph?format=dot", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/vnd.graphviz")
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "digraph knowledge {"))
+	assert.Contains(t, body, `label="Acids \"strong\""`)
+	assert.Contains(t, body, `"`+acidsID+`" -> "`+alkanes.ID.String()+`" [type="links_to"`)
+
+	w = doAuthRequest(t, r, token, "GET", "/graph?format=svg", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "GET", "/graph?folder_id=00000000-0000-0000-0000-000000000000", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func runBulk(t *testing.T, r *gin.Engine, token string, body map[string]interface{}) dto.NoteBulkResult {
+	w := doAuthRequest(t, r, token, "POST", "/notes/bulk", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result dto.NoteBulkResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+// noteTagIDs возвращает теги заметки из списка заметок (GET /notes/:id тегов не отдаёт)
+func noteTagIDs(t *testing.T, r *gin.Engine, token, noteID string) []string {
+	w := doAuthRequest(t, r, token, "GET", "/notes?limit=100", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	for _, note := range page.Notes {
+		if note.ID.String() == noteID {
+			return tagIDsOf(note)
+		}
+	}
+	t.Fatalf("note %s not found", noteID)
+	return nil
+}
+
+func getNote(t *testing.T, r *gin.Engine, token, noteID string) models.Note {
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+noteID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var note models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	return note
+}
+
+func TestNoteBulk_ActionsByIDs(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "bulk@example.com", "bulkpass", "Bulk")
+	otherToken := registerAndLogin(t, r, "bulk2@example.com", "bulkpass", "Bulk2")
+
+	first := createNoteWithContent(t, r, token, "First", "one")
+	second := createNoteWithContent(t, r, token, "Second", "two")
+	foreign := createNoteWithContent(t, r, otherToken, "Foreign", "three")
+	folder := createFolder(t, r, token, "Biology")
+	grammar := createTag(t, r, token, "grammar")
+	verbs := createTag(t, r, token, "verbs")
+
+	// Чужая и несуществующая заметки пропускаются
+	result := runBulk(t, r, token, map[string]interface{}{
+		"ids":    []string{first, second, foreign, "not-a-uuid"},
+		"action": dto.BulkArchive,
+	})
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 2, result.NotFound)
+	require.Len(t, result.Items, 4)
+	assert.Equal(t, dto.BulkItemUpdated, result.Items[0].Status)
+	assert.Equal(t, dto.BulkItemNotFound, result.Items[2].Status)
+	assert.True(t, getNote(t, r, token, first).Archived)
+	assert.False(t, getNote(t, r, otherToken, foreign).Archived)
+	assert.Equal(t, 2, getNote(t, r, token, first).Version)
+
+	// Повторное действие ничего не меняет
+	result = runBulk(t, r, token, map[string]interface{}{"ids": []string{first}, "action": dto.BulkArchive})
+	assert.Equal(t, dto.BulkItemUnchanged, result.Items[0].Status)
+	assert.Equal(t, 2, getNote(t, r, token, first).Version)
+
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{first, second}, "action": dto.BulkUnarchive})
+	assert.False(t, getNote(t, r, token, second).Archived)
+
+	// Перемещение в папку и обратно
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{first, second}, "action": dto.BulkMove, "folder_id": folder.ID.String()})
+	note := getNote(t, r, token, second)
+	require.NotNil(t, note.FolderID)
+	assert.Equal(t, folder.ID, *note.FolderID)
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{second}, "action": dto.BulkMove, "folder_id": nil})
+	assert.Nil(t, getNote(t, r, token, second).FolderID)
+
+	// Теги: добавить, убрать, заменить
+	result = runBulk(t, r, token, map[string]interface{}{
+		"ids": []string{first, second}, "action": dto.BulkAddTags, "tag_ids": []string{grammar.ID.String(), verbs.ID.String()},
+	})
+	assert.Equal(t, 2, result.Updated)
+	assert.Len(t, noteTagIDs(t, r, token, first), 2)
+
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{first}, "action": dto.BulkRemoveTags, "tag_ids": []string{grammar.ID.String()}})
+	assert.Equal(t, []string{verbs.ID.String()}, noteTagIDs(t, r, token, first))
+
+	result = runBulk(t, r, token, map[string]interface{}{
+		"ids": []string{first, second}, "action": dto.BulkReplaceTags, "tag_ids": []string{grammar.ID.String()},
+	})
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, []string{grammar.ID.String()}, noteTagIDs(t, r, token, first))
+	assert.Equal(t, []string{grammar.ID.String()}, noteTagIDs(t, r, token, second))
+
+	// Сброс расписания
+	w := doPatchRequest(t, r, token, first, "application/merge-patch+json", "", map[string]interface{}{
+		"memory_level": 70, "next_review_at": "2030-01-01T00:00:00Z", "version": noteVersion(t, r, token, first),
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	result = runBulk(t, r, token, map[string]interface{}{"ids": []string{first, second}, "action": dto.BulkResetScheduling})
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Unchanged)
+	note = getNote(t, r, token, first)
+	assert.Equal(t, 0, note.MemoryLevel)
+	assert.Nil(t, note.NextReviewAt)
+
+	// Удаление — в корзину
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{second}, "action": dto.BulkDelete})
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+second, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNoteBulk_FilterDryRunAndValidation(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "bulkfilter@example.com", "bulkpass", "BulkFilter")
+	otherToken := registerAndLogin(t, r, "bulkfilter2@example.com", "bulkpass", "BulkFilter2")
+
+	tag := createTag(t, r, token, "biology")
+	foreignTag := createTag(t, r, otherToken, "foreign")
+	cell := createNoteWithContent(t, r, token, "Cell", "membrane")
+	dna := createNoteWithContent(t, r, token, "DNA", "helix")
+	other := createNoteWithContent(t, r, token, "Verbs", "ser")
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{cell, dna}, "action": dto.BulkAddTags, "tag_ids": []string{tag.ID.String()}})
+
+	// dry_run показывает результат, но ничего не меняет
+	result := runBulk(t, r, token, map[string]interface{}{
+		"filter":  map[string]interface{}{"tag_ids": []string{tag.ID.String()}},
+		"action":  dto.BulkArchive,
+		"dry_run": true,
+	})
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Updated)
+	assert.False(t, getNote(t, r, token, cell).Archived)
+
+	// Фильтр на языке запросов
+	result = runBulk(t, r, token, map[string]interface{}{
+		"filter": map[string]interface{}{"q": "tag:biology"},
+		"action": dto.BulkArchive,
+	})
+	assert.Equal(t, 2, result.Matched)
+	ids := []string{result.Items[0].ID, result.Items[1].ID}
+	assert.ElementsMatch(t, []string{cell, dna}, ids)
+	assert.True(t, getNote(t, r, token, dna).Archived)
+	assert.False(t, getNote(t, r, token, other).Archived)
+
+	// Пустой фильтр выбирает все заметки
+	result = runBulk(t, r, token, map[string]interface{}{"filter": map[string]interface{}{}, "action": dto.BulkUnarchive})
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, 2, result.Updated)
+
+	cases := []map[string]interface{}{
+		{"action": dto.BulkArchive},
+		{"ids": []string{cell}, "filter": map[string]interface{}{}, "action": dto.BulkArchive},
+		{"ids": []string{cell}, "action": "explode"},
+		{"ids": []string{cell}, "action": dto.BulkAddTags},
+		{"ids": []string{cell}, "action": dto.BulkAddTags, "tag_ids": []string{foreignTag.ID.String()}},
+		{"ids": []string{cell}, "action": dto.BulkMove, "folder_id": "550e8400-e29b-41d4-a716-446655440000"},
+		{"filter": map[string]interface{}{"q": "(tag:biology"}, "action": dto.BulkArchive},
+	}
+	for _, body := range cases {
+		w := doAuthRequest(t, r, token, "POST", "/notes/bulk", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Empty(t, noteTagIDs(t, r, token, other))
+}
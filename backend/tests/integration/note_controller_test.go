@@ -2,7 +2,9 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -14,11 +16,14 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"valibibe/internal/controller"
 	"valibibe/internal/controller/dto"
+	"valibibe/internal/dedup"
 	"valibibe/internal/models"
 	"valibibe/internal/repository"
+	"valibibe/internal/repository/interfaces"
 	"valibibe/internal/router"
 	"valibibe/internal/service"
 )
@@ -35,7 +40,7 @@ func setupNoteControllerTestRouter(t *testing.T) *gin.Engine {
 	authController := controller.NewAuthController(authService)
 
 	noteRepo := repository.NewNoteRepository(db)
-	noteService := service.NewNoteService(noteRepo)
+	noteService := newNoteService(db, noteRepo)
 	folderRepo := repository.NewFolderRepo(db)
 	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
 	noteController := controller.NewNoteController(noteService, assignFolderService)
@@ -49,7 +54,7 @@ func setupNoteControllerTestRouter(t *testing.T) *gin.Engine {
 	noteTagController := controller.NewNoteTagController(noteTagService)
 
 	r := gin.Default()
-	router.SetupRoutes(r, tokenService, authController, noteController, folderController, tagController, noteTagController, nil)
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController})
 
 	return r
 }
@@ -132,9 +137,10 @@ func TestNoteCRUDFlow(t *testing.T) {
 	assert.Equal(t, createdNote.Title, gotNote.Title)
 
 	// --- Update Note ---
-	updateBody := map[string]string{
+	updateBody := map[string]interface{}{
 		"title":   "Updated Note Title",
 		"content": "Updated note content",
+		"version": createdNote.Version,
 	}
 	updateJSON, _ := json.Marshal(updateBody)
 	reqUpdate, _ := http.NewRequest("PUT", "/notes/"+createdNote.ID.String(), bytes.NewBuffer(updateJSON))
@@ -330,6 +336,82 @@ func TestReviewNoteHandler(t *testing.T) {
 	assert.Nil(t, noteAfter["nextReviewAt"])
 }
 
+// staleNoteRepo отдаёт заметку, прочитанную до параллельной правки: перед возвратом снимка
+// один раз вызывается race
+type staleNoteRepo struct {
+	interfaces.NoteRepository
+	race func()
+}
+
+func (r *staleNoteRepo) GetNoteByIDAndUserID(ctx context.Context, noteID, userID string) (*models.Note, error) {
+	note, err := r.NoteRepository.GetNoteByIDAndUserID(ctx, noteID, userID)
+	if r.race != nil {
+		race := r.race
+		r.race = nil
+		race()
+	}
+	return note, err
+}
+
+func TestReviewNote_ConcurrentEdit(t *testing.T) {
+	db := SetupTestDB(t)
+	user := models.User{ID: uuid.New(), Nickname: "Racer", Email: "racer@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	note := models.Note{UserID: user.ID, Title: "Original", Content: "Text"}
+	require.NoError(t, db.Create(&note).Error)
+
+	// Пока повторение считает расписание, заметку правят через PUT
+	repo := &staleNoteRepo{NoteRepository: repository.NewNoteRepository(db)}
+	repo.race = func() {
+		require.NoError(t, db.Model(&models.Note{}).Where("id = ?", note.ID).
+			Updates(map[string]interface{}{"title": "Edited", "version": gorm.Expr("version + 1")}).Error)
+	}
+	noteService := newNoteService(db, repo)
+
+	// Повторение не конфликтует с правкой и не затирает её
+	require.NoError(t, noteService.UpdateMemoryLevel(context.Background(), user.ID.String(), note.ID.String(), true))
+
+	var stored models.Note
+	require.NoError(t, db.First(&stored, "id = ?", note.ID).Error)
+	assert.Equal(t, "Edited", stored.Title)
+	assert.Equal(t, 20, stored.MemoryLevel)
+	assert.NotNil(t, stored.NextReviewAt)
+	assert.Equal(t, 3, stored.Version)
+}
+
+// failingReviewLogRepo не может сохранить запись журнала повторений
+type failingReviewLogRepo struct {
+	interfaces.ReviewLogRepository
+}
+
+func (r *failingReviewLogRepo) Create(ctx context.Context, log *models.ReviewLog) error {
+	return errors.New("review log unavailable")
+}
+
+func TestReviewNote_RollsBackWithoutReviewLog(t *testing.T) {
+	db := SetupTestDB(t)
+	user := models.User{ID: uuid.New(), Nickname: "Logger", Email: "logger@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	note := models.Note{UserID: user.ID, Title: "Logged", Content: "Text"}
+	require.NoError(t, db.Create(&note).Error)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := service.NewNoteService(noteRepo, &failingReviewLogRepo{ReviewLogRepository: repository.NewReviewLogRepository(db)},
+		service.NewNoteRevisionService(repository.NewNoteRevisionRepository(db), noteRepo, service.DefaultRevisionLimit),
+		service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo), service.NewNoteTypeService(repository.NewNoteTypeRepository(db)),
+		service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold),
+		repository.NewFolderRepo(db), repository.NewTagRepository(db))
+
+	// Без записи в журнале расписание заметки не меняется
+	require.Error(t, noteService.UpdateMemoryLevel(context.Background(), user.ID.String(), note.ID.String(), true))
+
+	var stored models.Note
+	require.NoError(t, db.First(&stored, "id = ?", note.ID).Error)
+	assert.Equal(t, 0, stored.MemoryLevel)
+	assert.Nil(t, stored.NextReviewAt)
+	assert.Equal(t, note.Version, stored.Version)
+}
+
 func TestNotesFilterByFolderAndTags(t *testing.T) {
 	r := setupNoteControllerTestRouter(t)
 
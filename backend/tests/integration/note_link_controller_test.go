@@ -0,0 +1,163 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/dedup"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupNoteLinkTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	noteLinkController := controller.NewNoteLinkController(service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo))
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, NoteLink: noteLinkController})
+
+	return r
+}
+
+func getNoteLinks(t *testing.T, r *gin.Engine, token, noteID string) []dto.NoteLinkInfo {
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+noteID+"/links", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var links []dto.NoteLinkInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	return links
+}
+
+func getDanglingLinks(t *testing.T, r *gin.Engine, token string) []dto.DanglingLink {
+	w := doAuthRequest(t, r, token, "GET", "/notes/links/dangling", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var links []dto.DanglingLink
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	return links
+}
+
+func TestNoteLinks_LinksBacklinksAndRename(t *testing.T) {
+	r := setupNoteLinkTestRouter(t)
+	token := registerAndLogin(t, r, "links@example.com", "linkspass", "Links")
+
+	verbs := createNoteWithContent(t, r, token, "Spanish verbs", "ser и estar")
+	grammar := createNoteWithContent(t, r, token, "Грамматика",
+		"См. [[spanish verbs]], [[Tenses]] и [["+verbs+"|эту заметку]]. Повтор: [[Spanish Verbs]]")
+
+	links := getNoteLinks(t, r, token, grammar)
+	require.Len(t, links, 3)
+	assert.Equal(t, "spanish verbs", links[0].Text)
+	assert.Equal(t, verbs, links[0].NoteID)
+	assert.Equal(t, "Spanish verbs", links[0].Title)
+	assert.True(t, links[1].Dangling)
+	assert.Equal(t, "Tenses", links[1].Text)
+	assert.Equal(t, verbs, links[2].NoteID)
+
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+verbs+"/backlinks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var backlinks []dto.Backlink
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &backlinks))
+	require.Len(t, backlinks, 1)
+	assert.Equal(t, grammar, backlinks[0].NoteID)
+	assert.Equal(t, "Грамматика", backlinks[0].Title)
+
+	// Висячая ссылка находит заметку, как только та появляется
+	dangling := getDanglingLinks(t, r, token)
+	require.Len(t, dangling, 1)
+	assert.Equal(t, grammar, dangling[0].SourceID)
+	assert.Equal(t, "Tenses", dangling[0].Text)
+
+	tenses := createNoteWithContent(t, r, token, "tenses", "past, present")
+	assert.Empty(t, getDanglingLinks(t, r, token))
+	assert.Equal(t, tenses, getNoteLinks(t, r, token, grammar)[1].NoteID)
+
+	// Переименование переписывает ссылки по заголовку, ссылки по id остаются как есть
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+verbs, map[string]interface{}{
+		"title": "Глаголы", "content": "ser и estar", "version": noteVersion(t, r, token, verbs),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+grammar, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var note models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	assert.Equal(t, "См. [[Глаголы]], [[Tenses]] и [["+verbs+"|эту заметку]]. Повтор: [[Глаголы]]", note.Content)
+
+	links = getNoteLinks(t, r, token, grammar)
+	require.Len(t, links, 3)
+	assert.Equal(t, "Глаголы", links[0].Text)
+	assert.Equal(t, verbs, links[0].NoteID)
+	assert.Equal(t, verbs, links[2].NoteID)
+
+	// Ссылки на удалённую заметку становятся висячими
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+verbs, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, getDanglingLinks(t, r, token), 2)
+
+	// Чужие заметки недоступны
+	otherToken := registerAndLogin(t, r, "links2@example.com", "linkspass", "Links2")
+	w = doAuthRequest(t, r, otherToken, "GET", "/notes/"+grammar+"/links", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, getDanglingLinks(t, r, otherToken))
+}
+
+// failingResolveLinkRepo сохраняет ссылки, но падает на привязке висячих
+type failingResolveLinkRepo struct {
+	interfaces.NoteLinkRepository
+}
+
+func (r *failingResolveLinkRepo) ResolveDangling(ctx context.Context, userID uuid.UUID, title string, noteID uuid.UUID) error {
+	return errors.New("link storage unavailable")
+}
+
+func TestNoteLinks_CreateRollsBackOnSyncError(t *testing.T) {
+	db := SetupTestDB(t)
+	user := models.User{ID: uuid.New(), Nickname: "Linker", Email: "linker@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+
+	noteRepo := repository.NewNoteRepository(db)
+	linkRepo := &failingResolveLinkRepo{NoteLinkRepository: repository.NewNoteLinkRepository(db)}
+	noteService := service.NewNoteService(noteRepo, repository.NewReviewLogRepository(db),
+		service.NewNoteRevisionService(repository.NewNoteRevisionRepository(db), noteRepo, service.DefaultRevisionLimit),
+		service.NewNoteLinkService(linkRepo, noteRepo), service.NewNoteTypeService(repository.NewNoteTypeRepository(db)),
+		service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold),
+		repository.NewFolderRepo(db), repository.NewTagRepository(db))
+
+	// Ссылки уже записаны, но синхронизация не завершилась — откатывается всё
+	_, err := noteService.CreateNote(context.Background(), user.ID.String(), &dto.NoteInput{Title: "Source", Content: "See [[Missing]]"})
+	require.Error(t, err)
+
+	var notes, links, revisions int64
+	require.NoError(t, db.Model(&models.Note{}).Where("user_id = ?", user.ID).Count(&notes).Error)
+	require.NoError(t, db.Model(&models.NoteLink{}).Where("user_id = ?", user.ID).Count(&links).Error)
+	require.NoError(t, db.Model(&models.NoteRevision{}).Count(&revisions).Error)
+	assert.Equal(t, int64(0), notes)
+	assert.Equal(t, int64(0), links)
+	assert.Equal(t, int64(0), revisions)
+}
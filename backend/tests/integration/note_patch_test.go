@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/models"
+)
+
+// doPatchRequest выполняет PATCH /notes/:id с заданным Content-Type и, если etag не пуст, If-Match
+func doPatchRequest(t *testing.T, r *gin.Engine, token, noteID, contentType, etag string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest("PATCH", "/notes/"+noteID, bytes.NewReader(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentType)
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodePatchedNote(t *testing.T, w *httptest.ResponseRecorder) models.Note {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var note models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	return note
+}
+
+func tagIDsOf(note models.Note) []string {
+	ids := make([]string, len(note.Tags))
+	for i, tag := range note.Tags {
+		ids[i] = tag.ID.String()
+	}
+	return ids
+}
+
+func TestNotePatch_MergePatch(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "patch@example.com", "patchpass", "Patch")
+
+	noteID := createNoteWithContent(t, r, token, "Verbs", "ser and estar")
+	folder := createFolder(t, r, token, "Spanish")
+	grammar := createTag(t, r, token, "grammar")
+	verbs := createTag(t, r, token, "verbs")
+
+	// Меняется только заголовок, текст остаётся прежним
+	w := doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{"title": "Spanish verbs"})
+	note := decodePatchedNote(t, w)
+	assert.Equal(t, "Spanish verbs", note.Title)
+	assert.Equal(t, "ser and estar", note.Content)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// Папка, теги, архив и расписание — одним запросом; версия в теле
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", "", map[string]interface{}{
+		"folder_id":      folder.ID.String(),
+		"tag_ids":        []string{grammar.ID.String(), verbs.ID.String()},
+		"archived":       true,
+		"memory_level":   60,
+		"next_review_at": "2030-01-02T10:00:00Z",
+		"version":        2,
+	})
+	note = decodePatchedNote(t, w)
+	require.NotNil(t, note.FolderID)
+	assert.Equal(t, folder.ID, *note.FolderID)
+	assert.ElementsMatch(t, []string{grammar.ID.String(), verbs.ID.String()}, tagIDsOf(note))
+	assert.True(t, note.Archived)
+	assert.Equal(t, 60, note.MemoryLevel)
+	require.NotNil(t, note.NextReviewAt)
+	assert.Equal(t, 2030, note.NextReviewAt.Year())
+	assert.Equal(t, 3, note.Version)
+
+	// null убирает папку и дату повторения; application/json — тоже Merge Patch
+	w = doPatchRequest(t, r, token, noteID, "application/json", "", map[string]interface{}{
+		"folder_id":      nil,
+		"next_review_at": nil,
+		"tag_ids":        []string{verbs.ID.String()},
+		"version":        3,
+	})
+	note = decodePatchedNote(t, w)
+	assert.Nil(t, note.FolderID)
+	assert.Nil(t, note.NextReviewAt)
+	assert.Equal(t, []string{verbs.ID.String()}, tagIDsOf(note))
+	assert.Equal(t, "Spanish verbs", note.Title)
+
+	// Изменения видны при чтении
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+noteID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.Equal(t, "Spanish verbs", stored.Title)
+	assert.True(t, stored.Archived)
+	assert.Equal(t, 4, stored.Version)
+}
+
+func TestNotePatch_JSONPatch(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "jsonpatch@example.com", "patchpass", "JSONPatch")
+
+	noteID := createNoteWithContent(t, r, token, "Verbs", "ser and estar")
+	folder := createFolder(t, r, token, "Spanish")
+	tag := createTag(t, r, token, "verbs")
+
+	ops := []map[string]interface{}{
+		{"op": "test", "path": "/version", "value": 1},
+		{"op": "replace", "path": "/title", "value": "Irregular verbs"},
+		{"op": "add", "path": "/tag_ids/-", "value": tag.ID.String()},
+		{"op": "replace", "path": "/folder_id", "value": folder.ID.String()},
+		{"op": "copy", "from": "/title", "path": "/content"},
+	}
+	note := decodePatchedNote(t, doPatchRequest(t, r, token, noteID, "application/json-patch+json", "", ops))
+	assert.Equal(t, "Irregular verbs", note.Title)
+	assert.Equal(t, "Irregular verbs", note.Content)
+	assert.Equal(t, []string{tag.ID.String()}, tagIDsOf(note))
+	require.NotNil(t, note.FolderID)
+
+	// remove убирает необязательное поле
+	ops = []map[string]interface{}{
+		{"op": "remove", "path": "/folder_id"},
+		{"op": "remove", "path": "/tag_ids/0"},
+	}
+	note = decodePatchedNote(t, doPatchRequest(t, r, token, noteID, "application/json-patch+json", `"2"`, ops))
+	assert.Nil(t, note.FolderID)
+	assert.Empty(t, note.Tags)
+
+	// Не прошла операция test — 409, заметка не меняется
+	ops = []map[string]interface{}{
+		{"op": "test", "path": "/title", "value": "Verbs"},
+		{"op": "replace", "path": "/title", "value": "Changed"},
+	}
+	w := doPatchRequest(t, r, token, noteID, "application/json-patch+json", `"3"`, ops)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	// Несуществующий путь и неизвестная операция — 400
+	w = doPatchRequest(t, r, token, noteID, "application/json-patch+json", `"3"`, []map[string]interface{}{
+		{"op": "replace", "path": "/tag_ids/5", "value": tag.ID.String()},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doPatchRequest(t, r, token, noteID, "application/json-patch+json", `"3"`, []map[string]interface{}{
+		{"op": "rename", "path": "/title"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, 3, noteVersion(t, r, token, noteID))
+}
+
+func TestNotePatch_ValidationAndVersions(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "patchvalid@example.com", "patchpass", "PatchValid")
+
+	noteID := createNoteWithContent(t, r, token, "Verbs", "ser and estar")
+	otherToken := registerAndLogin(t, r, "patchother@example.com", "patchpass", "PatchOther")
+	foreignTag := createTag(t, r, otherToken, "foreign")
+
+	// Ошибки собираются по полям
+	w := doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{
+		"title":        "",
+		"memory_level": 150,
+		"archived":     "yes",
+		"color":        "red",
+		"content":      nil,
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Fields map[string]string `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "must not be empty", resp.Fields["title"])
+	assert.Equal(t, "must be between 0 and 100", resp.Fields["memory_level"])
+	assert.Equal(t, "must be a boolean", resp.Fields["archived"])
+	assert.Equal(t, "unknown field", resp.Fields["color"])
+	assert.Equal(t, "is required", resp.Fields["content"])
+
+	// Атомарность: при чужом теге не меняется и заголовок
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{
+		"title":   "Changed",
+		"tag_ids": []string{foreignTag.ID.String()},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "tag not found", resp.Fields["tag_ids"])
+
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{
+		"folder_id": "550e8400-e29b-41d4-a716-446655440000",
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "folder not found", resp.Fields["folder_id"])
+
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+noteID, nil)
+	var stored models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.Equal(t, "Verbs", stored.Title)
+	assert.Equal(t, 1, stored.Version)
+
+	// Версия обязательна и должна быть актуальной
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", "", map[string]interface{}{"title": "Changed"})
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", "", map[string]interface{}{"title": "Changed", "version": 7})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doPatchRequest(t, r, token, noteID, "application/merge-patch+json", `"7"`, map[string]interface{}{"title": "Changed"})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Версию нельзя изменить патчем
+	w = doPatchRequest(t, r, token, noteID, "application/json-patch+json", `"1"`, []map[string]interface{}{
+		{"op": "replace", "path": "/version", "value": 10},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "is read-only", resp.Fields["version"])
+
+	w = doPatchRequest(t, r, token, noteID, "text/plain", `"1"`, map[string]interface{}{"title": "Changed"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = doPatchRequest(t, r, otherToken, noteID, "application/merge-patch+json", `"1"`, map[string]interface{}{"title": "Changed"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func queryNotes(t *testing.T, r *gin.Engine, token, q string) []uuid.UUID {
+	w := doAuthRequest(t, r, token, "GET", "/notes?limit=50&q="+url.QueryEscape(q), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	ids := make([]uuid.UUID, len(result.Notes))
+	for i, n := range result.Notes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+func TestNoteQueryLanguage(t *testing.T) {
+	r, db, _ := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "query@example.com", "querypass", "Query")
+
+	chem := createFolder(t, r, token, "Chem")
+	w := doAuthRequest(t, r, token, "POST", "/folders", map[string]string{"name": "Organic Chemistry", "parent_id": chem.ID.String()})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var organic models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &organic))
+
+	biology := createTag(t, r, token, "Biology")
+	draft := createTag(t, r, token, "draft")
+
+	cell := createNoteWithFolderAndTags(t, r, token, "Cell structure", "", []string{biology.ID.String()})
+	cellDraft := createNoteWithFolderAndTags(t, r, token, "Cell division", "", []string{biology.ID.String(), draft.ID.String()})
+	alkanes := createNoteWithFolderAndTags(t, r, token, "Alkanes", organic.ID.String(), nil)
+	acids := createNoteWithFolderAndTags(t, r, token, "Acids 100%", chem.ID.String(), nil)
+
+	// Уровень, срок повторения, архив и дата создания выставляются напрямую
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", cell.ID).
+		Updates(map[string]interface{}{"memory_level": 60, "next_review_at": past}).Error)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", cellDraft.ID).
+		Updates(map[string]interface{}{"memory_level": 20, "next_review_at": future}).Error)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", alkanes.ID).
+		Updates(map[string]interface{}{"archived": true, "created_at": time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}).Error)
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", acids.ID).
+		Update("created_at", time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)).Error)
+
+	cases := []struct {
+		q    string
+		want []uuid.UUID
+	}{
+		{"tag:biology", []uuid.UUID{cell.ID, cellDraft.ID}},
+		{"tag:biology -tag:draft", []uuid.UUID{cell.ID}},
+		{`folder:"Chem/Organic Chemistry"`, []uuid.UUID{alkanes.ID}},
+		{"folder:Chem", []uuid.UUID{acids.ID}},
+		{"folder:Missing/Path", nil},
+		{"is:due", []uuid.UUID{cell.ID}},
+		{"is:archived", []uuid.UUID{alkanes.ID}},
+		{"-is:archived level:<40", []uuid.UUID{cellDraft.ID, acids.ID}},
+		{"level:>=60", []uuid.UUID{cell.ID}},
+		{"created:>2025-01-01", []uuid.UUID{cell.ID, cellDraft.ID}},
+		{"created:2025-01-01", []uuid.UUID{acids.ID}},
+		{"created:<2025-01-01", []uuid.UUID{alkanes.ID}},
+		{`"cell division"`, []uuid.UUID{cellDraft.ID}},
+		{"cell -division", []uuid.UUID{cell.ID}},
+		{"100%", []uuid.UUID{acids.ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.q, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.want, queryNotes(t, r, token, tc.q))
+		})
+	}
+}
+
+func TestNoteQueryLanguage_SyntaxErrors(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "queryerr@example.com", "querypass", "QueryErr")
+
+	cases := []struct {
+		q        string
+		position int
+	}{
+		{`tag:biology "unclosed`, 13},
+		{"level:<abc", 8},
+		{"created:>01.01.2025", 10},
+		{"is:pending", 4},
+		{"tag:biology color:red", 13},
+		{"tag:", 5},
+		{"- tag:draft", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.q, func(t *testing.T) {
+			w := doAuthRequest(t, r, token, "GET", "/notes?q="+url.QueryEscape(tc.q), nil)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var resp struct {
+				Error    string `json:"error"`
+				Position int    `json:"position"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tc.position, resp.Position)
+			assert.Contains(t, resp.Error, "syntax error")
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/dedup"
+	"valibibe/internal/diff"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupNoteRevisionTestRouter(t *testing.T, limit int) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	folderRepo := repository.NewFolderRepo(db)
+	revisionService := service.NewNoteRevisionService(repository.NewNoteRevisionRepository(db), noteRepo, limit)
+	noteService := service.NewNoteService(noteRepo, repository.NewReviewLogRepository(db), revisionService,
+		service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo), service.NewNoteTypeService(repository.NewNoteTypeRepository(db)),
+		service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold),
+		folderRepo, repository.NewTagRepository(db))
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+	revisionController := controller.NewNoteRevisionController(revisionService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, NoteRevision: revisionController})
+
+	return r
+}
+
+func updateNoteContent(t *testing.T, r *gin.Engine, token, noteID, title, content string) {
+	w := doAuthRequest(t, r, token, "PUT", "/notes/"+noteID, map[string]interface{}{
+		"title": title, "content": content, "version": noteVersion(t, r, token, noteID),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func listRevisions(t *testing.T, r *gin.Engine, token, noteID string) []dto.NoteRevisionInfo {
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+noteID+"/revisions", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var revisions []dto.NoteRevisionInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &revisions))
+	return revisions
+}
+
+func TestNoteRevisions_HistoryDiffAndRestore(t *testing.T) {
+	r := setupNoteRevisionTestRouter(t, service.DefaultRevisionLimit)
+	token := registerAndLogin(t, r, "revisions@example.com", "revpass", "Revisions")
+
+	note := createNoteWithFolderAndTags(t, r, token, "Verbs", "", nil)
+	id := note.ID.String()
+
+	updateNoteContent(t, r, token, id, "Verbs", "ser\nestar\nir")
+	updateNoteContent(t, r, token, id, "Irregular verbs", "ser\nir\ntener")
+	// Сохранение без изменений новую ревизию не создаёт
+	updateNoteContent(t, r, token, id, "Irregular verbs", "ser\nir\ntener")
+
+	revisions := listRevisions(t, r, token, id)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, 3, revisions[0].Revision)
+	assert.True(t, revisions[0].Current)
+	assert.Equal(t, "Irregular verbs", revisions[0].Title)
+	assert.Equal(t, 1, revisions[2].Revision)
+
+	// Ревизия целиком
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+id+"/revisions/2", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var rev models.NoteRevision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rev))
+	assert.Equal(t, "ser\nestar\nir", rev.Content)
+
+	// Дифф 2 -> последняя
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+id+"/revisions/diff?from=2", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var d dto.NoteRevisionDiff
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+	assert.Equal(t, 2, d.From)
+	assert.Equal(t, 3, d.To)
+	assert.Equal(t, "Verbs", d.OldTitle)
+	assert.Equal(t, "Irregular verbs", d.NewTitle)
+	assert.Equal(t, 1, d.Added)
+	assert.Equal(t, 1, d.Removed)
+	assert.Equal(t, []diff.Line{
+		{Op: diff.OpEqual, Text: "ser", OldLine: 1, NewLine: 1},
+		{Op: diff.OpDelete, Text: "estar", OldLine: 2},
+		{Op: diff.OpEqual, Text: "ir", OldLine: 3, NewLine: 2},
+		{Op: diff.OpInsert, Text: "tener", NewLine: 3},
+	}, d.Lines)
+
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+id+"/revisions/diff?from=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+id+"/revisions/diff?from=1&to=9", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Восстановление ревизии 2 создаёт ревизию 4
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+id+"/revisions/2/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, "Verbs", restored.Title)
+	assert.Equal(t, "ser\nestar\nir", restored.Content)
+
+	revisions = listRevisions(t, r, token, id)
+	require.Len(t, revisions, 4)
+	require.NotNil(t, revisions[0].RestoredFrom)
+	assert.Equal(t, 2, *revisions[0].RestoredFrom)
+
+	// Чужой пользователь историю не видит
+	otherToken := registerAndLogin(t, r, "revisionsother@example.com", "revpass", "RevisionsOther")
+	w = doAuthRequest(t, r, otherToken, "GET", "/notes/"+id+"/revisions", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNoteRevisions_RetentionLimit(t *testing.T) {
+	r := setupNoteRevisionTestRouter(t, 3)
+	token := registerAndLogin(t, r, "revlimit@example.com", "revpass", "RevLimit")
+
+	note := createNoteWithFolderAndTags(t, r, token, "Limited", "", nil)
+	id := note.ID.String()
+	for _, content := range []string{"v2", "v3", "v4", "v5"} {
+		updateNoteContent(t, r, token, id, "Limited", content)
+	}
+
+	// Хранятся только три последние ревизии, нумерация не сбрасывается
+	revisions := listRevisions(t, r, token, id)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, []int{5, 4, 3}, []int{revisions[0].Revision, revisions[1].Revision, revisions[2].Revision})
+
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+id+"/revisions/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// failingRevisionRepo не может сохранить ни одной ревизии
+type failingRevisionRepo struct {
+	interfaces.NoteRevisionRepository
+}
+
+func (r *failingRevisionRepo) Create(ctx context.Context, revision *models.NoteRevision) error {
+	return errors.New("revision storage unavailable")
+}
+
+func TestNoteRevisions_CreateRollsBackWithoutFirstRevision(t *testing.T) {
+	db := SetupTestDB(t)
+	user := models.User{ID: uuid.New(), Nickname: "Reviser", Email: "reviser@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+
+	noteRepo := repository.NewNoteRepository(db)
+	revisionRepo := &failingRevisionRepo{NoteRevisionRepository: repository.NewNoteRevisionRepository(db)}
+	noteService := service.NewNoteService(noteRepo, repository.NewReviewLogRepository(db),
+		service.NewNoteRevisionService(revisionRepo, noteRepo, service.DefaultRevisionLimit),
+		service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo), service.NewNoteTypeService(repository.NewNoteTypeRepository(db)),
+		service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold),
+		repository.NewFolderRepo(db), repository.NewTagRepository(db))
+
+	// Без первой ревизии заметка не создаётся
+	_, err := noteService.CreateNote(context.Background(), user.ID.String(), &dto.NoteInput{Title: "Lost", Content: "Text"})
+	require.Error(t, err)
+
+	var notes int64
+	require.NoError(t, db.Model(&models.Note{}).Where("user_id = ?", user.ID).Count(&notes).Error)
+	assert.Equal(t, int64(0), notes)
+}
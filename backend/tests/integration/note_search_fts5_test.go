@@ -0,0 +1,32 @@
+//go:build sqlite_fts5
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Запуск: go test -tags sqlite_fts5 ./tests/...
+func TestNoteSearch_FTS5Ranking(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "searchfts5@example.com", "searchpass", "SearchFTS5")
+
+	inTitle := createNoteWithContent(t, r, token, "Mitochondria", "Cell organelle")
+	inContent := createNoteWithContent(t, r, token, "Cell biology", "Mitochondria, mitochondria and more mitochondria")
+
+	// bm25 с весом заголовка: одно совпадение в заголовке важнее трёх в тексте
+	result := searchNotes(t, r, token, "mitochondria")
+	require.Len(t, result.Notes, 2)
+	assert.Equal(t, inTitle, result.Notes[0].ID.String())
+	assert.Equal(t, inContent, result.Notes[1].ID.String())
+	assert.Equal(t, "<mark>Mitochondria</mark>", result.Highlights[inTitle].Title)
+
+	// Стеммер porter сводит формы слова к основе: runs находит running
+	running := createNoteWithContent(t, r, token, "Sports", "I was running fast")
+	result = searchNotes(t, r, token, "runs")
+	require.Len(t, result.Notes, 1)
+	assert.Equal(t, running, result.Notes[0].ID.String())
+}
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+)
+
+func createNoteWithContent(t *testing.T, r *gin.Engine, token, title, content string) string {
+	w := doAuthRequest(t, r, token, "POST", "/notes", map[string]string{"title": title, "content": content})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var note struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	return note.ID
+}
+
+func searchNotes(t *testing.T, r *gin.Engine, token, query string) dto.PaginatedNotes {
+	w := doAuthRequest(t, r, token, "GET", "/notes?search="+url.QueryEscape(query), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestNoteSearch_FullText(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "search@example.com", "searchpass", "Search")
+
+	spanish := createNoteWithContent(t, r, token, "Испанские глаголы", "Глаголы ser и estar")
+	grammar := createNoteWithContent(t, r, token, "Грамматика", "Неправильные глаголы прошедшего времени")
+	running := createNoteWithContent(t, r, token, "Sports", "I was running fast")
+	createNoteWithContent(t, r, token, "Unrelated", "Nothing to see here")
+
+	// Совпадения и в заголовке, и в тексте — выше, чем только в тексте
+	result := searchNotes(t, r, token, "глаголы")
+	require.Len(t, result.Notes, 2)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, spanish, result.Notes[0].ID.String())
+	assert.Equal(t, grammar, result.Notes[1].ID.String())
+
+	require.Contains(t, result.Highlights, spanish)
+	assert.Contains(t, result.Highlights[spanish].Title, "<mark>")
+	assert.Contains(t, result.Highlights[spanish].Snippet, "<mark>")
+	assert.Greater(t, result.Highlights[spanish].Rank, result.Highlights[grammar].Rank)
+
+	// Поиск по тексту, не только по заголовку; регистр не важен
+	result = searchNotes(t, r, token, "ESTAR")
+	require.Len(t, result.Notes, 1)
+	assert.Equal(t, spanish, result.Notes[0].ID.String())
+
+	// Форма слова: run находит running
+	result = searchNotes(t, r, token, "run")
+	require.Len(t, result.Notes, 1)
+	assert.Equal(t, running, result.Notes[0].ID.String())
+
+	// Разметка в тексте заметки экранируется, тегами остаются только <mark>
+	markup := createNoteWithContent(t, r, token, "<b>Tags</b>", `<script>alert("xss")</script> markup & tags`)
+	result = searchNotes(t, r, token, "markup")
+	require.Len(t, result.Notes, 1)
+	require.Contains(t, result.Highlights, markup)
+	assert.NotContains(t, result.Highlights[markup].Snippet, "<script>")
+	assert.Contains(t, result.Highlights[markup].Snippet, "&lt;script&gt;")
+	assert.Contains(t, result.Highlights[markup].Snippet, "<mark>markup</mark> &amp;")
+	result = searchNotes(t, r, token, "tags")
+	require.Contains(t, result.Highlights, markup)
+	assert.Equal(t, "&lt;b&gt;<mark>Tags</mark>&lt;/b&gt;", result.Highlights[markup].Title)
+
+	// Служебные символы не ломают запрос
+	result = searchNotes(t, r, token, `"*(-`)
+	assert.Empty(t, result.Notes)
+
+	// Изменённый текст сразу доступен для поиска
+	w := doAuthRequest(t, r, token, "PUT", "/notes/"+grammar, map[string]interface{}{
+		"title": "Грамматика", "content": "Спряжение estar", "version": noteVersion(t, r, token, grammar),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	result = searchNotes(t, r, token, "estar")
+	assert.Len(t, result.Notes, 2)
+
+	// Удалённые заметки и заметки других пользователей не находятся
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+spanish, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	result = searchNotes(t, r, token, "estar")
+	require.Len(t, result.Notes, 1)
+	assert.Equal(t, grammar, result.Notes[0].ID.String())
+
+	otherToken := registerAndLogin(t, r, "searchother@example.com", "searchpass", "SearchOther")
+	assert.Empty(t, searchNotes(t, r, otherToken, "estar").Notes)
+}
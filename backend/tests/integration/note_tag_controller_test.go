@@ -30,7 +30,7 @@ func setupNoteTagControllerTestRouter(t *testing.T) *gin.Engine {
 	authController := controller.NewAuthController(authService)
 
 	noteRepo := repository.NewNoteRepository(db)
-	noteService := service.NewNoteService(noteRepo)
+	noteService := newNoteService(db, noteRepo)
 	folderRepo := repository.NewFolderRepo(db)
 	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
 	noteController := controller.NewNoteController(noteService, assignFolderService)
@@ -46,7 +46,7 @@ func setupNoteTagControllerTestRouter(t *testing.T) *gin.Engine {
 	noteTagController := controller.NewNoteTagController(noteTagService)
 
 	r := gin.Default()
-	router.SetupRoutes(r, tokenService, authController, noteController, folderController, tagController, noteTagController, nil)
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController})
 
 	return r
 }
@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupNoteTypeTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+
+	noteTypeRepo := repository.NewNoteTypeRepository(db)
+	noteTypeController := controller.NewNoteTypeController(service.NewNoteTypeService(noteTypeRepo))
+	reviewSessionService := service.NewReviewSessionService(noteRepo, repository.NewSavedSearchRepository(db), noteTypeRepo, repository.NewFolderRepo(db), repository.NewReviewLogRepository(db))
+	reviewSessionController := controller.NewReviewSessionController(reviewSessionService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, ReviewSession: reviewSessionController, NoteType: noteTypeController})
+
+	return r, db
+}
+
+func vocabularyTypeInput() dto.NoteTypeInput {
+	return dto.NoteTypeInput{
+		Name: "Vocabulary",
+		Fields: []models.NoteTypeField{
+			{Name: "Word", Kind: "text", Required: true},
+			{Name: "Translation", Kind: "text", Required: true},
+			{Name: "Example", Kind: "long_text"},
+			{Name: "Audio", Kind: "audio"},
+		},
+		PromptTemplate: "{{Word}}",
+		AnswerTemplate: "{{ Translation }}\n{{Example}}",
+	}
+}
+
+func TestNoteType_CRUDAndValidation(t *testing.T) {
+	r, _ := setupNoteTypeTestRouter(t)
+	token := registerAndLogin(t, r, "notetypes@example.com", "typespass", "Types")
+
+	// Встроенный Basic есть всегда
+	w := doAuthRequest(t, r, token, "GET", "/note-types", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []models.NoteType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "Basic", list[0].Name)
+	assert.True(t, list[0].Builtin)
+
+	w = doAuthRequest(t, r, token, "POST", "/note-types", vocabularyTypeInput())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var vocabulary models.NoteType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &vocabulary))
+	assert.Len(t, vocabulary.Fields, 4)
+
+	// Без шаблонов: вопрос — первое поле, ответ — остальные
+	w = doAuthRequest(t, r, token, "POST", "/note-types", dto.NoteTypeInput{
+		Name:   "Capitals",
+		Fields: []models.NoteTypeField{{Name: "Country", Kind: "text"}, {Name: "Capital", Kind: "text"}, {Name: "Map", Kind: "url"}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var capitals models.NoteType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &capitals))
+	assert.Equal(t, "{{Country}}", capitals.PromptTemplate)
+	assert.Equal(t, "{{Capital}}\n{{Map}}", capitals.AnswerTemplate)
+
+	invalid := []dto.NoteTypeInput{
+		{Name: "Kinds", Fields: []models.NoteTypeField{{Name: "Word", Kind: "image"}}},
+		{Name: "Template", Fields: []models.NoteTypeField{{Name: "Word", Kind: "text"}}, PromptTemplate: "{{Missing}}"},
+		{Name: "Duplicates", Fields: []models.NoteTypeField{{Name: "Word", Kind: "text"}, {Name: "word", Kind: "text"}}},
+		{Name: "Empty", Fields: []models.NoteTypeField{}},
+	}
+	for _, input := range invalid {
+		w = doAuthRequest(t, r, token, "POST", "/note-types", input)
+		assert.Equal(t, http.StatusBadRequest, w.Code, input.Name)
+	}
+
+	// Имя Basic занято встроенным типом, имена уникальны без учёта регистра
+	basic := vocabularyTypeInput()
+	basic.Name = "basic"
+	w = doAuthRequest(t, r, token, "POST", "/note-types", basic)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	duplicate := vocabularyTypeInput()
+	duplicate.Name = "VOCABULARY"
+	w = doAuthRequest(t, r, token, "POST", "/note-types", duplicate)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	renamed := vocabularyTypeInput()
+	renamed.Name = "Words"
+	w = doAuthRequest(t, r, token, "PUT", "/note-types/"+vocabulary.ID.String(), renamed)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doAuthRequest(t, r, token, "GET", "/note-types", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 3)
+	assert.Equal(t, []string{"Basic", "Capitals", "Words"}, []string{list[0].Name, list[1].Name, list[2].Name})
+
+	w = doAuthRequest(t, r, token, "DELETE", "/note-types/"+capitals.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doAuthRequest(t, r, token, "GET", "/note-types/"+capitals.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNoteType_TypedNotesAndReview(t *testing.T) {
+	r, db := setupNoteTypeTestRouter(t)
+	token := registerAndLogin(t, r, "typednotes@example.com", "typespass", "Typed")
+
+	w := doAuthRequest(t, r, token, "POST", "/note-types", vocabularyTypeInput())
+	require.Equal(t, http.StatusCreated, w.Code)
+	var vocabulary models.NoteType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &vocabulary))
+	typeID := vocabulary.ID.String()
+
+	// Текст заметки собирается из полей, чтобы работали поиск и ссылки
+	w = doAuthRequest(t, r, token, "POST", "/notes", map[string]interface{}{
+		"title":        "Hund",
+		"note_type_id": typeID,
+		"fields": map[string]string{
+			"Word":        "der Hund",
+			"Translation": "dog",
+			"Example":     "Der Hund bellt.",
+			"Audio":       "https://example.com/audio/hund.mp3",
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var typed models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &typed))
+	require.NotNil(t, typed.NoteTypeID)
+	assert.Equal(t, vocabulary.ID, *typed.NoteTypeID)
+	assert.Equal(t, "dog", typed.Fields["Translation"])
+	assert.Equal(t, "der Hund\ndog\nDer Hund bellt.\nhttps://example.com/audio/hund.mp3", typed.Content)
+
+	invalid := []map[string]interface{}{
+		{"title": "Missing", "note_type_id": typeID, "fields": map[string]string{"Word": "die Katze"}},
+		{"title": "Unknown", "note_type_id": typeID, "fields": map[string]string{"Word": "a", "Translation": "b", "Color": "c"}},
+		{"title": "Audio", "note_type_id": typeID, "fields": map[string]string{"Word": "a", "Translation": "b", "Audio": "https://example.com/a.txt"}},
+		{"title": "Multiline", "note_type_id": typeID, "fields": map[string]string{"Word": "a\nb", "Translation": "b"}},
+		{"title": "No type", "note_type_id": "00000000-0000-0000-0000-000000000001", "fields": map[string]string{"Word": "a"}},
+		{"title": "Basic fields", "content": "text", "fields": map[string]string{"Word": "a"}},
+		{"title": "Basic without content"},
+	}
+	for _, body := range invalid {
+		w = doAuthRequest(t, r, token, "POST", "/notes", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body["title"])
+	}
+
+	basicID := createNoteWithContent(t, r, token, "Mitochondria", "Powerhouse of the cell")
+
+	// Обе заметки к повторению
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Model(&models.Note{}).Where("id IN ?", []string{typed.ID.String(), basicID}).Update("next_review_at", past).Error)
+
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", dto.ReviewSessionInput{Limit: 10})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var session dto.ReviewSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	require.Equal(t, 2, session.Total)
+	byID := make(map[string]dto.ReviewSessionNote)
+	for _, note := range session.Notes {
+		byID[note.ID] = note
+	}
+
+	card := byID[typed.ID.String()]
+	assert.Equal(t, "Vocabulary", card.NoteType)
+	assert.Equal(t, typeID, card.NoteTypeID)
+	assert.Equal(t, "der Hund", card.Prompt)
+	assert.Equal(t, "dog\nDer Hund bellt.", card.Answer)
+	assert.Equal(t, "https://example.com/audio/hund.mp3", card.Fields["Audio"])
+
+	card = byID[basicID]
+	assert.Equal(t, "Basic", card.NoteType)
+	assert.Empty(t, card.NoteTypeID)
+	assert.Equal(t, "Mitochondria", card.Prompt)
+	assert.Equal(t, "Powerhouse of the cell", card.Answer)
+
+	// Тип с заметками удалить нельзя
+	w = doAuthRequest(t, r, token, "DELETE", "/note-types/"+typeID, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Без note_type_id обновление сохраняет тип и проверяет поля
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+typed.ID.String(), map[string]interface{}{
+		"title":   "Hund",
+		"fields":  map[string]string{"Word": "der Hund"},
+		"version": typed.Version,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Пустой note_type_id переводит заметку в Basic
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+typed.ID.String(), map[string]interface{}{
+		"title":        "Hund",
+		"content":      "dog",
+		"note_type_id": "",
+		"version":      typed.Version,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Nil(t, updated.NoteTypeID)
+	assert.Empty(t, updated.Fields)
+
+	w = doAuthRequest(t, r, token, "DELETE", "/note-types/"+typeID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
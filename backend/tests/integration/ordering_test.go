@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func folderTree(t *testing.T, r *gin.Engine, token string) []*dto.FolderNode {
+	w := doAuthRequest(t, r, token, "GET", "/folders/tree", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tree []*dto.FolderNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	return tree
+}
+
+func nodeNames(nodes []*dto.FolderNode) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return names
+}
+
+// notesInOrder возвращает заголовки заметок папки (пустой folderID — всех заметок) по position
+func notesInOrder(t *testing.T, r *gin.Engine, token, folderID string) []string {
+	path := "/notes?limit=100&sort_by=position&order=asc"
+	if folderID != "" {
+		path += "&folder_id=" + folderID
+	}
+	w := doAuthRequest(t, r, token, "GET", path, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	titles := make([]string, len(page.Notes))
+	for i, note := range page.Notes {
+		titles[i] = note.Title
+	}
+	return titles
+}
+
+func TestOrdering_Folders(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "order@example.com", "orderpass", "Order")
+	otherToken := registerAndLogin(t, r, "order2@example.com", "orderpass", "Order2")
+
+	// Новые папки встают последними, порядок не зависит от имени
+	zeta := createFolder(t, r, token, "Zeta")
+	alpha := createFolder(t, r, token, "Alpha")
+	mid := createFolder(t, r, token, "Mid")
+	child := createSubfolder(t, r, token, "Child", alpha.ID.String())
+	assert.Equal(t, []string{"Zeta", "Alpha", "Mid"}, nodeNames(folderTree(t, r, token)))
+
+	w := moveFolder(t, r, token, mid.ID.String(), map[string]interface{}{"before": zeta.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var moved models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Less(t, moved.Position, zeta.Position)
+	assert.Equal(t, 2, moved.Version)
+
+	w = moveFolder(t, r, token, zeta.ID.String(), map[string]interface{}{"after": alpha.ID.String()})
+	require.Equal(t, http.StatusOK, w.Code)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, []string{"Mid", "Alpha", "Zeta"}, nodeNames(folderTree(t, r, token)))
+	}
+
+	// Без before/after — в конец; на своём месте ничего не меняется
+	w = moveFolder(t, r, token, mid.ID.String(), map[string]interface{}{})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Alpha", "Zeta", "Mid"}, nodeNames(folderTree(t, r, token)))
+	w = moveFolder(t, r, token, mid.ID.String(), map[string]interface{}{"after": zeta.ID.String()})
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Equal(t, 3, moved.Version)
+
+	// Перенос в другую папку ставит папку последней
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+zeta.ID.String(), map[string]interface{}{"parent_id": alpha.ID.String(), "version": 2})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tree := folderTree(t, r, token)
+	require.Equal(t, []string{"Alpha", "Mid"}, nodeNames(tree))
+	assert.Equal(t, []string{"Child", "Zeta"}, nodeNames(tree[0].Children))
+
+	cases := []map[string]interface{}{
+		{"before": alpha.ID.String(), "after": alpha.ID.String()},
+		{"before": mid.ID.String()},
+		{"before": child.ID.String()},
+		{"after": "not-a-uuid"},
+	}
+	for _, body := range cases {
+		w = moveFolder(t, r, token, mid.ID.String(), body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	w = moveFolder(t, r, otherToken, mid.ID.String(), map[string]interface{}{})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrdering_Notes(t *testing.T) {
+	r := setupWorkspaceTestRouter(t)
+	token := registerAndLogin(t, r, "ordernotes@example.com", "orderpass", "OrderNotes")
+
+	first := createNoteWithContent(t, r, token, "First", "1")
+	second := createNoteWithContent(t, r, token, "Second", "2")
+	third := createNoteWithContent(t, r, token, "Third", "3")
+	assert.Equal(t, []string{"First", "Second", "Third"}, notesInOrder(t, r, token, ""))
+
+	w := doAuthRequest(t, r, token, "POST", "/notes/"+third+"/move", map[string]interface{}{"before": first})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+first+"/move", map[string]interface{}{"after": second})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Third", "Second", "First"}, notesInOrder(t, r, token, ""))
+
+	// Порядок заметок в папке свой; перенесённые заметки сохраняют ключи, и они могут совпасть
+	folder := createFolder(t, r, token, "Biology")
+	cell := createNoteWithContent(t, r, token, "Cell", "membrane")
+	dna := createNoteWithContent(t, r, token, "DNA", "helix")
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{first}, "action": dto.BulkMove, "folder_id": folder.ID.String()})
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+dna+"/move", map[string]interface{}{"after": second})
+	require.Equal(t, http.StatusOK, w.Code)
+	var note models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+	assert.Equal(t, getNote(t, r, token, first).Position, note.Position)
+	runBulk(t, r, token, map[string]interface{}{"ids": []string{dna, cell}, "action": dto.BulkMove, "folder_id": folder.ID.String()})
+	assert.Equal(t, []string{"First", "DNA", "Cell"}, notesInOrder(t, r, token, folder.ID.String()))
+
+	// Между совпавшими ключами ничего не вставить — ключи папки расставляются заново
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+cell+"/move", map[string]interface{}{"after": first})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"First", "Cell", "DNA"}, notesInOrder(t, r, token, folder.ID.String()))
+
+	// Сосед из другой папки — ошибка
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+cell+"/move", map[string]interface{}{"before": second})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/notes/550e8400-e29b-41d4-a716-446655440000/move", map[string]interface{}{})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
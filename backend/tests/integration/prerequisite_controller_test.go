@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupPrerequisiteTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+
+	folderService := service.NewFolderService(folderRepo)
+	folderController := controller.NewFolderController(folderService)
+
+	tagRepo := repository.NewTagRepository(db)
+	tagService := service.NewTagService(tagRepo)
+	tagController := controller.NewTagController(tagService)
+
+	noteTagService := service.NewNoteTagService(noteRepo, tagRepo)
+	noteTagController := controller.NewNoteTagController(noteTagService)
+
+	reviewSessionService := service.NewReviewSessionService(noteRepo, repository.NewSavedSearchRepository(db), repository.NewNoteTypeRepository(db), repository.NewFolderRepo(db), repository.NewReviewLogRepository(db))
+	reviewSessionController := controller.NewReviewSessionController(reviewSessionService)
+
+	prerequisiteRepo := repository.NewPrerequisiteRepository(db)
+	prerequisiteService := service.NewPrerequisiteService(prerequisiteRepo, noteRepo, folderRepo)
+	prerequisiteController := controller.NewPrerequisiteController(prerequisiteService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, ReviewSession: reviewSessionController, Prerequisite: prerequisiteController})
+
+	return r, db
+}
+
+func TestPrerequisites_LinksAndCycles(t *testing.T) {
+	r, _ := setupPrerequisiteTestRouter(t)
+	token := registerAndLogin(t, r, "prereq@example.com", "prereqpass", "Prereq")
+
+	folder := createFolder(t, r, token, "Chemistry")
+	noteA := createNoteWithFolderAndTags(t, r, token, "Atoms", folder.ID.String(), nil)
+	noteB := createNoteWithFolderAndTags(t, r, token, "Molecules", folder.ID.String(), nil)
+	noteC := createNoteWithFolderAndTags(t, r, token, "Reactions", "", nil)
+
+	// A — пререквизит B, B — пререквизит C
+	w := doAuthRequest(t, r, token, "POST", "/notes/"+noteB.ID.String()+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": noteA.ID.String()})
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteC.ID.String()+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": noteB.ID.String(), "required_level": 40})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// C -> A замкнёт цикл A -> B -> C -> A
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteA.ID.String()+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": noteC.ID.String()})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Заметка не может зависеть от себя
+	w = doAuthRequest(t, r, token, "POST", "/notes/"+noteA.ID.String()+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": noteA.ID.String()})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Список пререквизитов
+	w = doAuthRequest(t, r, token, "GET", "/notes/"+noteC.ID.String()+"/prerequisites", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var prerequisites []dto.Prerequisite
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prerequisites))
+	require.Len(t, prerequisites, 1)
+	assert.Equal(t, noteB.ID.String(), prerequisites[0].PrerequisiteID)
+	assert.Equal(t, 40, prerequisites[0].RequiredLevel)
+	assert.False(t, prerequisites[0].Satisfied)
+
+	// Граф папки: A и B в папке, C связан с B и попадает в граф как внешний узел
+	w = doAuthRequest(t, r, token, "GET", "/folders/"+folder.ID.String()+"/prerequisite-graph", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var graph dto.PrerequisiteGraph
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &graph))
+	assert.Len(t, graph.Nodes, 3)
+	assert.Len(t, graph.Edges, 2)
+	for _, node := range graph.Nodes {
+		assert.Equal(t, node.ID != noteC.ID.String(), node.InFolder)
+	}
+
+	// Удаление ребра
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+noteC.ID.String()+"/prerequisites/"+noteB.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+noteC.ID.String()+"/prerequisites/"+noteB.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Чужой пользователь не видит пререквизиты
+	otherToken := registerAndLogin(t, r, "prereqother@example.com", "prereqpass", "PrereqOther")
+	w = doAuthRequest(t, r, otherToken, "GET", "/notes/"+noteB.ID.String()+"/prerequisites", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPrerequisites_GateNewCardQueue(t *testing.T) {
+	r, db := setupPrerequisiteTestRouter(t)
+	token := registerAndLogin(t, r, "prereqqueue@example.com", "prereqpass", "PrereqQueue")
+
+	noteA := createNoteWithFolderAndTags(t, r, token, "Basics", "", nil)
+	noteB := createNoteWithFolderAndTags(t, r, token, "Advanced", "", nil)
+
+	w := doAuthRequest(t, r, token, "POST", "/notes/"+noteB.ID.String()+"/prerequisites",
+		map[string]interface{}{"prerequisite_id": noteA.ID.String(), "required_level": 40})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	sessionNoteIDs := func() []string {
+		w := doAuthRequest(t, r, token, "POST", "/review/sessions", dto.ReviewSessionInput{Limit: 10})
+		require.Equal(t, http.StatusOK, w.Code)
+		var response dto.ReviewSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := make([]string, 0, len(response.Notes))
+		for _, n := range response.Notes {
+			ids = append(ids, n.ID)
+		}
+		return ids
+	}
+
+	// Пока A не выучена, B не попадает в очередь новых карточек
+	ids := sessionNoteIDs()
+	assert.Contains(t, ids, noteA.ID.String())
+	assert.NotContains(t, ids, noteB.ID.String())
+
+	// A достигла нужного уровня — B открывается
+	require.NoError(t, db.Model(&models.Note{}).Where("id = ?", noteA.ID).Update("memory_level", 40).Error)
+	ids = sessionNoteIDs()
+	assert.Contains(t, ids, noteB.ID.String())
+}
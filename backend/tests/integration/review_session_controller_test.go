@@ -33,7 +33,7 @@ func setupReviewSessionTestRouter(t *testing.T) *gin.Engine {
 	authController := controller.NewAuthController(authService)
 
 	noteRepo := repository.NewNoteRepository(db)
-	noteService := service.NewNoteService(noteRepo)
+	noteService := newNoteService(db, noteRepo)
 	folderRepo := repository.NewFolderRepo(db)
 	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
 	noteController := controller.NewNoteController(noteService, assignFolderService)
@@ -48,11 +48,11 @@ func setupReviewSessionTestRouter(t *testing.T) *gin.Engine {
 	noteTagService := service.NewNoteTagService(noteRepo, tagRepo)
 	noteTagController := controller.NewNoteTagController(noteTagService)
 
-	reviewSessionService := service.NewReviewSessionService(noteRepo)
+	reviewSessionService := service.NewReviewSessionService(noteRepo, repository.NewSavedSearchRepository(db), repository.NewNoteTypeRepository(db), repository.NewFolderRepo(db), repository.NewReviewLogRepository(db))
 	reviewSessionController := controller.NewReviewSessionController(reviewSessionService)
 
 	r := gin.Default()
-	router.SetupRoutes(r, tokenService, authController, noteController, folderController, tagController, noteTagController, reviewSessionController)
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, ReviewSession: reviewSessionController})
 
 	return r
 }
@@ -228,6 +228,7 @@ func createNoteWithReview(t *testing.T, r *gin.Engine, token, title, folderID st
 		"title":        title,
 		"content":      "content",
 		"memory_level": memoryLevel,
+		"version":      note.Version,
 	}
 	if nextReviewAt != nil {
 		updateBody["next_review_at"] = nextReviewAt.Format(time.RFC3339)
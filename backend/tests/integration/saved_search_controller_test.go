@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupSavedSearchTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+	tagRepo := repository.NewTagRepository(db)
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	noteTagController := controller.NewNoteTagController(service.NewNoteTagService(noteRepo, tagRepo))
+
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	reviewSessionController := controller.NewReviewSessionController(service.NewReviewSessionService(noteRepo, savedSearchRepo, repository.NewNoteTypeRepository(db), repository.NewFolderRepo(db), repository.NewReviewLogRepository(db)))
+	savedSearchController := controller.NewSavedSearchController(service.NewSavedSearchService(savedSearchRepo, noteService))
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, ReviewSession: reviewSessionController, SavedSearch: savedSearchController})
+
+	return r, db
+}
+
+func TestSavedSearch_CRUDAndRun(t *testing.T) {
+	r, db := setupSavedSearchTestRouter(t)
+	token := registerAndLogin(t, r, "saved@example.com", "savedpass", "Saved")
+
+	biology := createTag(t, r, token, "biology")
+	draft := createTag(t, r, token, "draft")
+
+	cell := createNoteWithFolderAndTags(t, r, token, "Cell", "", []string{biology.ID.String()})
+	division := createNoteWithFolderAndTags(t, r, token, "Division", "", []string{biology.ID.String(), draft.ID.String()})
+	tissue := createNoteWithFolderAndTags(t, r, token, "Tissue", "", []string{biology.ID.String()})
+	other := createNoteWithFolderAndTags(t, r, token, "Other", "", nil)
+
+	// Все, кроме tissue, пора повторять
+	require.NoError(t, db.Model(&models.Note{}).Where("id IN ?", []string{cell.ID.String(), division.ID.String(), other.ID.String()}).
+		Update("next_review_at", time.Now().Add(-time.Hour)).Error)
+
+	input := dto.SavedSearchInput{Name: "Bio", Query: "tag:biology -tag:draft", SortBy: "created_at", Order: "asc"}
+	w := doAuthRequest(t, r, token, "POST", "/saved-searches", input)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var saved models.SavedSearch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &saved))
+	assert.Equal(t, "tag:biology -tag:draft", saved.Query)
+
+	// Имя уникально без учёта регистра
+	w = doAuthRequest(t, r, token, "POST", "/saved-searches", dto.SavedSearchInput{Name: "bio"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Ошибка в q отдаётся с позицией
+	w = doAuthRequest(t, r, token, "POST", "/saved-searches", dto.SavedSearchInput{Name: "Broken", Query: "level:<x"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var syntaxResp struct {
+		Position int `json:"position"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &syntaxResp))
+	assert.Equal(t, 8, syntaxResp.Position)
+
+	w = doAuthRequest(t, r, token, "POST", "/saved-searches", dto.SavedSearchInput{Name: "Sorted", SortBy: "title"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAuthRequest(t, r, token, "GET", "/saved-searches", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []models.SavedSearch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+
+	// Запуск сохранённого поиска
+	runPath := "/saved-searches/" + saved.ID.String() + "/notes"
+	w = doAuthRequest(t, r, token, "GET", runPath, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Notes, 2)
+	assert.Equal(t, cell.ID, page.Notes[0].ID)
+	assert.Equal(t, tissue.ID, page.Notes[1].ID)
+
+	// Сессия повторения из сохранённого поиска: к сроку — cell, добор — tissue
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{
+		"saved_search_id": saved.ID.String(), "limit": 10,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var session dto.ReviewSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	sessionIDs := make([]string, len(session.Notes))
+	for i, n := range session.Notes {
+		sessionIDs[i] = n.ID
+	}
+	assert.Equal(t, []string{cell.ID.String(), tissue.ID.String()}, sessionIDs)
+
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{
+		"saved_search_id": "00000000-0000-0000-0000-000000000000",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Изменение фильтров
+	input.Query = "tag:biology"
+	input.SortBy = ""
+	w = doAuthRequest(t, r, token, "PUT", "/saved-searches/"+saved.ID.String(), input)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "GET", runPath+"?limit=50", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	page = dto.PaginatedNotes{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.True(t, containsNote(page.Notes, division.ID))
+	assert.False(t, containsNote(page.Notes, other.ID))
+	assert.Len(t, page.Notes, 3)
+
+	// Чужой сохранённый поиск не виден
+	otherToken := registerAndLogin(t, r, "saved2@example.com", "savedpass", "Saved2")
+	w = doAuthRequest(t, r, otherToken, "GET", runPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAuthRequest(t, r, token, "DELETE", "/saved-searches/"+saved.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doAuthRequest(t, r, token, "GET", "/saved-searches/"+saved.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupStatsTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	reviewLogRepo := repository.NewReviewLogRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+
+	statsService := service.NewStatsService(noteRepo, reviewLogRepo, folderRepo)
+	statsController := controller.NewStatsController(statsService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Stats: statsController})
+
+	return r
+}
+
+func TestStats_Simulate(t *testing.T) {
+	r := setupStatsTestRouter(t)
+	token := registerAndLogin(t, r, "simulate@example.com", "simpass", "Simulate")
+
+	for _, title := range []string{"One", "Two", "Three", "Four", "Five"} {
+		createNoteWithFolderAndTags(t, r, token, title, "", nil)
+	}
+
+	// Ответ на одну карточку попадает в журнал повторений
+	notes := doAuthRequest(t, r, token, "GET", "/notes", nil)
+	require.Equal(t, http.StatusOK, notes.Code)
+	var list dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(notes.Body.Bytes(), &list))
+	require.NotEmpty(t, list.Notes)
+	w := doAuthRequest(t, r, token, "POST", "/notes/"+list.Notes[0].ID.String()+"/review",
+		map[string]interface{}{"remembered": true})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	simulate := func(body map[string]interface{}) dto.SimulationResult {
+		w := doAuthRequest(t, r, token, "POST", "/stats/simulate", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result dto.SimulationResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	result := simulate(map[string]interface{}{
+		"days":     10,
+		"settings": map[string]interface{}{"new_per_day": 2},
+	})
+	assert.Equal(t, 5, result.Notes)
+	assert.Len(t, result.Days, 10)
+	assert.Len(t, result.RecallRates, 5)
+	assert.Equal(t, 2, result.Settings.NewPerDay)
+
+	// Новые карточки вводятся не быстрее лимита
+	for _, day := range result.Days {
+		assert.LessOrEqual(t, day.NewCards, 2)
+	}
+	assert.Equal(t, 2, result.Days[0].NewCards)
+	assert.Greater(t, result.TotalReviews+result.TotalNewCards, 0)
+	assert.Greater(t, result.ExpectedRetention, 0.0)
+	assert.Greater(t, result.TotalTimeSeconds, 0.0)
+
+	// Одинаковый seed — одинаковый прогноз
+	again := simulate(map[string]interface{}{
+		"days":     10,
+		"settings": map[string]interface{}{"new_per_day": 2},
+	})
+	assert.Equal(t, result.Days, again.Days)
+
+	// Алгоритм, целевая доля и шаги обучения тоже переопределяются
+	steps := simulate(map[string]interface{}{
+		"days": 3,
+		"settings": map[string]interface{}{
+			"new_per_day":      2,
+			"algorithm":        "exponential",
+			"target_retention": 0.85,
+			"learning_steps":   []int{1, 10},
+		},
+	})
+	assert.Equal(t, "exponential", steps.Settings.Algorithm)
+	assert.Equal(t, 0.85, steps.Settings.TargetRetention)
+	assert.Equal(t, []int{1, 10}, steps.Settings.LearningSteps)
+	// Карточки первого дня — на шагах обучения, поэтому все приходят на следующий день
+	assert.Equal(t, 2, steps.Days[0].NewCards)
+	assert.Equal(t, 2, steps.Days[1].Reviews)
+
+	// Некорректные настройки — 400
+	w = doAuthRequest(t, r, token, "POST", "/stats/simulate", map[string]interface{}{
+		"settings": map[string]interface{}{"interval_days": []int{5, 1}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAuthRequest(t, r, token, "POST", "/stats/simulate", map[string]interface{}{
+		"settings": map[string]interface{}{"algorithm": "random"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAuthRequest(t, r, token, "POST", "/stats/simulate", map[string]interface{}{"days": 1000})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStats_SimulateFolderSettings(t *testing.T) {
+	r := setupStatsTestRouter(t)
+	token := registerAndLogin(t, r, "simfolders@example.com", "simpass", "SimFolders")
+
+	biology := createFolder(t, r, token, "Biology")
+	cells := createSubfolder(t, r, token, "Cells", biology.ID.String())
+	w := doAuthRequest(t, r, token, "PUT", "/folders/"+biology.ID.String()+"/settings", map[string]interface{}{"new_per_day": 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, title := range []string{"Cell", "Membrane"} {
+		createNoteWithFolderAndTags(t, r, token, title, cells.ID.String(), nil)
+	}
+	createNoteWithFolderAndTags(t, r, token, "Plant", biology.ID.String(), nil)
+	for _, title := range []string{"Loose one", "Loose two"} {
+		createNoteWithFolderAndTags(t, r, token, title, "", nil)
+	}
+
+	simulate := func(body map[string]interface{}) dto.SimulationResult {
+		w := doAuthRequest(t, r, token, "POST", "/stats/simulate", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result dto.SimulationResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	// Лимит Biology общий для всего поддерева, заметки без папки живут по настройкам по умолчанию
+	result := simulate(map[string]interface{}{"days": 1})
+	assert.Equal(t, 5, result.Notes)
+	assert.Equal(t, 3, result.Days[0].NewCards)
+	assert.Equal(t, 20, result.Settings.NewPerDay)
+
+	// Кандидатные настройки ложатся поверх настроек папки
+	result = simulate(map[string]interface{}{"days": 1, "settings": map[string]interface{}{"new_per_day": 2}})
+	assert.Equal(t, 4, result.Days[0].NewCards)
+
+	// Переопределение, несовместимое с настройками папки, — 400
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+cells.ID.String()+"/settings", map[string]interface{}{"level_step": 2})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "POST", "/stats/simulate", map[string]interface{}{
+		"settings": map[string]interface{}{"learning_steps": []int{1, 5, 10}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
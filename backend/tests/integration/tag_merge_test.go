@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func tagNames(t *testing.T, r *gin.Engine, token string) []string {
+	w := doAuthRequest(t, r, token, "GET", "/tags", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tags []models.Tag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tags))
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func bulkRenameTags(t *testing.T, r *gin.Engine, token string, body map[string]interface{}) dto.TagBulkRenameResult {
+	w := doAuthRequest(t, r, token, "POST", "/tags/bulk-rename", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result dto.TagBulkRenameResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestTagMerge(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "tagmerge@example.com", "mergepass", "TagMerge")
+	otherToken := registerAndLogin(t, r, "tagmerge2@example.com", "mergepass", "TagMerge2")
+
+	bio := createTag(t, r, token, "bio")
+	biology := createTag(t, r, token, "Biology")
+	course := createTag(t, r, token, "biology-101")
+	cells := createTag(t, r, token, "bio::cells")
+
+	tag := func(title string, tags ...models.Tag) string {
+		ids := make([]string, len(tags))
+		for i, tag := range tags {
+			ids[i] = tag.ID.String()
+		}
+		id := createNoteWithContent(t, r, token, title, title)
+		runBulk(t, r, token, map[string]interface{}{"ids": []string{id}, "action": dto.BulkAddTags, "tag_ids": ids})
+		return id
+	}
+	both := tag("Both", bio, biology, course)
+	onlyCourse := tag("Course", course)
+	tag("Cell", cells)
+
+	merge := func(token string, target models.Tag, sources ...models.Tag) (int, dto.TagMergeResult) {
+		ids := make([]string, len(sources))
+		for i, source := range sources {
+			ids[i] = source.ID.String()
+		}
+		w := doAuthRequest(t, r, token, "POST", "/tags/merge", map[string]interface{}{"source_ids": ids, "target_id": target.ID.String()})
+		var result dto.TagMergeResult
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		}
+		return w.Code, result
+	}
+
+	// Ошибки ничего не меняют
+	code, _ := merge(token, cells, bio)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = merge(token, biology, biology)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = merge(otherToken, biology, bio)
+	assert.Equal(t, http.StatusNotFound, code)
+	clash := createTag(t, r, token, "Biology::cells")
+	code, _ = merge(token, biology, bio, course)
+	assert.Equal(t, http.StatusConflict, code)
+	w := doAuthRequest(t, r, token, "DELETE", "/tags/"+clash.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// У заметки Both уже есть Biology — её связи схлопываются в одну
+	code, result := merge(token, biology, bio, course, bio)
+	require.Equal(t, http.StatusOK, code)
+	assert.ElementsMatch(t, []uuid.UUID{bio.ID, course.ID}, result.MergedTagIDs)
+	assert.Equal(t, 1, result.MovedLinks)
+	assert.Equal(t, 2, result.DuplicateLinks)
+	assert.Equal(t, biology.ID, result.Target.ID)
+
+	assert.ElementsMatch(t, []string{"Biology", "Biology::cells"}, tagNames(t, r, token))
+	assert.Equal(t, []string{biology.ID.String()}, noteTagIDs(t, r, token, both))
+	assert.Equal(t, []string{biology.ID.String()}, noteTagIDs(t, r, token, onlyCourse))
+	assert.Equal(t, 3, getNote(t, r, token, both).Version)
+	assert.Equal(t, []string{"Both", "Cell", "Course"}, taggedTitles(t, r, token, biology.ID.String()))
+
+	w = doAuthRequest(t, r, token, "GET", "/tags/"+cells.ID.String(), nil)
+	var moved models.Tag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Equal(t, "Biology::cells", moved.Name)
+}
+
+func TestTagBulkRename(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "tagrename@example.com", "renamepass", "TagRename")
+
+	for _, name := range []string{"lang::es", "lang::de", "chem-101", "chem-102", "math"} {
+		createTag(t, r, token, name)
+	}
+
+	// Предпросмотр ничего не меняет
+	preview := bulkRenameTags(t, r, token, map[string]interface{}{"mode": "prefix", "pattern": "LANG", "replacement": "language", "dry_run": true})
+	assert.True(t, preview.DryRun)
+	require.Len(t, preview.Renamed, 3)
+	assert.Equal(t, "lang", preview.Renamed[0].From)
+	assert.Equal(t, "language::de", preview.Renamed[1].To)
+	assert.Empty(t, preview.Conflicts)
+	assert.Contains(t, tagNames(t, r, token), "lang::es")
+
+	result := bulkRenameTags(t, r, token, map[string]interface{}{"mode": "prefix", "pattern": "lang", "replacement": "language"})
+	assert.False(t, result.DryRun)
+	assert.ElementsMatch(t, []string{"language", "language::de", "language::es", "chem-101", "chem-102", "math"}, tagNames(t, r, token))
+
+	// Регулярное выражение с группами; недостающий родитель создаётся
+	result = bulkRenameTags(t, r, token, map[string]interface{}{"mode": "regex", "pattern": `^(\w+)-(\d+)$`, "replacement": "${1}::$2"})
+	assert.Len(t, result.Renamed, 2)
+	assert.Equal(t, []string{"chem"}, result.CreatedParents)
+	tree := tagTree(t, r, token)
+	require.Equal(t, "chem", tree[0].Name)
+	assert.Len(t, tree[0].Children, 2)
+
+	// Шаблон подходит только под родителя — потомки переименовываются вместе с ним
+	result = bulkRenameTags(t, r, token, map[string]interface{}{"mode": "regex", "pattern": `^language$`, "replacement": "lang"})
+	require.Len(t, result.Renamed, 3)
+	assert.Equal(t, "language::de", result.Renamed[1].From)
+	assert.Equal(t, "lang::de", result.Renamed[1].To)
+	assert.Contains(t, tagNames(t, r, token), "lang::es")
+	assert.NotContains(t, tagNames(t, r, token), "language::es")
+
+	// Конфликт виден в предпросмотре и блокирует переименование целиком
+	body := map[string]interface{}{"mode": "regex", "pattern": `^chem::10\d$`, "replacement": "chem::1", "dry_run": true}
+	preview = bulkRenameTags(t, r, token, body)
+	assert.Len(t, preview.Conflicts, 2)
+	body["dry_run"] = false
+	w := doAuthRequest(t, r, token, "POST", "/tags/bulk-rename", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, tagNames(t, r, token), "chem::101")
+
+	for _, bad := range []map[string]interface{}{
+		{"mode": "regex", "pattern": "(", "replacement": "x"},
+		{"mode": "glob", "pattern": "*", "replacement": "x"},
+		{"mode": "prefix", "pattern": "math", "replacement": "::"},
+	} {
+		w = doAuthRequest(t, r, token, "POST", "/tags/bulk-rename", bad)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+}
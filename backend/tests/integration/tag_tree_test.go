@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+func tagTree(t *testing.T, r *gin.Engine, token string) []dto.TagNode {
+	w := doAuthRequest(t, r, token, "GET", "/tags/tree", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tree []dto.TagNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	return tree
+}
+
+// taggedTitles возвращает отсортированные заголовки заметок с тегом tagID или его потомками
+func taggedTitles(t *testing.T, r *gin.Engine, token, tagID string) []string {
+	w := doAuthRequest(t, r, token, "GET", "/notes?limit=100&tag_ids[]="+tagID, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page dto.PaginatedNotes
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	titles := make([]string, len(page.Notes))
+	for i, note := range page.Notes {
+		titles[i] = note.Title
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+func TestTagTree_Hierarchy(t *testing.T) {
+	r := setupFolderSubtreeTestRouter(t)
+	token := registerAndLogin(t, r, "tagtree@example.com", "tagtreepass", "TagTree")
+	otherToken := registerAndLogin(t, r, "tagtree2@example.com", "tagtreepass", "TagTree2")
+
+	// Недостающие родители создаются вместе с тегом, пробелы вокруг :: убираются
+	verbs := createTag(t, r, token, "lang :: spanish :: verbs")
+	assert.Equal(t, "lang::spanish::verbs", verbs.Name)
+	nouns := createTag(t, r, token, "lang::spanish::nouns")
+	german := createTag(t, r, token, "lang::german")
+	createTag(t, r, token, "math")
+
+	tree := tagTree(t, r, token)
+	require.Len(t, tree, 2)
+	lang := tree[0]
+	assert.Equal(t, "lang", lang.Name)
+	require.Len(t, lang.Children, 2)
+	assert.Equal(t, "german", lang.Children[0].Label)
+	spanish := lang.Children[1]
+	assert.Equal(t, "lang::spanish", spanish.Name)
+	require.Len(t, spanish.Children, 2)
+	assert.Equal(t, "nouns", spanish.Children[0].Label)
+	assert.Equal(t, verbs.ID.String(), spanish.Children[1].ID)
+	assert.Empty(t, tagTree(t, r, otherToken))
+
+	w := doAuthRequest(t, r, token, "POST", "/tags", map[string]string{"name": "LANG::Spanish"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/tags", map[string]string{"name": "lang::::x"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Родительский тег совпадает с заметками потомков, заметка с обоими — один раз
+	tag := func(title string, tagIDs ...string) string {
+		id := createNoteWithContent(t, r, token, title, title)
+		runBulk(t, r, token, map[string]interface{}{"ids": []string{id}, "action": dto.BulkAddTags, "tag_ids": tagIDs})
+		return id
+	}
+	tag("Hablar", verbs.ID.String())
+	tag("Casa", nouns.ID.String(), spanish.ID)
+	tag("Haus", german.ID.String())
+	createNoteWithContent(t, r, token, "Untagged", "x")
+
+	assert.Equal(t, []string{"Casa", "Hablar", "Haus"}, taggedTitles(t, r, token, lang.ID))
+	assert.Equal(t, []string{"Casa", "Hablar"}, taggedTitles(t, r, token, spanish.ID))
+	assert.Equal(t, []string{"Hablar"}, taggedTitles(t, r, token, verbs.ID.String()))
+
+	w = doAuthRequest(t, r, token, "POST", "/review/sessions", map[string]interface{}{"tag_ids": []string{spanish.ID}, "limit": 100})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var session dto.ReviewSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(t, 2, session.Total)
+
+	// Переименование и перенос родителя переносят потомков
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+lang.ID, map[string]interface{}{"name": "languages", "version": lang.Version})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "GET", "/tags/"+verbs.ID.String(), nil)
+	var renamed models.Tag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &renamed))
+	assert.Equal(t, "languages::spanish::verbs", renamed.Name)
+	assert.Equal(t, 2, renamed.Version)
+
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+spanish.ID, map[string]interface{}{"name": "romance::spanish", "version": 2})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tree = tagTree(t, r, token)
+	require.Len(t, tree, 3)
+	assert.Equal(t, []string{"languages", "math", "romance"}, []string{tree[0].Name, tree[1].Name, tree[2].Name})
+	assert.Equal(t, "languages::german", tree[0].Children[0].Name)
+	require.Len(t, tree[2].Children, 1)
+	assert.Equal(t, []string{"romance::spanish::nouns", "romance::spanish::verbs"},
+		[]string{tree[2].Children[0].Children[0].Name, tree[2].Children[0].Children[1].Name})
+	assert.Equal(t, []string{"Casa", "Hablar"}, taggedTitles(t, r, token, tree[2].ID))
+
+	// Нельзя перенести тег под себя или так, чтобы путь потомка занял чужой тег
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+spanish.ID, map[string]interface{}{"name": "romance::spanish::old", "version": 3})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	createTag(t, r, token, "es::verbs")
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+spanish.ID, map[string]interface{}{"name": "es", "version": 3})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doAuthRequest(t, r, token, "GET", "/tags/"+nouns.ID.String(), nil)
+	assert.Contains(t, w.Body.String(), `"romance::spanish::nouns"`)
+}
@@ -1,12 +1,26 @@
 package integration
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"valibibe/internal/controller"
+	"valibibe/internal/dedup"
 	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/repository/interfaces"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
 )
 
 func SetupTestDB(t *testing.T) *gorm.DB {
@@ -15,10 +29,109 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to open test database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Note{}, &models.Folder{}, &models.Tag{}, &models.NoteTag{})
+	err = db.AutoMigrate(&models.User{}, &models.Note{}, &models.Folder{}, &models.Tag{}, &models.NoteTag{},
+		&models.Exam{}, &models.ExamQuestion{}, &models.NotePrerequisite{}, &models.ReviewLog{}, &models.NoteRevision{}, &models.SavedSearch{}, &models.NoteLink{}, &models.NoteType{}, &models.NoteDedupKey{})
 	if err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
+	// Полнотекстовый поиск в SQLite работает через FTS-таблицу
+	if err := repository.EnsureSQLiteSearchIndex(db); err != nil {
+		t.Fatalf("failed to create search index: %v", err)
+	}
+
 	return db
 }
+
+// newNoteService собирает NoteService со всеми зависимостями поверх тестовой БД
+func newNoteService(db *gorm.DB, noteRepo interfaces.NoteRepository) *service.NoteService {
+	revisionService := service.NewNoteRevisionService(repository.NewNoteRevisionRepository(db), noteRepo, service.DefaultRevisionLimit)
+	linkService := service.NewNoteLinkService(repository.NewNoteLinkRepository(db), noteRepo)
+	noteTypeService := service.NewNoteTypeService(repository.NewNoteTypeRepository(db))
+	duplicateService := service.NewDuplicateService(repository.NewNoteDuplicateRepository(db), dedup.DefaultThreshold)
+	return service.NewNoteService(noteRepo, repository.NewReviewLogRepository(db), revisionService, linkService, noteTypeService, duplicateService,
+		repository.NewFolderRepo(db), repository.NewTagRepository(db))
+}
+
+// doAuthRequest выполняет авторизованный запрос с JSON-телом (body может быть nil)
+func doAuthRequest(t *testing.T, r *gin.Engine, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	buf := bytes.NewBuffer(nil)
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request body: %v", err)
+		}
+		buf = bytes.NewBuffer(jsonBody)
+	}
+	req, _ := http.NewRequest(method, path, buf)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// noteVersion возвращает текущую версию заметки — её требует PUT /notes/:id
+func noteVersion(t *testing.T, r *gin.Engine, token, noteID string) int {
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+noteID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to get note %s: %d %s", noteID, w.Code, w.Body.String())
+	}
+	var note models.Note
+	if err := json.Unmarshal(w.Body.Bytes(), &note); err != nil {
+		t.Fatalf("failed to decode note: %v", err)
+	}
+	return note.Version
+}
+
+// setupWorkspaceTestRouter — роутер с заметками, папками, тегами и массовыми операциями;
+// на нём держатся тесты, которым не нужны другие контроллеры
+func setupWorkspaceTestRouter(t *testing.T) *gin.Engine {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	tagRepo := repository.NewTagRepository(db)
+	noteController := controller.NewNoteController(noteService, service.NewAssignFolderService(noteRepo, folderRepo))
+	folderController := controller.NewFolderController(service.NewFolderService(folderRepo))
+	tagController := controller.NewTagController(service.NewTagService(tagRepo))
+	bulkService := service.NewNoteBulkService(repository.NewNoteBulkRepository(db), noteService, folderRepo, tagRepo)
+	bulkController := controller.NewNoteBulkController(bulkService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteBulk: bulkController})
+
+	return r
+}
+
+// folderVersion возвращает текущую версию папки; false — папка не найдена
+func folderVersion(t *testing.T, r *gin.Engine, token, folderID string) (int, bool) {
+	w := doAuthRequest(t, r, token, "GET", "/folders/"+folderID+"/path", nil)
+	if w.Code != http.StatusOK {
+		return 0, false
+	}
+	var chain []models.Folder
+	if err := json.Unmarshal(w.Body.Bytes(), &chain); err != nil || len(chain) == 0 {
+		t.Fatalf("failed to decode folder path: %v", err)
+	}
+	return chain[len(chain)-1].Version, true
+}
+
+// moveFolder выполняет POST /folders/:id/move; без version в body подставляет текущую версию папки
+func moveFolder(t *testing.T, r *gin.Engine, token, folderID string, body map[string]interface{}) *httptest.ResponseRecorder {
+	if _, ok := body["version"]; !ok {
+		if version, ok := folderVersion(t, r, token, folderID); ok {
+			body["version"] = version
+		}
+	}
+	return doAuthRequest(t, r, token, "POST", "/folders/"+folderID+"/move", body)
+}
@@ -0,0 +1,282 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"valibibe/internal/controller"
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+	"valibibe/internal/repository"
+	"valibibe/internal/router"
+	"valibibe/internal/service"
+)
+
+func setupTrashTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *service.TrashService) {
+	err := godotenv.Load("../../../.env")
+	assert.NoError(t, err)
+
+	db := SetupTestDB(t)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService()
+	authService := service.NewAuthService(userRepo, tokenService)
+	authController := controller.NewAuthController(authService)
+
+	noteRepo := repository.NewNoteRepository(db)
+	noteService := newNoteService(db, noteRepo)
+	folderRepo := repository.NewFolderRepo(db)
+	assignFolderService := service.NewAssignFolderService(noteRepo, folderRepo)
+	noteController := controller.NewNoteController(noteService, assignFolderService)
+
+	folderService := service.NewFolderService(folderRepo)
+	folderController := controller.NewFolderController(folderService)
+
+	tagRepo := repository.NewTagRepository(db)
+	tagService := service.NewTagService(tagRepo)
+	tagController := controller.NewTagController(tagService)
+
+	noteTagService := service.NewNoteTagService(noteRepo, tagRepo)
+	noteTagController := controller.NewNoteTagController(noteTagService)
+
+	trashService := service.NewTrashService(repository.NewTrashRepository(db), 7*24*time.Hour)
+	trashController := controller.NewTrashController(trashService)
+
+	r := gin.Default()
+	router.SetupRoutes(r, tokenService, router.Controllers{Auth: authController, Note: noteController, Folder: folderController, Tag: tagController, NoteTag: noteTagController, Trash: trashController})
+
+	return r, db, trashService
+}
+
+func getTrash(t *testing.T, r *gin.Engine, token string) dto.TrashList {
+	w := doAuthRequest(t, r, token, "GET", "/trash", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash dto.TrashList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	return trash
+}
+
+func getFolderTree(t *testing.T, r *gin.Engine, token string) []dto.FolderNode {
+	w := doAuthRequest(t, r, token, "GET", "/folders/tree", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tree []dto.FolderNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	return tree
+}
+
+func TestTrash_FolderDeleteAndRestore(t *testing.T) {
+	r, _, _ := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "trash@example.com", "trashpass", "Trash")
+
+	parent := createFolder(t, r, token, "Languages")
+	w := doAuthRequest(t, r, token, "POST", "/folders", map[string]interface{}{
+		"name": "Spanish", "parent_id": parent.ID.String(),
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var child models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &child))
+
+	tag := createTag(t, r, token, "verbs")
+	noteInParent := createNoteWithFolderAndTags(t, r, token, "Grammar", parent.ID.String(), []string{tag.ID.String()})
+	noteInChild := createNoteWithFolderAndTags(t, r, token, "Hola", child.ID.String(), nil)
+	loose := createNoteWithFolderAndTags(t, r, token, "Loose", "", nil)
+
+	// Отдельно удалённая заметка и папка целиком
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+loose.ID.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doAuthRequest(t, r, token, "DELETE", "/folders/"+parent.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// Из обычных выборок всё исчезло
+	assert.Empty(t, getNotes(t, r, token, nil).Notes)
+	assert.Empty(t, getFolderTree(t, r, token))
+
+	// В корзине папка одной записью со счётчиками и отдельная заметка
+	trash := getTrash(t, r, token)
+	assert.Equal(t, 7, trash.RetentionDays)
+	require.Len(t, trash.Items, 2)
+	byID := make(map[string]dto.TrashItem)
+	for _, item := range trash.Items {
+		byID[item.ID] = item
+	}
+	folderItem := byID[parent.ID.String()]
+	assert.Equal(t, dto.TrashItemFolder, folderItem.Type)
+	assert.Equal(t, 1, folderItem.FoldersCount)
+	assert.Equal(t, 2, folderItem.NotesCount)
+	assert.WithinDuration(t, folderItem.DeletedAt.Add(7*24*time.Hour), folderItem.PurgeAt, time.Second)
+	assert.Equal(t, dto.TrashItemNote, byID[loose.ID.String()].Type)
+
+	// Восстановление папки возвращает подпапки, заметки и связи с тегами
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+parent.ID.String()+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tree := getFolderTree(t, r, token)
+	require.Len(t, tree, 1)
+	require.Len(t, tree[0].Children, 1)
+	assert.Equal(t, child.ID.String(), tree[0].Children[0].ID)
+
+	notes := getNotes(t, r, token, nil).Notes
+	require.Len(t, notes, 2)
+	for _, n := range notes {
+		if n.ID == noteInParent.ID {
+			require.Len(t, n.Tags, 1)
+			assert.Equal(t, tag.ID, n.Tags[0].ID)
+		}
+	}
+	assert.True(t, containsNote(notes, noteInChild.ID))
+
+	// Отдельно удалённая заметка осталась в корзине
+	trash = getTrash(t, r, token)
+	require.Len(t, trash.Items, 1)
+	assert.Equal(t, loose.ID.String(), trash.Items[0].ID)
+
+	// Повторное восстановление — 404
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+parent.ID.String()+"/restore", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTrash_RestoreNoteRebuildsFolderChain(t *testing.T) {
+	r, _, _ := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "trashchain@example.com", "trashpass", "TrashChain")
+
+	parent := createFolder(t, r, token, "Science")
+	w := doAuthRequest(t, r, token, "POST", "/folders", map[string]interface{}{
+		"name": "Physics", "parent_id": parent.ID.String(),
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var child models.Folder
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &child))
+
+	note := createNoteWithFolderAndTags(t, r, token, "Newton", child.ID.String(), nil)
+	sibling := createNoteWithFolderAndTags(t, r, token, "Kepler", parent.ID.String(), nil)
+
+	w = doAuthRequest(t, r, token, "DELETE", "/folders/"+parent.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// Заметка из вложенной папки восстанавливается вместе с цепочкой папок
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+note.ID.String()+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tree := getFolderTree(t, r, token)
+	require.Len(t, tree, 1)
+	assert.Equal(t, parent.ID.String(), tree[0].ID)
+	require.Len(t, tree[0].Children, 1)
+	assert.Equal(t, child.ID.String(), tree[0].Children[0].ID)
+
+	notes := getNotes(t, r, token, nil).Notes
+	require.Len(t, notes, 1)
+	assert.Equal(t, note.ID, notes[0].ID)
+
+	// Остальное содержимое папки по-прежнему в корзине
+	trash := getTrash(t, r, token)
+	require.Len(t, trash.Items, 1)
+	assert.Equal(t, sibling.ID.String(), trash.Items[0].ID)
+}
+
+func TestTrash_TagRestoreConflictAndPurge(t *testing.T) {
+	r, db, trashService := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "trashtag@example.com", "trashpass", "TrashTag")
+
+	tag := createTag(t, r, token, "history")
+	note := createNoteWithFolderAndTags(t, r, token, "Rome", "", []string{tag.ID.String()})
+
+	w := doAuthRequest(t, r, token, "DELETE", "/tags/"+tag.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	notes := getNotes(t, r, token, nil).Notes
+	require.Len(t, notes, 1)
+	assert.Empty(t, notes[0].Tags)
+
+	// Имя удалённого тега свободно, но тогда восстановить его нельзя
+	replacement := createTag(t, r, token, "History")
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+tag.ID.String()+"/restore", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doAuthRequest(t, r, token, "DELETE", "/tags/"+replacement.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+tag.ID.String()+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	notes = getNotes(t, r, token, nil).Notes
+	require.Len(t, notes[0].Tags, 1)
+	assert.Equal(t, tag.ID, notes[0].Tags[0].ID)
+
+	// Очистка удаляет только то, что лежит в корзине дольше срока хранения
+	w = doAuthRequest(t, r, token, "DELETE", "/notes/"+note.ID.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	purged, err := trashService.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	require.NoError(t, db.Unscoped().Model(&models.Note{}).Where("id = ?", note.ID).
+		Update("deleted_at", time.Now().Add(-8*24*time.Hour)).Error)
+	require.NoError(t, db.Unscoped().Model(&models.Tag{}).Where("id = ?", replacement.ID).
+		Update("deleted_at", time.Now().Add(-8*24*time.Hour)).Error)
+
+	purged, err = trashService.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	var count int64
+	db.Unscoped().Model(&models.Note{}).Where("id = ?", note.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.NoteTag{}).Where("note_id = ?", note.ID).Count(&count)
+	assert.Zero(t, count)
+	assert.Empty(t, getTrash(t, r, token).Items)
+
+	// Восстановленный тег не затронут
+	db.Model(&models.Tag{}).Where("id = ?", tag.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestTrash_TagRestoreAfterParentRename(t *testing.T) {
+	r, _, _ := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "trashtagpath@example.com", "trashpass", "TrashTagPath")
+
+	parent := createTag(t, r, token, "bio")
+	child := createTag(t, r, token, "bio::cell")
+	w := doAuthRequest(t, r, token, "DELETE", "/tags/"+child.ID.String(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// Потомок в корзине переезжает вместе с родителем и восстанавливается под новым путём
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+parent.ID.String(), map[string]interface{}{"name": "biology", "version": parent.Version})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doAuthRequest(t, r, token, "POST", "/trash/"+child.ID.String()+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doAuthRequest(t, r, token, "GET", "/tags/"+child.ID.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored models.Tag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, "biology::cell", restored.Name)
+}
+
+func TestTrash_PurgeFolderKeepsLiveNotes(t *testing.T) {
+	r, db, trashService := setupTrashTestRouter(t)
+	token := registerAndLogin(t, r, "trashpurgefolder@example.com", "trashpass", "TrashPurgeFolder")
+
+	folder := createFolder(t, r, token, "Old")
+	note := createNoteWithFolderAndTags(t, r, token, "Kept", folder.ID.String(), nil)
+
+	// Папка давно в корзине, а заметка в ней по-прежнему живая
+	require.NoError(t, db.Unscoped().Model(&models.Folder{}).Where("id = ?", folder.ID).
+		Update("deleted_at", time.Now().Add(-8*24*time.Hour)).Error)
+
+	purged, err := trashService.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	notes := getNotes(t, r, token, nil).Notes
+	require.Len(t, notes, 1)
+	assert.Equal(t, note.ID, notes[0].ID)
+	assert.Nil(t, notes[0].FolderID)
+	assert.Equal(t, 2, notes[0].Version)
+}
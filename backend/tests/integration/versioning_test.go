@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/controller/dto"
+	"valibibe/internal/models"
+)
+
+// doIfMatchRequest выполняет PUT с заголовком If-Match
+func doIfMatchRequest(t *testing.T, r *gin.Engine, token, path, etag string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest("PUT", path, bytes.NewReader(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", etag)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestVersioning_Notes(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "versions@example.com", "versionspass", "Versions")
+
+	noteID := createNoteWithContent(t, r, token, "Verbs", "ser and estar")
+
+	w := doAuthRequest(t, r, token, "GET", "/notes/"+noteID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	// Без версии сервер не знает, что видел клиент
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+noteID, map[string]interface{}{"title": "Verbs", "content": "no version"})
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	// Первый клиент сохраняет изменения
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+noteID, map[string]interface{}{
+		"title": "Verbs", "content": "first writer", "version": 1,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var updated models.Note
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, 2, updated.Version)
+
+	// Второй клиент с версией в теле получает 409 и актуальную копию
+	w = doAuthRequest(t, r, token, "PUT", "/notes/"+noteID, map[string]interface{}{
+		"title": "Verbs", "content": "second writer", "version": 1,
+	})
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var conflict struct {
+		Error   string      `json:"error"`
+		Current models.Note `json:"current"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, "first writer", conflict.Current.Content)
+	assert.Equal(t, 2, conflict.Current.Version)
+
+	// Устаревший If-Match — 412
+	w = doIfMatchRequest(t, r, token, "/notes/"+noteID, `"1"`, map[string]interface{}{"title": "Verbs", "content": "second writer"})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Заголовок важнее версии в теле; слабый ETag тоже принимается
+	w = doIfMatchRequest(t, r, token, "/notes/"+noteID, `W/"2"`, map[string]interface{}{
+		"title": "Verbs", "content": "second writer", "version": 1,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = doIfMatchRequest(t, r, token, "/notes/"+noteID, "not-an-etag", map[string]interface{}{"title": "Verbs", "content": "x"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVersioning_FoldersAndTags(t *testing.T) {
+	r := setupNoteControllerTestRouter(t)
+	token := registerAndLogin(t, r, "versions2@example.com", "versionspass", "Versions2")
+
+	folder := createFolder(t, r, token, "Languages")
+	assert.Equal(t, 1, folder.Version)
+
+	w := doAuthRequest(t, r, token, "GET", "/folders/tree", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tree []dto.FolderNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+	require.Len(t, tree, 1)
+	assert.Equal(t, 1, tree[0].Version)
+
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+folder.ID.String(), map[string]interface{}{"name": "Spanish"})
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	w = doAuthRequest(t, r, token, "PUT", "/folders/"+folder.ID.String(), map[string]interface{}{"name": "Spanish", "version": 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = doIfMatchRequest(t, r, token, "/folders/"+folder.ID.String(), `"1"`, map[string]interface{}{"name": "German"})
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+	var folderConflict struct {
+		Current models.Folder `json:"current"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &folderConflict))
+	assert.Equal(t, "Spanish", folderConflict.Current.Name)
+
+	tag := createTag(t, r, token, "verbs")
+	w = doAuthRequest(t, r, token, "GET", "/tags/"+tag.ID.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+tag.ID.String(), map[string]interface{}{"name": "grammar", "version": 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = doAuthRequest(t, r, token, "PUT", "/tags/"+tag.ID.String(), map[string]interface{}{"name": "irregular", "version": 1})
+	require.Equal(t, http.StatusConflict, w.Code)
+	var tagConflict struct {
+		Current models.Tag `json:"current"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tagConflict))
+	assert.Equal(t, "grammar", tagConflict.Current.Name)
+	assert.Equal(t, 2, tagConflict.Current.Version)
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/fractional"
+)
+
+// Ожидаемые ключи — из тестов fractional-indexing (rocicorp), с которой совпадает схема
+func TestFractional_Between(t *testing.T) {
+	smallest := "A" + strings.Repeat("0", 26)
+	largest := strings.Repeat("z", 27)
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		// первый ключ, голова и хвост
+		{"", "", "a0"},
+		{"", "a0", "Zz"},
+		{"", "Zz", "Zy"},
+		{"a0", "", "a1"},
+		{"a1", "", "a2"},
+		{"", "a0V", "a0"},
+		{"", "b999", "b99"},
+		// соседние ключи
+		{"a0", "a1", "a0V"},
+		{"a1", "a2", "a1V"},
+		{"a0V", "a1", "a0l"},
+		{"Zz", "a0", "ZzV"},
+		{"Zz", "a1", "a0"},
+		{"a0", "a0V", "a0G"},
+		{"a0", "a0G", "a08"},
+		{"b125", "b129", "b127"},
+		{"a0", "a1V", "a1"},
+		{"Zz", "a01", "a0"},
+		// переполнение целой части: длина меняется, у крайних целых — дробная часть
+		{"", "Y00", "Xzzz"},
+		{"bzz", "", "c000"},
+		{"", smallest + "1", smallest + "0V"},
+		{strings.Repeat("z", 26) + "y", "", largest},
+		{largest, "", largest + "V"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+".."+tt.b, func(t *testing.T) {
+			got, err := fractional.Between(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, fractional.Validate(got))
+		})
+	}
+}
+
+func TestFractional_BetweenInvalid(t *testing.T) {
+	smallest := "A" + strings.Repeat("0", 26)
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"smallest integer", "", smallest},
+		{"trailing zero", "a00", ""},
+		{"trailing zero in lower bound", "a00", "a1"},
+		{"invalid head", "0", "1"},
+		{"integer part too short", "b1", ""},
+		{"character outside alphabet", "a0-", ""},
+		{"reversed bounds", "a1", "a0"},
+		{"equal bounds", "a1", "a1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fractional.Between(tt.a, tt.b)
+			assert.ErrorIs(t, err, fractional.ErrInvalidKey)
+		})
+	}
+}
+
+func TestFractional_Sequence(t *testing.T) {
+	keys, err := fractional.Sequence("", "", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a0", "a1", "a2", "a3", "a4"}, keys)
+
+	keys, err = fractional.Sequence("a4", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a5", "a6", "a7", "a8", "a9", "aA", "aB", "aC", "aD", "aE"}, keys)
+
+	// С обеими границами и без нижней ключи строго возрастают и остаются между границами
+	for _, bounds := range [][2]string{{"a0", "a2"}, {"", "a0"}, {"Zz", "a0"}} {
+		keys, err = fractional.Sequence(bounds[0], bounds[1], 20)
+		require.NoError(t, err)
+		require.Len(t, keys, 20)
+		prev := bounds[0]
+		for _, key := range keys {
+			assert.NoError(t, fractional.Validate(key))
+			assert.Less(t, prev, key)
+			assert.Less(t, key, bounds[1])
+			prev = key
+		}
+	}
+
+	keys, err = fractional.Sequence("a0", "a1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = fractional.Sequence("a1", "a0", 3)
+	assert.ErrorIs(t, err, fractional.ErrInvalidKey)
+}
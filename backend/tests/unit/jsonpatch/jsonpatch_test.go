@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"valibibe/internal/jsonpatch"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	v, err := jsonpatch.Decode([]byte(data))
+	require.NoError(t, err)
+	return v
+}
+
+func applyPatch(t *testing.T, doc, patch string) (interface{}, error) {
+	ops, err := jsonpatch.ParseOperations([]byte(patch))
+	require.NoError(t, err)
+	return jsonpatch.Apply(decode(t, doc), ops)
+}
+
+func TestJSONPatch_Operations(t *testing.T) {
+	doc := `{"title":"Cell","tags":["a","b"],"meta":{"level":1}}`
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"add member", `[{"op":"add","path":"/content","value":"text"}]`,
+			`{"title":"Cell","content":"text","tags":["a","b"],"meta":{"level":1}}`},
+		{"add to array", `[{"op":"add","path":"/tags/1","value":"x"}]`,
+			`{"title":"Cell","tags":["a","x","b"],"meta":{"level":1}}`},
+		{"append to array", `[{"op":"add","path":"/tags/-","value":"c"}]`,
+			`{"title":"Cell","tags":["a","b","c"],"meta":{"level":1}}`},
+		{"remove", `[{"op":"remove","path":"/tags/0"}]`,
+			`{"title":"Cell","tags":["b"],"meta":{"level":1}}`},
+		{"replace", `[{"op":"replace","path":"/meta/level","value":5}]`,
+			`{"title":"Cell","tags":["a","b"],"meta":{"level":5}}`},
+		{"move", `[{"op":"move","from":"/meta/level","path":"/level"}]`,
+			`{"title":"Cell","tags":["a","b"],"meta":{},"level":1}`},
+		{"copy", `[{"op":"copy","from":"/title","path":"/meta/title"}]`,
+			`{"title":"Cell","tags":["a","b"],"meta":{"level":1,"title":"Cell"}}`},
+		{"test then replace", `[{"op":"test","path":"/title","value":"Cell"},{"op":"replace","path":"/title","value":"Atom"}]`,
+			`{"title":"Atom","tags":["a","b"],"meta":{"level":1}}`},
+		{"replace whole document", `[{"op":"replace","path":"","value":[1,2]}]`, `[1,2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(t, doc, tt.patch)
+			require.NoError(t, err)
+			assert.Equal(t, decode(t, tt.want), got)
+		})
+	}
+}
+
+func TestJSONPatch_PointerEscaping(t *testing.T) {
+	doc := `{"a/b":1,"m~n":2,"~1":3}`
+
+	// ~1 — это «/», ~0 — «~»; ~01 раскрывается в «~1», а не в «/»
+	got, err := applyPatch(t, doc, `[
+		{"op":"replace","path":"/a~1b","value":10},
+		{"op":"replace","path":"/m~0n","value":20},
+		{"op":"remove","path":"/~01"}
+	]`)
+	require.NoError(t, err)
+	assert.Equal(t, decode(t, `{"a/b":10,"m~n":20}`), got)
+
+	// Без экранирования «/» делит путь на два сегмента
+	_, err = applyPatch(t, doc, `[{"op":"replace","path":"/a/b","value":10}]`)
+	assert.ErrorIs(t, err, jsonpatch.ErrPathNotFound)
+
+	// Недопустимая escape-последовательность отклоняется при разборе
+	for _, path := range []string{"/a~2b", "/a~", "/~~01"} {
+		_, err = jsonpatch.ParseOperations([]byte(`[{"op":"remove","path":"` + path + `"}]`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch, path)
+	}
+}
+
+func TestJSONPatch_FailedTestIsAtomic(t *testing.T) {
+	original := decode(t, `{"title":"Cell","version":3}`)
+	ops, err := jsonpatch.ParseOperations([]byte(`[
+		{"op":"replace","path":"/title","value":"Atom"},
+		{"op":"test","path":"/version","value":2}
+	]`))
+	require.NoError(t, err)
+
+	got, err := jsonpatch.Apply(original, ops)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+	var opErr *jsonpatch.OpError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, 1, opErr.Index)
+	assert.Equal(t, "/version", opErr.Path)
+
+	// Исходный документ не тронут, даже первая операция не применилась к нему
+	assert.Equal(t, decode(t, `{"title":"Cell","version":3}`), original)
+}
+
+func TestJSONPatch_Errors(t *testing.T) {
+	doc := `{"tags":["a"]}`
+	tests := []struct {
+		name  string
+		patch string
+		err   error
+	}{
+		{"missing member", `[{"op":"remove","path":"/title"}]`, jsonpatch.ErrPathNotFound},
+		{"index out of range", `[{"op":"replace","path":"/tags/5","value":"x"}]`, jsonpatch.ErrPathNotFound},
+		{"move into itself", `[{"op":"move","from":"/tags","path":"/tags/0"}]`, jsonpatch.ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyPatch(t, doc, tt.patch)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	for _, patch := range []string{
+		`{"op":"add"}`,
+		`[{"op":"add","value":1}]`,
+		`[{"op":"add","path":"/x"}]`,
+		`[{"op":"move","path":"/x"}]`,
+		`[{"op":"merge","path":"/x"}]`,
+	} {
+		_, err := jsonpatch.ParseOperations([]byte(patch))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch, patch)
+	}
+}
+
+func TestJSONPatch_MergePatch(t *testing.T) {
+	target := decode(t, `{"title":"Cell","folder_id":"f1","meta":{"a":1,"b":2},"tags":["x"]}`)
+	patch := decode(t, `{"folder_id":null,"meta":{"b":null,"c":3},"tags":["y"]}`)
+
+	got := jsonpatch.MergePatch(target, patch)
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Cell","meta":{"a":1,"c":3},"tags":["y"]}`, string(data))
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"valibibe/internal/controller/dto"
+	apperrors "valibibe/internal/errors"
 	"valibibe/internal/models"
 	"valibibe/internal/repository/interfaces"
 	"valibibe/internal/service"
@@ -20,6 +22,11 @@ type MockNoteRepo struct {
 	mock.Mock
 }
 
+// WithinTransaction просто вызывает fn: транзакций у мока нет
+func (m *MockNoteRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (m *MockNoteRepo) CreateNote(ctx context.Context, note *models.Note) error {
 	args := m.Called(ctx, note)
 	return args.Error(0)
@@ -54,6 +61,29 @@ func (m *MockNoteRepo) UpdateNote(ctx context.Context, note *models.Note) error
 	return args.Error(0)
 }
 
+func (m *MockNoteRepo) UpdateNoteFields(ctx context.Context, note *models.Note, fields ...string) error {
+	args := m.Called(ctx, note, fields)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepo) PatchNote(ctx context.Context, note *models.Note, tagIDs []uuid.UUID) error {
+	args := m.Called(ctx, note, tagIDs)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepo) ListSiblings(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID) ([]models.Note, error) {
+	args := m.Called(ctx, userID, folderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Note), args.Error(1)
+}
+
+func (m *MockNoteRepo) SetPositions(ctx context.Context, userID uuid.UUID, positions map[uuid.UUID]string) error {
+	args := m.Called(ctx, userID, positions)
+	return args.Error(0)
+}
+
 func (m *MockNoteRepo) DeleteNote(ctx context.Context, noteID string) error {
 	args := m.Called(ctx, noteID)
 	return args.Error(0)
@@ -111,11 +141,201 @@ func (m *MockNoteRepo) GetNotesForReview(ctx context.Context, userID uuid.UUID,
 	return notes, args.Error(1)
 }
 
+func (m *MockNoteRepo) GetNotesForExam(ctx context.Context, userID uuid.UUID, filter *dto.ExamInput) ([]models.Note, error) {
+	args := m.Called(ctx, userID, filter)
+	notes, _ := args.Get(0).([]models.Note)
+	return notes, args.Error(1)
+}
+
+// MockReviewLogRepo реализует интерфейс ReviewLogRepository для моков
+type MockReviewLogRepo struct {
+	mock.Mock
+}
+
+func (m *MockReviewLogRepo) Create(ctx context.Context, log *models.ReviewLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockReviewLogRepo) GetRecallStats(ctx context.Context, userID uuid.UUID) ([]interfaces.RecallStat, error) {
+	args := m.Called(ctx, userID)
+	stats, _ := args.Get(0).([]interfaces.RecallStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockReviewLogRepo) CountByFolderSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]interfaces.FolderReviewCount, error) {
+	args := m.Called(ctx, userID, since)
+	counts, _ := args.Get(0).([]interfaces.FolderReviewCount)
+	return counts, args.Error(1)
+}
+
+// MockNoteRevisionRepo реализует интерфейс NoteRevisionRepository для моков
+type MockNoteRevisionRepo struct {
+	mock.Mock
+}
+
+func (m *MockNoteRevisionRepo) Create(ctx context.Context, revision *models.NoteRevision) error {
+	args := m.Called(ctx, revision)
+	return args.Error(0)
+}
+
+func (m *MockNoteRevisionRepo) ListByNote(ctx context.Context, noteID uuid.UUID) ([]models.NoteRevision, error) {
+	args := m.Called(ctx, noteID)
+	revisions, _ := args.Get(0).([]models.NoteRevision)
+	return revisions, args.Error(1)
+}
+
+func (m *MockNoteRevisionRepo) GetByNumber(ctx context.Context, noteID uuid.UUID, revision int) (*models.NoteRevision, error) {
+	args := m.Called(ctx, noteID, revision)
+	rev, _ := args.Get(0).(*models.NoteRevision)
+	return rev, args.Error(1)
+}
+
+func (m *MockNoteRevisionRepo) GetLatest(ctx context.Context, noteID uuid.UUID) (*models.NoteRevision, error) {
+	args := m.Called(ctx, noteID)
+	rev, _ := args.Get(0).(*models.NoteRevision)
+	return rev, args.Error(1)
+}
+
+func (m *MockNoteRevisionRepo) Prune(ctx context.Context, noteID uuid.UUID, keep int) error {
+	args := m.Called(ctx, noteID, keep)
+	return args.Error(0)
+}
+
+// MockNoteLinkRepo реализует интерфейс NoteLinkRepository для моков
+type MockNoteLinkRepo struct {
+	mock.Mock
+}
+
+func (m *MockNoteLinkRepo) ReplaceForNote(ctx context.Context, sourceID uuid.UUID, links []models.NoteLink) error {
+	args := m.Called(ctx, sourceID, links)
+	return args.Error(0)
+}
+
+func (m *MockNoteLinkRepo) ListBySource(ctx context.Context, userID, sourceID uuid.UUID) ([]models.NoteLink, error) {
+	args := m.Called(ctx, userID, sourceID)
+	links, _ := args.Get(0).([]models.NoteLink)
+	return links, args.Error(1)
+}
+
+func (m *MockNoteLinkRepo) ListByTarget(ctx context.Context, userID, targetID uuid.UUID) ([]models.NoteLink, error) {
+	args := m.Called(ctx, userID, targetID)
+	links, _ := args.Get(0).([]models.NoteLink)
+	return links, args.Error(1)
+}
+
+func (m *MockNoteLinkRepo) ListResolvedByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error) {
+	args := m.Called(ctx, userID)
+	links, _ := args.Get(0).([]models.NoteLink)
+	return links, args.Error(1)
+}
+
+func (m *MockNoteLinkRepo) ListDangling(ctx context.Context, userID uuid.UUID) ([]models.NoteLink, error) {
+	args := m.Called(ctx, userID)
+	links, _ := args.Get(0).([]models.NoteLink)
+	return links, args.Error(1)
+}
+
+func (m *MockNoteLinkRepo) FindNoteIDByTitle(ctx context.Context, userID uuid.UUID, title string) (*uuid.UUID, error) {
+	args := m.Called(ctx, userID, title)
+	id, _ := args.Get(0).(*uuid.UUID)
+	return id, args.Error(1)
+}
+
+func (m *MockNoteLinkRepo) ResolveDangling(ctx context.Context, userID uuid.UUID, title string, noteID uuid.UUID) error {
+	args := m.Called(ctx, userID, title, noteID)
+	return args.Error(0)
+}
+
+// MockNoteTypeRepo реализует интерфейс NoteTypeRepository для моков
+type MockNoteTypeRepo struct {
+	mock.Mock
+}
+
+func (m *MockNoteTypeRepo) Create(ctx context.Context, noteType *models.NoteType) error {
+	args := m.Called(ctx, noteType)
+	return args.Error(0)
+}
+
+func (m *MockNoteTypeRepo) GetByID(ctx context.Context, userID, typeID uuid.UUID) (*models.NoteType, error) {
+	args := m.Called(ctx, userID, typeID)
+	noteType, _ := args.Get(0).(*models.NoteType)
+	return noteType, args.Error(1)
+}
+
+func (m *MockNoteTypeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.NoteType, error) {
+	args := m.Called(ctx, userID)
+	noteTypes, _ := args.Get(0).([]models.NoteType)
+	return noteTypes, args.Error(1)
+}
+
+func (m *MockNoteTypeRepo) Update(ctx context.Context, noteType *models.NoteType) error {
+	args := m.Called(ctx, noteType)
+	return args.Error(0)
+}
+
+func (m *MockNoteTypeRepo) Delete(ctx context.Context, userID, typeID uuid.UUID) error {
+	args := m.Called(ctx, userID, typeID)
+	return args.Error(0)
+}
+
+func (m *MockNoteTypeRepo) ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, name, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNoteTypeRepo) CountNotes(ctx context.Context, typeID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, typeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockNoteDuplicateRepo реализует интерфейс NoteDuplicateRepository для моков
+type MockNoteDuplicateRepo struct {
+	mock.Mock
+}
+
+func (m *MockNoteDuplicateRepo) ListCandidates(ctx context.Context, userID uuid.UUID) ([]models.Note, error) {
+	args := m.Called(ctx, userID)
+	notes, _ := args.Get(0).([]models.Note)
+	return notes, args.Error(1)
+}
+
+func (m *MockNoteDuplicateRepo) ListStale(ctx context.Context, userID uuid.UUID) ([]models.Note, error) {
+	args := m.Called(ctx, userID)
+	notes, _ := args.Get(0).([]models.Note)
+	return notes, args.Error(1)
+}
+
+func (m *MockNoteDuplicateRepo) ReplaceKeys(ctx context.Context, noteIDs []uuid.UUID, keys []models.NoteDedupKey) error {
+	args := m.Called(ctx, noteIDs, keys)
+	return args.Error(0)
+}
+
+func (m *MockNoteDuplicateRepo) ListByKeys(ctx context.Context, userID uuid.UUID, keys []int64) ([]models.Note, error) {
+	args := m.Called(ctx, userID, keys)
+	notes, _ := args.Get(0).([]models.Note)
+	return notes, args.Error(1)
+}
+
+func (m *MockNoteDuplicateRepo) ListByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]models.Note, error) {
+	args := m.Called(ctx, userID, ids)
+	notes, _ := args.Get(0).([]models.Note)
+	return notes, args.Error(1)
+}
+
+func (m *MockNoteDuplicateRepo) Merge(ctx context.Context, target *models.Note, sourceIDs []uuid.UUID) error {
+	args := m.Called(ctx, target, sourceIDs)
+	return args.Error(0)
+}
+
 // ====== Тесты NoteService ======
 
 func TestNoteService_CreateNote(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	mockRevisionRepo := new(MockNoteRevisionRepo)
+	mockLinkRepo := new(MockNoteLinkRepo)
+	mockDuplicateRepo := new(MockNoteDuplicateRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(mockRevisionRepo, mockRepo, 10), service.NewNoteLinkService(mockLinkRepo, mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(mockDuplicateRepo, 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -124,22 +344,76 @@ func TestNoteService_CreateNote(t *testing.T) {
 		Content: "Test Content",
 	}
 
+	// Похожих заметок нет: ключи актуальны, по ключам нашлась непохожая заметка
+	mockDuplicateRepo.On("ListStale", ctx, userID).Return([]models.Note{}, nil)
+	mockDuplicateRepo.On("ListByKeys", ctx, userID, mock.Anything).Return([]models.Note{
+		{ID: uuid.New(), Title: "Other note", Content: "Something else entirely"},
+	}, nil)
+
 	mockRepo.On("CreateNote", ctx, mock.MatchedBy(func(note *models.Note) bool {
 		return note.Title == input.Title && note.Content == input.Content && note.UserID == userID
 	})).Return(nil)
+	// Первая ревизия с исходным текстом
+	mockRevisionRepo.On("GetLatest", ctx, mock.Anything).Return(nil, nil)
+	mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.NoteRevision) bool {
+		return rev.Title == input.Title && rev.Content == input.Content && rev.UserID == userID
+	})).Return(nil)
+	mockRevisionRepo.On("Prune", ctx, mock.Anything, 10).Return(nil)
+	// В тексте нет ссылок; висячие ссылки на заголовок привязываются к новой заметке
+	mockLinkRepo.On("ReplaceForNote", ctx, mock.Anything, []models.NoteLink{}).Return(nil)
+	mockLinkRepo.On("ResolveDangling", ctx, userID, input.Title, mock.Anything).Return(nil)
 
 	createdNote, err := noteService.CreateNote(ctx, userID.String(), input)
 	assert.NoError(t, err)
 	assert.Equal(t, input.Title, createdNote.Title)
 	assert.Equal(t, input.Content, createdNote.Content)
 	assert.Equal(t, userID, createdNote.UserID)
+	assert.Empty(t, createdNote.DuplicateIDs)
 
 	mockRepo.AssertExpectations(t)
+	mockRevisionRepo.AssertExpectations(t)
+	mockLinkRepo.AssertExpectations(t)
+	mockDuplicateRepo.AssertExpectations(t)
+}
+
+func TestNoteService_CreateNote_RejectDuplicate(t *testing.T) {
+	mockRepo := new(MockNoteRepo)
+	mockDuplicateRepo := new(MockNoteDuplicateRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(mockDuplicateRepo, 0.8), nil, nil)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	existingID := uuid.New()
+	input := &dto.NoteInput{
+		Title:       "The Krebs cycle!",
+		Content:     "Citric acid cycle in mitochondria",
+		OnDuplicate: dto.OnDuplicateReject,
+	}
+
+	// Ключи существующей заметки пересчитываются перед поиском
+	mockDuplicateRepo.On("ListStale", ctx, userID).Return([]models.Note{
+		{ID: existingID, UserID: userID, Title: "the krebs  cycle", Content: "Different text", Version: 1},
+	}, nil)
+	mockDuplicateRepo.On("ReplaceKeys", ctx, []uuid.UUID{existingID}, mock.Anything).Return(nil)
+	// Заголовки совпадают после нормализации (регистр и пунктуация не важны)
+	mockDuplicateRepo.On("ListByKeys", ctx, userID, mock.Anything).Return([]models.Note{
+		{ID: existingID, Title: "the krebs  cycle", Content: "Different text"},
+	}, nil)
+
+	note, err := noteService.CreateNote(ctx, userID.String(), input)
+	assert.Nil(t, note)
+	var duplicateErr *service.DuplicateNoteError
+	assert.ErrorAs(t, err, &duplicateErr)
+	assert.Equal(t, []string{existingID.String()}, duplicateErr.NoteIDs)
+
+	// Заметка не создаётся
+	mockRepo.AssertNotCalled(t, "CreateNote", mock.Anything, mock.Anything)
+	mockDuplicateRepo.AssertExpectations(t)
 }
 
 func TestNoteService_GetNoteByID(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -163,7 +437,7 @@ func TestNoteService_GetNoteByID(t *testing.T) {
 
 func TestNoteService_GetAllNotesByUserID(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New().String()
@@ -194,7 +468,9 @@ func TestNoteService_GetAllNotesByUserID(t *testing.T) {
 
 func TestNoteService_UpdateNote(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	mockRevisionRepo := new(MockNoteRevisionRepo)
+	mockLinkRepo := new(MockNoteLinkRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(mockRevisionRepo, mockRepo, 10), service.NewNoteLinkService(mockLinkRepo, mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -205,11 +481,14 @@ func TestNoteService_UpdateNote(t *testing.T) {
 		Title:   "Old Title",
 		Content: "Old Content",
 		UserID:  userID,
+		Version: 1,
 	}
 
+	version := 1
 	input := &dto.NoteInput{
 		Title:   "Updated Title",
 		Content: "Updated Content",
+		Version: &version,
 	}
 
 	//mockRepo.On("GetNoteByID", ctx, noteID.String()).Return(note, nil)
@@ -217,6 +496,18 @@ func TestNoteService_UpdateNote(t *testing.T) {
 	mockRepo.On("UpdateNote", ctx, mock.MatchedBy(func(n *models.Note) bool {
 		return n.Title == input.Title && n.Content == input.Content && n.ID == noteID
 	})).Return(nil)
+	// История уже есть: сохраняется только новая ревизия
+	mockRevisionRepo.On("GetLatest", ctx, noteID).Return(&models.NoteRevision{
+		NoteID: noteID, Revision: 1, Title: "Old Title", Content: "Old Content",
+	}, nil)
+	mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.NoteRevision) bool {
+		return rev.NoteID == noteID && rev.Title == input.Title && rev.Content == input.Content
+	})).Return(nil).Once()
+	mockRevisionRepo.On("Prune", ctx, noteID, 10).Return(nil)
+	// Заголовок изменился: ищутся заметки со ссылками на старый заголовок
+	mockLinkRepo.On("ReplaceForNote", ctx, noteID, []models.NoteLink{}).Return(nil)
+	mockLinkRepo.On("ResolveDangling", ctx, userID, input.Title, noteID).Return(nil)
+	mockLinkRepo.On("ListByTarget", ctx, userID, noteID).Return(nil, nil)
 
 	updatedNote, err := noteService.UpdateNote(ctx, userID.String(), noteID.String(), input)
 	assert.NoError(t, err)
@@ -224,11 +515,39 @@ func TestNoteService_UpdateNote(t *testing.T) {
 	assert.Equal(t, input.Content, updatedNote.Content)
 
 	mockRepo.AssertExpectations(t)
+	mockRevisionRepo.AssertExpectations(t)
+	mockLinkRepo.AssertExpectations(t)
+}
+
+func TestNoteService_UpdateNote_StaleVersion(t *testing.T) {
+	mockRepo := new(MockNoteRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	noteID := uuid.New()
+	note := &models.Note{ID: noteID, Title: "Current", Content: "Current", UserID: userID, Version: 3}
+	mockRepo.On("GetNoteByIDAndUserID", ctx, noteID.String(), userID.String()).Return(note, nil)
+
+	// Без версии обновление не выполняется
+	_, err := noteService.UpdateNote(ctx, userID.String(), noteID.String(), &dto.NoteInput{Title: "New", Content: "New"})
+	assert.ErrorIs(t, err, apperrors.ErrVersionRequired)
+
+	// Устаревшая версия: ошибка несёт актуальную заметку
+	stale := 2
+	_, err = noteService.UpdateNote(ctx, userID.String(), noteID.String(), &dto.NoteInput{Title: "New", Content: "New", Version: &stale})
+	var conflict *service.VersionConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, 3, conflict.Version)
+		assert.Equal(t, note, conflict.Current)
+	}
+	assert.ErrorIs(t, err, apperrors.ErrVersionConflict)
+	mockRepo.AssertNotCalled(t, "UpdateNote", mock.Anything, mock.Anything)
 }
 
 func TestNoteService_DeleteNote(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -251,7 +570,7 @@ func TestNoteService_DeleteNote(t *testing.T) {
 
 func TestNoteService_ArchiveNote(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	noteService := service.NewNoteService(mockRepo, new(MockReviewLogRepo), service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -274,7 +593,7 @@ func TestNoteService_ArchiveNote(t *testing.T) {
 	// Мокируем вызовы репозитория
 	//mockRepo.On("GetNoteByID", ctx, noteID.String()).Return(note, nil)
 	mockRepo.On("GetNoteByIDAndUserID", ctx, noteID.String(), userID.String()).Return(note, nil)
-	mockRepo.On("UpdateNote", ctx, archivedNote).Return(nil)
+	mockRepo.On("UpdateNoteFields", ctx, archivedNote, []string{"archived"}).Return(nil)
 
 	// Вызываем метод и проверяем, что ошибки нет и заметка вернулась с Archived = true
 	updatedNote, err := noteService.ArchiveNote(ctx, userID.String(), noteID.String())
@@ -291,7 +610,8 @@ func TestNoteService_ArchiveNote(t *testing.T) {
 
 func TestNoteService_UpdateMemoryLevel(t *testing.T) {
 	mockRepo := new(MockNoteRepo)
-	noteService := service.NewNoteService(mockRepo)
+	mockLogRepo := new(MockReviewLogRepo)
+	noteService := service.NewNoteService(mockRepo, mockLogRepo, service.NewNoteRevisionService(new(MockNoteRevisionRepo), mockRepo, 10), service.NewNoteLinkService(new(MockNoteLinkRepo), mockRepo), service.NewNoteTypeService(new(MockNoteTypeRepo)), service.NewDuplicateService(new(MockNoteDuplicateRepo), 0.8), nil, nil)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -305,9 +625,12 @@ func TestNoteService_UpdateMemoryLevel(t *testing.T) {
 
 	//mockRepo.On("GetNoteByID", ctx, noteID.String()).Return(note, nil)
 	mockRepo.On("GetNoteByIDAndUserID", ctx, noteID.String(), userID.String()).Return(note, nil)
-	mockRepo.On("UpdateNote", ctx, mock.MatchedBy(func(n *models.Note) bool {
+	mockRepo.On("UpdateNoteFields", ctx, mock.MatchedBy(func(n *models.Note) bool {
 		return n.ID == noteID && (n.MemoryLevel == 60 || n.MemoryLevel == 0)
-	})).Return(nil)
+	}), []string{"memory_level", "next_review_at"}).Return(nil)
+	mockLogRepo.On("Create", ctx, mock.MatchedBy(func(l *models.ReviewLog) bool {
+		return l.NoteID == noteID && l.Source == models.ReviewSourceReview
+	})).Return(nil).Times(2)
 
 	// Тестируем рост memoryLevel
 	err := noteService.UpdateMemoryLevel(ctx, userID.String(), noteID.String(), true)
@@ -322,4 +645,5 @@ func TestNoteService_UpdateMemoryLevel(t *testing.T) {
 	assert.Nil(t, note.NextReviewAt)
 
 	mockRepo.AssertExpectations(t)
+	mockLogRepo.AssertExpectations(t)
 }
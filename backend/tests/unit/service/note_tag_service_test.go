@@ -19,6 +19,11 @@ type MockTagRepo struct {
 	mock.Mock
 }
 
+// WithinTransaction просто вызывает fn: транзакций у мока нет
+func (m *MockTagRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (m *MockTagRepo) Create(ctx context.Context, tag *models.Tag) error {
 	args := m.Called(ctx, tag)
 	return args.Error(0)
@@ -56,6 +61,16 @@ func (m *MockTagRepo) CountTagsByIDsAndUserID(ctx context.Context, tagIDs []stri
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockTagRepo) Merge(ctx context.Context, sources []models.Tag, target *models.Tag) (int, int, error) {
+	args := m.Called(ctx, sources, target)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockTagRepo) Rename(ctx context.Context, userID uuid.UUID, names map[uuid.UUID]string) error {
+	args := m.Called(ctx, userID, names)
+	return args.Error(0)
+}
+
 func (m *MockTagRepo) AttachToNote(ctx context.Context, noteID, tagID uuid.UUID) error {
 	args := m.Called(ctx, noteID, tagID)
 	return args.Error(0)
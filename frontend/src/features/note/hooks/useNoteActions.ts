@@ -13,8 +13,8 @@ export function useNoteActions(noteId: string) {
     const [archiveNote] = useArchiveNoteMutation()
     const [unarchiveNote, { isLoading: isUnarchiving }] = useUnarchiveNoteMutation()
 
-    const update = async (title: string, content: string) => {
-        return updateNote({ id: noteId, title, content }).unwrap()
+    const update = async (title: string, content: string, version: number) => {
+        return updateNote({ id: noteId, title, content, version }).unwrap()
     }
 
     const remove = async () => {
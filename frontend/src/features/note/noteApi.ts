@@ -8,6 +8,7 @@ export interface Note {
     created_at: string
     next_review_at?: string
     archived: boolean
+    version: number
 }
 
 export interface PaginatedNotes {
@@ -68,7 +68,7 @@ const NoteDetailPage = () => {
         const content = editor?.getHTML() || ''
         if (!content.trim()) return alert('Введите текст заметки')
         try {
-            await update(title, content)
+            await update(title, content, note!.version)
             setSnackbar({ message: 'Заметка сохранена' })
             setEditing(false)
         } catch (err) {